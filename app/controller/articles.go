@@ -1,31 +1,114 @@
 package controller
 
 import (
+	"context"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/logging"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 	"net/http"
 )
 
+// numOfMediumArticles is the number of articles returned by the medium endpoint
+const numOfMediumArticles = 6
+
 type ArticlesService interface {
-	GetLatestArticles() []dto.Article
+	GetArticles(ctx context.Context, source, tag string, page, limit int) ([]dto.Article, error)
+}
+
+type onchainArticlesService interface {
+	GetArticles(ctx context.Context, publisher string, page, limit int) ([]dto.Article, error)
 }
 
 type ArticlesController struct {
 	service ArticlesService
+	onchain onchainArticlesService
 	logger  logrus.FieldLogger
 }
 
-func NewArticlesController(logger logrus.FieldLogger, service ArticlesService) (*ArticlesController, error) {
-	if logger == nil || service == nil {
+func NewArticlesController(logger logrus.FieldLogger, service ArticlesService, onchain onchainArticlesService) (*ArticlesController, error) {
+	if logger == nil || service == nil || onchain == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewArticlesController")
 	}
 
-	return &ArticlesController{service: service, logger: logger}, nil
+	return &ArticlesController{service: service, onchain: onchain, logger: logger}, nil
+}
+
+func (c *ArticlesController) ArticlesHandler(ctx echo.Context) error {
+	articles, err := c.getArticles(ctx)
+	if err != nil {
+		return respondErrResponse(ctx, c.getMethodLogger(ctx, "ArticlesHandler"), err)
+	}
+
+	return ctx.JSON(http.StatusOK, articles)
+}
+
+func (c *ArticlesController) ArticlesV2Handler(ctx echo.Context) error {
+	articles, err := c.getArticles(ctx)
+	if err != nil {
+		return respondError(ctx, c.getMethodLogger(ctx, "ArticlesV2Handler"), err)
+	}
+
+	return respondData(ctx, articles)
+}
+
+func (c *ArticlesController) OnchainArticlesHandler(ctx echo.Context) error {
+	articles, err := c.getOnchainArticles(ctx)
+	if err != nil {
+		return respondErrResponse(ctx, c.getMethodLogger(ctx, "OnchainArticlesHandler"), err)
+	}
+
+	return ctx.JSON(http.StatusOK, articles)
+}
+
+func (c *ArticlesController) OnchainArticlesV2Handler(ctx echo.Context) error {
+	articles, err := c.getOnchainArticles(ctx)
+	if err != nil {
+		return respondError(ctx, c.getMethodLogger(ctx, "OnchainArticlesV2Handler"), err)
+	}
+
+	return respondData(ctx, articles)
 }
 
+// MediumArticlesHandler is kept for backward compatibility, it returns the latest articles of the medium feed
 func (c *ArticlesController) MediumArticlesHandler(ctx echo.Context) error {
+	articles, err := c.service.GetArticles(ctx.Request().Context(), config.DefaultArticlesSource, "", 1, numOfMediumArticles)
+	if err != nil {
+		return respondErrResponse(ctx, c.getMethodLogger(ctx, "MediumArticlesHandler"), err)
+	}
+
+	return ctx.JSON(http.StatusOK, articles)
+}
+
+func (c *ArticlesController) getArticles(ctx echo.Context) ([]dto.Article, error) {
+	params, err := request.NewArticlesParams(ctx)
+	if err != nil {
+		return nil, internal.NewInvalidRequestErr("invalid request")
+	}
+
+	if err = params.Validate(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	return c.service.GetArticles(ctx.Request().Context(), params.Source, params.Tag, params.Page, params.Limit)
+}
+
+func (c *ArticlesController) getOnchainArticles(ctx echo.Context) ([]dto.Article, error) {
+	params, err := request.NewOnchainArticlesParams(ctx)
+	if err != nil {
+		return nil, internal.NewInvalidRequestErr("invalid request")
+	}
+
+	if err = params.Validate(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	return c.onchain.GetArticles(ctx.Request().Context(), params.Publisher, params.Page, params.Limit)
+}
 
-	return ctx.JSON(http.StatusOK, c.service.GetLatestArticles())
+func (c *ArticlesController) getMethodLogger(ctx echo.Context, method string) logrus.FieldLogger {
+	return logging.FromContext(ctx, c.logger).WithField("struct", "ArticlesController").WithField("func", method)
 }
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/logging"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type assetsService interface {
+	GetAssets(ctx context.Context) ([]response.Asset, error)
+}
+
+type AssetsController struct {
+	logger  logrus.FieldLogger
+	service assetsService
+}
+
+func NewAssetsController(logger logrus.FieldLogger, service assetsService) (*AssetsController, error) {
+	if logger == nil || service == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewAssetsController")
+	}
+
+	return &AssetsController{logger: logger, service: service}, nil
+}
+
+func (c *AssetsController) AssetsHandler(ctx echo.Context) error {
+	assets, err := c.service.GetAssets(ctx.Request().Context())
+	if err != nil {
+		return respondErrResponse(ctx, c.getMethodLogger(ctx, "AssetsHandler"), err)
+	}
+
+	return ctx.JSON(http.StatusOK, assets)
+}
+
+func (c *AssetsController) AssetsV2Handler(ctx echo.Context) error {
+	assets, err := c.service.GetAssets(ctx.Request().Context())
+	if err != nil {
+		return respondError(ctx, c.getMethodLogger(ctx, "AssetsV2Handler"), err)
+	}
+
+	return respondData(ctx, emptyIfNil(assets))
+}
+
+func (c *AssetsController) getMethodLogger(ctx echo.Context, method string) logrus.FieldLogger {
+	return logging.FromContext(ctx, c.logger).WithField("struct", "AssetsController").WithField("method", method)
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/logging"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type balanceGroupsService interface {
+	GetGroups(ctx context.Context) ([]response.BalanceGroup, error)
+	GetGroup(ctx context.Context, name string) (*response.BalanceGroup, error)
+	SaveGroup(ctx context.Context, name string, params *request.BalanceHealthParams) (*response.BalanceGroup, error)
+	DeleteGroup(ctx context.Context, name string) error
+	CheckGroup(ctx context.Context, name string) (*response.BalanceHealthResponse, error)
+}
+
+// BalanceGroupsController checks the balance watch groups and manages them through the admin API
+type BalanceGroupsController struct {
+	logger  logrus.FieldLogger
+	service balanceGroupsService
+}
+
+func NewBalanceGroupsController(logger logrus.FieldLogger, service balanceGroupsService) (*BalanceGroupsController, error) {
+	if logger == nil || service == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewBalanceGroupsController")
+	}
+
+	return &BalanceGroupsController{logger: logger, service: service}, nil
+}
+
+func (c *BalanceGroupsController) CheckHandler(ctx echo.Context) error {
+	result, err := c.checkGroup(ctx)
+	if err != nil {
+		return respondErrResponse(ctx, c.getMethodLogger(ctx, "CheckHandler"), err)
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (c *BalanceGroupsController) CheckV2Handler(ctx echo.Context) error {
+	result, err := c.checkGroup(ctx)
+	if err != nil {
+		return respondError(ctx, c.getMethodLogger(ctx, "CheckV2Handler"), err)
+	}
+
+	return respondData(ctx, result)
+}
+
+func (c *BalanceGroupsController) ListV2Handler(ctx echo.Context) error {
+	groups, err := c.service.GetGroups(ctx.Request().Context())
+	if err != nil {
+		return respondError(ctx, c.getMethodLogger(ctx, "ListV2Handler"), err)
+	}
+
+	return respondData(ctx, groups)
+}
+
+func (c *BalanceGroupsController) GetV2Handler(ctx echo.Context) error {
+	l := c.getMethodLogger(ctx, "GetV2Handler")
+	name, err := request.BalanceGroupName(ctx)
+	if err != nil {
+		return respondError(ctx, l, validationErr(err))
+	}
+
+	group, err := c.service.GetGroup(ctx.Request().Context(), name)
+	if err != nil {
+		return respondError(ctx, l, err)
+	}
+
+	return respondData(ctx, group)
+}
+
+func (c *BalanceGroupsController) SaveV2Handler(ctx echo.Context) error {
+	l := c.getMethodLogger(ctx, "SaveV2Handler")
+	name, err := request.BalanceGroupName(ctx)
+	if err != nil {
+		return respondError(ctx, l, validationErr(err))
+	}
+
+	params, err := request.NewBalanceHealthParams(ctx)
+	if err != nil {
+		return respondError(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	group, err := c.service.SaveGroup(ctx.Request().Context(), name, params)
+	if err != nil {
+		return respondError(ctx, l, err)
+	}
+
+	return respondData(ctx, group)
+}
+
+func (c *BalanceGroupsController) DeleteV2Handler(ctx echo.Context) error {
+	l := c.getMethodLogger(ctx, "DeleteV2Handler")
+	name, err := request.BalanceGroupName(ctx)
+	if err != nil {
+		return respondError(ctx, l, validationErr(err))
+	}
+
+	if err = c.service.DeleteGroup(ctx.Request().Context(), name); err != nil {
+		return respondError(ctx, l, err)
+	}
+
+	return respondData(ctx, nil)
+}
+
+func (c *BalanceGroupsController) checkGroup(ctx echo.Context) (*response.BalanceHealthResponse, error) {
+	name, err := request.BalanceGroupName(ctx)
+	if err != nil {
+		return nil, validationErr(err)
+	}
+
+	return c.service.CheckGroup(ctx.Request().Context(), name)
+}
+
+func (c *BalanceGroupsController) getMethodLogger(ctx echo.Context, method string) logrus.FieldLogger {
+	return logging.FromContext(ctx, c.logger).WithField("struct", "BalanceGroupsController").WithField("method", method)
+}
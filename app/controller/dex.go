@@ -1,33 +1,42 @@
 package controller
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
 	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/httpcache"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/logging"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
-	"net/http"
 )
 
 type intervalService interface {
-	GetIntervals(marketId string, length int, limit int) ([]entity.MarketHistoryInterval, error)
-	GetTradingViewIntervals(marketId string, length int, limit int) (result []entity.TradingViewInterval, err error)
+	GetIntervals(ctx context.Context, marketId string, length int, limit int) ([]entity.MarketHistoryInterval, error)
+	GetTradingViewIntervals(ctx context.Context, marketId string, length int, limit int) (result []entity.TradingViewInterval, err error)
 }
 
 type historyService interface {
-	GetHistory(params *request.HistoryParams) ([]response.HistoryTrade, error)
-	GetCoingeckoHistory(params *request.HistoryParams) (*response.CoingeckoHistory, error)
+	GetHistory(ctx context.Context, params *request.HistoryParams) ([]response.HistoryTrade, error)
+	GetCoingeckoHistory(ctx context.Context, params *request.HistoryParams) (*response.CoingeckoHistory, error)
 }
 
 type ordersService interface {
-	GetMarketOrders(marketId string, depth int) (*response.Orders, error)
-	GetCoingeckoMarketOrders(marketId string, depth int) (*response.CoingeckoOrders, error)
+	GetMarketOrders(ctx context.Context, marketId string, depth int) (*response.Orders, error)
+	GetCoingeckoMarketOrders(ctx context.Context, marketId string, depth int) (*response.CoingeckoOrders, error)
 }
 
 type tickersService interface {
-	GetTickers() ([]*response.Ticker, error)
-	GetCoingeckoTickers() ([]*response.CoingeckoTicker, error)
+	GetTickers(ctx context.Context) ([]*response.Ticker, error)
+	GetCoingeckoTickers(ctx context.Context) ([]*response.CoingeckoTicker, error)
+}
+
+type marketsService interface {
+	GetMarket(ctx context.Context, marketId string) (*entity.Market, error)
 }
 
 type Dex struct {
@@ -36,10 +45,13 @@ type Dex struct {
 	orders    ordersService
 	history   historyService
 	intervals intervalService
+	markets   marketsService
+	cache     responseCache
+	maxAge    time.Duration
 }
 
-func NewDexController(logger logrus.FieldLogger, service tickersService, orders ordersService, history historyService, intervals intervalService) (*Dex, error) {
-	if logger == nil || service == nil || orders == nil || history == nil || intervals == nil {
+func NewDexController(logger logrus.FieldLogger, service tickersService, orders ordersService, history historyService, intervals intervalService, markets marketsService, cache responseCache, maxAge time.Duration) (*Dex, error) {
+	if logger == nil || service == nil || orders == nil || history == nil || intervals == nil || markets == nil || cache == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewDexController")
 	}
 
@@ -49,11 +61,14 @@ func NewDexController(logger logrus.FieldLogger, service tickersService, orders
 		orders:    orders,
 		history:   history,
 		intervals: intervals,
+		markets:   markets,
+		cache:     cache,
+		maxAge:    maxAge,
 	}, nil
 }
 
 func (d *Dex) TickersHandler(ctx echo.Context) error {
-	l := d.getMethodLogger("TickersHandler")
+	l := d.getMethodLogger(ctx, "TickersHandler")
 
 	params, err := request.NewTickersParams(ctx)
 	if err != nil {
@@ -62,29 +77,24 @@ func (d *Dex) TickersHandler(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, request.NewErrResponse("invalid request"))
 	}
 
-	if params.IsCoingeckoFormat() {
-		data, err := d.tickers.GetCoingeckoTickers()
-		if err != nil {
-			l.WithError(err).Error("error when getting tickers")
-
-			return ctx.JSON(http.StatusInternalServerError, request.NewUnknownErrorResponse())
+	entry, err := d.cached(ctx, tickersKey(ctx, params), httpcache.AnyMarket, func(c context.Context) (any, error) {
+		if params.IsCoingeckoFormat() {
+			return d.tickers.GetCoingeckoTickers(c)
 		}
 
-		return ctx.JSON(http.StatusOK, data)
-	}
-
-	data, err := d.tickers.GetTickers()
+		return d.tickers.GetTickers(c)
+	})
 	if err != nil {
 		l.WithError(err).Error("error when getting tickers")
 
 		return ctx.JSON(http.StatusInternalServerError, request.NewUnknownErrorResponse())
 	}
 
-	return ctx.JSON(http.StatusOK, data)
+	return d.writeCached(ctx, entry)
 }
 
 func (d *Dex) OrdersHandler(ctx echo.Context) error {
-	l := d.getMethodLogger("OrdersHandler")
+	l := d.getMethodLogger(ctx, "OrdersHandler")
 
 	params, err := request.NewOrdersParams(ctx)
 	if err != nil {
@@ -100,37 +110,34 @@ func (d *Dex) OrdersHandler(ctx echo.Context) error {
 	}
 
 	marketId := params.MustGetMarketId()
-	if params.IsCoingeckoFormat() {
-		data, err := d.orders.GetCoingeckoMarketOrders(marketId, params.Depth)
-		if err != nil {
-			l.WithError(err).Error("error when getting orders")
-
-			return ctx.JSON(http.StatusBadRequest, request.NewUnknownErrorResponse())
+	entry, err := d.cached(ctx, ordersKey(ctx, params), marketId, func(c context.Context) (any, error) {
+		if params.IsCoingeckoFormat() {
+			data, err := d.orders.GetCoingeckoMarketOrders(c, marketId, params.Depth)
+			if data == nil || err != nil {
+				return []struct{}{}, err
+			}
+
+			return data, nil
 		}
 
-		if data == nil {
-			return ctx.JSON(http.StatusOK, []struct{}{})
+		data, err := d.orders.GetMarketOrders(c, marketId, params.Depth)
+		if data == nil || err != nil {
+			return []struct{}{}, err
 		}
 
-		return ctx.JSON(http.StatusOK, data)
-	}
-
-	data, err := d.orders.GetMarketOrders(marketId, params.Depth)
+		return data, nil
+	})
 	if err != nil {
 		l.WithError(err).Error("error when getting orders")
 
 		return ctx.JSON(http.StatusBadRequest, request.NewUnknownErrorResponse())
 	}
 
-	if data == nil {
-		return ctx.JSON(http.StatusOK, []struct{}{})
-	}
-
-	return ctx.JSON(http.StatusOK, data)
+	return d.writeCached(ctx, entry)
 }
 
 func (d *Dex) HistoryHandler(ctx echo.Context) error {
-	l := d.getMethodLogger("HistoryHandler")
+	l := d.getMethodLogger(ctx, "HistoryHandler")
 
 	params, err := request.NewHistoryParams(ctx)
 	if err != nil {
@@ -145,37 +152,34 @@ func (d *Dex) HistoryHandler(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, request.NewErrResponse(err.Error()))
 	}
 
-	if params.IsCoingeckoFormat() {
-		data, err := d.history.GetCoingeckoHistory(params)
-		if err != nil {
-			l.WithError(err).Error("error when getting history")
+	entry, err := d.cached(ctx, historyKey(ctx, params), params.MustGetMarketId(), func(c context.Context) (any, error) {
+		if params.IsCoingeckoFormat() {
+			data, err := d.history.GetCoingeckoHistory(c, params)
+			if data == nil || err != nil {
+				return []struct{}{}, err
+			}
 
-			return ctx.JSON(http.StatusBadRequest, request.NewUnknownErrorResponse())
+			return data, nil
 		}
 
-		if data == nil {
-			return ctx.JSON(http.StatusOK, []struct{}{})
+		data, err := d.history.GetHistory(c, params)
+		if data == nil || err != nil {
+			return []struct{}{}, err
 		}
 
-		return ctx.JSON(http.StatusOK, data)
-	}
-
-	data, err := d.history.GetHistory(params)
+		return data, nil
+	})
 	if err != nil {
 		l.WithError(err).Error("error when getting history")
 
 		return ctx.JSON(http.StatusBadRequest, request.NewUnknownErrorResponse())
 	}
 
-	if data == nil {
-		return ctx.JSON(http.StatusOK, []struct{}{})
-	}
-
-	return ctx.JSON(http.StatusOK, data)
+	return d.writeCached(ctx, entry)
 }
 
 func (d *Dex) IntervalsHandler(ctx echo.Context) error {
-	l := d.getMethodLogger("IntervalsHandler")
+	l := d.getMethodLogger(ctx, "IntervalsHandler")
 
 	params, err := request.NewDexInterval(ctx)
 	if err != nil {
@@ -190,35 +194,33 @@ func (d *Dex) IntervalsHandler(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, request.NewErrResponse(err.Error()))
 	}
 
-	if params.IsTradingViewFormat() {
-		data, err := d.intervals.GetTradingViewIntervals(params.MustGetMarketId(), params.Minutes, params.Limit)
-		if err != nil {
-			l.WithError(err).Error("error when getting history")
-
-			return ctx.JSON(http.StatusBadRequest, request.NewUnknownErrorResponse())
+	marketId := params.MustGetMarketId()
+	entry, err := d.cached(ctx, intervalsKey(ctx, params), marketId, func(c context.Context) (any, error) {
+		if params.IsTradingViewFormat() {
+			data, err := d.intervals.GetTradingViewIntervals(c, marketId, params.Minutes, params.Limit)
+			if data == nil || err != nil {
+				return []struct{}{}, err
+			}
+
+			return data, nil
 		}
 
-		if data == nil {
-			return ctx.JSON(http.StatusOK, []struct{}{})
+		data, err := d.intervals.GetIntervals(c, marketId, params.Minutes, params.Limit)
+		if data == nil || err != nil {
+			return []struct{}{}, err
 		}
 
-		return ctx.JSON(http.StatusOK, data)
-	}
-
-	data, err := d.intervals.GetIntervals(params.MustGetMarketId(), params.Minutes, params.Limit)
+		return data, nil
+	})
 	if err != nil {
 		l.WithError(err).Error("error when getting history")
 
 		return ctx.JSON(http.StatusBadRequest, request.NewUnknownErrorResponse())
 	}
 
-	if data == nil {
-		return ctx.JSON(http.StatusOK, []struct{}{})
-	}
-
-	return ctx.JSON(http.StatusOK, data)
+	return d.writeCached(ctx, entry)
 }
 
-func (d *Dex) getMethodLogger(method string) logrus.FieldLogger {
-	return d.logger.WithField("struct", "DexController").WithField("method", method)
+func (d *Dex) getMethodLogger(ctx echo.Context, method string) logrus.FieldLogger {
+	return logging.FromContext(ctx, d.logger).WithField("struct", "DexController").WithField("method", method)
 }
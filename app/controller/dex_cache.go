@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/httpcache"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+type responseCache interface {
+	Get(key string) (*httpcache.Entry, bool)
+	Generation(tag string) uint64
+	Set(key, tag string, generation uint64, data any) (*httpcache.Entry, error)
+}
+
+// cached returns the cached response stored under key or loads, caches and returns it.
+// tag is the market the response is built from, httpcache.AnyMarket when it is built from all markets.
+// The response is not cached when the market is invalidated while it is loaded.
+func (d *Dex) cached(ctx echo.Context, key, tag string, load func(ctx context.Context) (any, error)) (*httpcache.Entry, error) {
+	if entry, ok := d.cache.Get(key); ok {
+		return entry, nil
+	}
+
+	generation := d.cache.Generation(tag)
+	data, err := load(ctx.Request().Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return d.cache.Set(key, tag, generation, data)
+}
+
+// cacheKey builds the key of a response from the route and its normalized query params
+func cacheKey(ctx echo.Context, params url.Values) string {
+	return ctx.Path() + "?" + params.Encode()
+}
+
+// writeCached writes a cached v1 response or 304 when the client already has it
+func (d *Dex) writeCached(ctx echo.Context, entry *httpcache.Entry) error {
+	if d.notModified(ctx, entry.ETag, entry) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return ctx.JSONBlob(http.StatusOK, entry.Body)
+}
+
+// writeCachedEnvelope writes a cached response in the /api/v2 envelope or 304 when the client already has it.
+// The envelope carries the request id, so the ETag is weak: the data is the same, the bytes are not.
+func (d *Dex) writeCachedEnvelope(ctx echo.Context, entry *httpcache.Entry) error {
+	if d.notModified(ctx, "W/"+entry.ETag, entry) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return ctx.JSON(http.StatusOK, response.NewEnvelope(json.RawMessage(entry.Body), requestId(ctx)))
+}
+
+// notModified sets the caching headers and reports whether the If-None-Match header matches the ETag
+func (d *Dex) notModified(ctx echo.Context, etag string, entry *httpcache.Entry) bool {
+	h := ctx.Response().Header()
+	h.Set(headerETag, etag)
+	h.Set(echo.HeaderLastModified, entry.LastModified.Format(http.TimeFormat))
+	h.Set(echo.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(d.maxAge/time.Second)))
+
+	return etagMatches(ctx.Request().Header.Get(headerIfNoneMatch), etag)
+}
+
+// etagMatches uses the weak comparison required for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func tickersKey(ctx echo.Context, p *request.TickersParams) string {
+	return cacheKey(ctx, url.Values{"format": {p.Format}})
+}
+
+func ordersKey(ctx echo.Context, p *request.OrdersParams) string {
+	return cacheKey(ctx, url.Values{
+		"format":    {p.Format},
+		"market_id": {p.MustGetMarketId()},
+		"depth":     {strconv.Itoa(p.Depth)},
+	})
+}
+
+func historyKey(ctx echo.Context, p *request.HistoryParams) string {
+	return cacheKey(ctx, url.Values{
+		"format":     {p.Format},
+		"market_id":  {p.MustGetMarketId()},
+		"type":       {p.OrderType},
+		"limit":      {strconv.Itoa(p.Limit)},
+		"start_time": {strconv.FormatInt(p.StartTime, 10)},
+		"end_time":   {strconv.FormatInt(p.EndTime, 10)},
+		"address":    {p.Address},
+	})
+}
+
+func intervalsKey(ctx echo.Context, p *request.DexInterval) string {
+	//any format other than tv returns the default format
+	format := ""
+	if p.IsTradingViewFormat() {
+		format = p.Format
+	}
+
+	return cacheKey(ctx, url.Values{
+		"format":    {format},
+		"market_id": {p.MustGetMarketId()},
+		"minutes":   {strconv.Itoa(p.Minutes)},
+		"limit":     {strconv.Itoa(p.Limit)},
+	})
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/export"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/logging"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type exportService interface {
+	ExportTrades(ctx context.Context, marketId string, from, to time.Time, w export.Writer) error
+	ExportCandles(ctx context.Context, marketId string, length int, from, to time.Time, w export.Writer) error
+}
+
+// DexExport streams the whole trade history and candles of a market
+type DexExport struct {
+	logger  logrus.FieldLogger
+	service exportService
+	//replaces the request timeout, skipped on the export routes
+	timeout time.Duration
+}
+
+func NewDexExportController(logger logrus.FieldLogger, service exportService, timeout time.Duration) (*DexExport, error) {
+	if logger == nil || service == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewDexExportController")
+	}
+
+	return &DexExport{logger: logger, service: service, timeout: timeout}, nil
+}
+
+func (d *DexExport) TradesHandler(ctx echo.Context) error {
+	l := d.getMethodLogger(ctx, "TradesHandler")
+
+	params, err := request.NewDexExportTrades(ctx)
+	if err != nil {
+		return respondErrResponse(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	if err = params.Validate(); err != nil {
+		return respondErrResponse(ctx, l, validationErr(err))
+	}
+
+	marketId := params.MustGetMarketId()
+	from, to := converter.MillisecondsToTime(params.StartTime), converter.MillisecondsToTime(params.EndTime)
+
+	return d.stream(ctx, l, params.Format, exportFileName(marketId, "trades", params.Format), func(c context.Context, w export.Writer) error {
+		return d.service.ExportTrades(c, marketId, from, to, w)
+	})
+}
+
+func (d *DexExport) CandlesHandler(ctx echo.Context) error {
+	l := d.getMethodLogger(ctx, "CandlesHandler")
+
+	params, err := request.NewDexExportCandles(ctx)
+	if err != nil {
+		return respondErrResponse(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	if err = params.Validate(); err != nil {
+		return respondErrResponse(ctx, l, validationErr(err))
+	}
+
+	marketId := params.MustGetMarketId()
+	from, to := converter.MillisecondsToTime(params.StartTime), converter.MillisecondsToTime(params.EndTime)
+	name := exportFileName(marketId, fmt.Sprintf("candles-%dm", params.Minutes), params.Format)
+
+	return d.stream(ctx, l, params.Format, name, func(c context.Context, w export.Writer) error {
+		return d.service.ExportCandles(c, marketId, params.Minutes, from, to, w)
+	})
+}
+
+// stream writes the rows as they are read. Once the first rows are sent the status can not change anymore, so a
+// later failure only ends the response early.
+func (d *DexExport) stream(ctx echo.Context, l logrus.FieldLogger, format, fileName string, write func(c context.Context, w export.Writer) error) error {
+	c, cancel := context.WithTimeout(context.WithoutCancel(ctx.Request().Context()), d.timeout)
+	defer cancel()
+	//the client going away still stops the export
+	stop := context.AfterFunc(ctx.Request().Context(), cancel)
+	defer stop()
+
+	res := ctx.Response()
+	w, err := export.NewWriter(format, res)
+	if err != nil {
+		return respondErrResponse(ctx, l, validationErr(err))
+	}
+
+	res.Header().Set(echo.HeaderContentType, export.ContentType(format))
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+
+	err = write(c, w)
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err == nil {
+		if !res.Committed {
+			res.WriteHeader(http.StatusOK)
+		}
+
+		return nil
+	}
+
+	if !res.Committed {
+		res.Header().Del(echo.HeaderContentType)
+		res.Header().Del(echo.HeaderContentDisposition)
+
+		return respondErrResponse(ctx, l, err)
+	}
+
+	l.WithError(err).Error("export aborted after it started")
+
+	return nil
+}
+
+func (d *DexExport) getMethodLogger(ctx echo.Context, method string) logrus.FieldLogger {
+	return logging.FromContext(ctx, d.logger).WithField("struct", "DexExportController").WithField("method", method)
+}
+
+func exportFileName(marketId, kind, format string) string {
+	return fmt.Sprintf("%s-%s.%s", strings.ReplaceAll(marketId, "/", "_"), kind, format)
+}
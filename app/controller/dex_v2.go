@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/httpcache"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/labstack/echo/v4"
+)
+
+func (d *Dex) TickersV2Handler(ctx echo.Context) error {
+	l := d.getMethodLogger(ctx, "TickersV2Handler")
+
+	params, err := request.NewTickersParams(ctx)
+	if err != nil {
+		return respondError(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	entry, err := d.cached(ctx, tickersKey(ctx, params), httpcache.AnyMarket, func(c context.Context) (any, error) {
+		if params.IsCoingeckoFormat() {
+			data, err := d.tickers.GetCoingeckoTickers(c)
+
+			return emptyIfNil(data), err
+		}
+
+		data, err := d.tickers.GetTickers(c)
+
+		return emptyIfNil(data), err
+	})
+	if err != nil {
+		return respondError(ctx, l, err)
+	}
+
+	return d.writeCachedEnvelope(ctx, entry)
+}
+
+func (d *Dex) OrdersV2Handler(ctx echo.Context) error {
+	l := d.getMethodLogger(ctx, "OrdersV2Handler")
+
+	params, err := request.NewOrdersParams(ctx)
+	if err != nil {
+		return respondError(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	if err = params.Validate(); err != nil {
+		return respondError(ctx, l, validationErr(err))
+	}
+
+	marketId := params.MustGetMarketId()
+	entry, err := d.cached(ctx, ordersKey(ctx, params), marketId, func(c context.Context) (any, error) {
+		if params.IsCoingeckoFormat() {
+			data, err := d.orders.GetCoingeckoMarketOrders(c, marketId, params.Depth)
+			if err != nil {
+				return nil, err
+			}
+			data.Bids = emptyIfNil(data.Bids)
+			data.Asks = emptyIfNil(data.Asks)
+
+			return data, nil
+		}
+
+		data, err := d.orders.GetMarketOrders(c, marketId, params.Depth)
+		if err != nil {
+			return nil, err
+		}
+		data.Bids = emptyIfNil(data.Bids)
+		data.Asks = emptyIfNil(data.Asks)
+
+		return data, nil
+	})
+	if err != nil {
+		return respondError(ctx, l, err)
+	}
+
+	return d.writeCachedEnvelope(ctx, entry)
+}
+
+func (d *Dex) HistoryV2Handler(ctx echo.Context) error {
+	l := d.getMethodLogger(ctx, "HistoryV2Handler")
+
+	params, err := request.NewHistoryParams(ctx)
+	if err != nil {
+		return respondError(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	if err = params.Validate(); err != nil {
+		return respondError(ctx, l, validationErr(err))
+	}
+
+	marketId := params.MustGetMarketId()
+	entry, err := d.cached(ctx, historyKey(ctx, params), marketId, func(c context.Context) (any, error) {
+		//v1 returns an empty list for unknown markets, v2 reports them
+		if _, err := d.markets.GetMarket(c, marketId); err != nil {
+			return nil, err
+		}
+
+		if params.IsCoingeckoFormat() {
+			return d.history.GetCoingeckoHistory(c, params)
+		}
+
+		data, err := d.history.GetHistory(c, params)
+
+		return emptyIfNil(data), err
+	})
+	if err != nil {
+		return respondError(ctx, l, err)
+	}
+
+	return d.writeCachedEnvelope(ctx, entry)
+}
+
+func (d *Dex) IntervalsV2Handler(ctx echo.Context) error {
+	l := d.getMethodLogger(ctx, "IntervalsV2Handler")
+
+	params, err := request.NewDexInterval(ctx)
+	if err != nil {
+		return respondError(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	if err = params.Validate(); err != nil {
+		return respondError(ctx, l, validationErr(err))
+	}
+
+	marketId := params.MustGetMarketId()
+	entry, err := d.cached(ctx, intervalsKey(ctx, params), marketId, func(c context.Context) (any, error) {
+		if params.IsTradingViewFormat() {
+			data, err := d.intervals.GetTradingViewIntervals(c, marketId, params.Minutes, params.Limit)
+
+			return emptyIfNil(data), err
+		}
+
+		data, err := d.intervals.GetIntervals(c, marketId, params.Minutes, params.Limit)
+
+		return emptyIfNil(data), err
+	})
+	if err != nil {
+		return respondError(ctx, l, err)
+	}
+
+	return d.writeCachedEnvelope(ctx, entry)
+}
+
+// emptyIfNil makes sure empty lists are encoded as [] instead of null
+func emptyIfNil[T any](list []T) []T {
+	if list == nil {
+		return []T{}
+	}
+
+	return list
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/openapi"
+	"github.com/labstack/echo/v4"
+)
+
+// swaggerUI holds the Swagger UI files, served from the binary so the docs page loads no third party scripts
+//
+//go:embed swagger-ui/swagger-ui-bundle.js swagger-ui/swagger-ui.css
+var swaggerUI embed.FS
+
+// docsPage renders the spec with Swagger UI
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8"/>
+  <title>BZE Aggregator API</title>
+  <link rel="stylesheet" href="%[1]s/swagger-ui.css"/>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="%[1]s/swagger-ui-bundle.js"></script>
+<script>
+  window.onload = () => {
+    window.ui = SwaggerUIBundle({url: '%[2]s', dom_id: '#swagger-ui', validatorUrl: null});
+  };
+</script>
+</body>
+</html>`
+
+type DocsController struct {
+	spec   []byte
+	page   string
+	assets http.Handler
+}
+
+func NewDocsController() (*DocsController, error) {
+	spec, err := openapi.JSON()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := fs.Sub(swaggerUI, "swagger-ui")
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(openapi.DocsAssetsPath, "/*")
+
+	return &DocsController{
+		spec:   spec,
+		page:   fmt.Sprintf(docsPage, prefix, openapi.SpecPath),
+		assets: http.StripPrefix(prefix, http.FileServer(http.FS(files))),
+	}, nil
+}
+
+func (c *DocsController) SpecHandler(ctx echo.Context) error {
+	return ctx.JSONBlob(http.StatusOK, c.spec)
+}
+
+func (c *DocsController) UIHandler(ctx echo.Context) error {
+	return ctx.HTML(http.StatusOK, c.page)
+}
+
+// AssetsHandler serves the embedded Swagger UI files
+func (c *DocsController) AssetsHandler(ctx echo.Context) error {
+	c.assets.ServeHTTP(ctx.Response(), ctx.Request())
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// respondData writes a successful /api/v2 response
+func respondData(ctx echo.Context, data any) error {
+	return ctx.JSON(http.StatusOK, response.NewEnvelope(data, requestId(ctx)))
+}
+
+// respondError writes a failed /api/v2 response. The status code and the message are given by the error type,
+// details of untyped errors are only logged.
+func respondError(ctx echo.Context, l logrus.FieldLogger, err error) error {
+	code := internal.ErrorCodeOf(err)
+	status := internal.StatusCode(code)
+	if status >= http.StatusInternalServerError {
+		l.WithError(err).Error("request failed")
+	} else {
+		l.WithError(err).Info("request rejected")
+	}
+
+	return RespondErrorEnvelope(ctx, status, string(code), internal.ErrorMessageOf(err))
+}
+
+// respondErrResponse writes a failed v1 JSON response, with the status code given by the error type like respondError
+func respondErrResponse(ctx echo.Context, l logrus.FieldLogger, err error) error {
+	code := internal.ErrorCodeOf(err)
+	status := internal.StatusCode(code)
+	if status >= http.StatusInternalServerError {
+		l.WithError(err).Error("request failed")
+	} else {
+		l.WithError(err).Info("request rejected")
+	}
+
+	return ctx.JSON(status, request.NewErrResponse(internal.ErrorMessageOf(err)))
+}
+
+// RespondErrorEnvelope writes an /api/v2 error response
+func RespondErrorEnvelope(ctx echo.Context, status int, code, message string) error {
+	return ctx.JSON(status, response.NewErrorEnvelope(code, message, requestId(ctx)))
+}
+
+// validationErr wraps the error returned by the Validate method of a request DTO
+func validationErr(err error) error {
+	return internal.NewValidationErr(err.Error())
+}
+
+func requestId(ctx echo.Context) string {
+	return ctx.Response().Header().Get(echo.HeaderXRequestID)
+}
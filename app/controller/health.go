@@ -1,35 +1,46 @@
 package controller
 
 import (
-	"fmt"
+	"context"
 	"net/http"
 
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/logging"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 )
 
 type BalanceHealthCheckService interface {
-	CheckBalances(params *request.BalanceHealthParams) []dto.AddressHealthCheck
+	CheckBalances(ctx context.Context, params *request.BalanceHealthParams) []dto.AddressHealthCheck
 }
 
 type MarketHealthCheckService interface {
-	GetMarketHealth(marketId string, minutesAgo int) dto.MarketHealth
-	GetAggregatorHealth(minutesAgo int) dto.AggregatorHealth
-	GetNodesHealth() dto.NodesHealth
+	GetMarketHealth(ctx context.Context, marketId string, minutesAgo int) dto.MarketHealth
+	GetAggregatorHealth(ctx context.Context, minutesAgo int) dto.AggregatorHealth
+	GetNodesHealth(ctx context.Context) dto.NodesHealth
+}
+
+type healthStatusService interface {
+	GetStatus(ctx context.Context, checkName string, limit int) (*response.HealthStatus, error)
+}
+
+type readinessService interface {
+	Check(ctx context.Context) dto.Readiness
 }
 
 type HealthCheckController struct {
 	logger         logrus.FieldLogger
 	service        MarketHealthCheckService
 	balanceChecker BalanceHealthCheckService
+	status         healthStatusService
+	readiness      readinessService
 }
 
-func NewHealthCheckController(logger logrus.FieldLogger, service MarketHealthCheckService, balance BalanceHealthCheckService) (*HealthCheckController, error) {
-	if logger == nil || service == nil || balance == nil {
+func NewHealthCheckController(logger logrus.FieldLogger, service MarketHealthCheckService, balance BalanceHealthCheckService, status healthStatusService, readiness readinessService) (*HealthCheckController, error) {
+	if logger == nil || service == nil || balance == nil || status == nil || readiness == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewHealthCheckController")
 	}
 
@@ -37,11 +48,13 @@ func NewHealthCheckController(logger logrus.FieldLogger, service MarketHealthChe
 		logger:         logger,
 		service:        service,
 		balanceChecker: balance,
+		status:         status,
+		readiness:      readiness,
 	}, nil
 }
 
 func (c *HealthCheckController) DexMarketCheckHandler(ctx echo.Context) error {
-	l := c.getMethodLogger("DexMarketCheckHandler")
+	l := c.getMethodLogger(ctx, "DexMarketCheckHandler")
 
 	params, err := request.NewMarketHealthRequest(ctx)
 	if err != nil {
@@ -56,11 +69,11 @@ func (c *HealthCheckController) DexMarketCheckHandler(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, request.NewErrResponse("invalid parameters"))
 	}
 
-	return ctx.JSON(http.StatusOK, c.service.GetMarketHealth(params.MarketId, params.Minutes))
+	return ctx.JSON(http.StatusOK, c.service.GetMarketHealth(ctx.Request().Context(), params.MarketId, params.Minutes))
 }
 
 func (c *HealthCheckController) DexAggregatorCheckHandler(ctx echo.Context) error {
-	l := c.getMethodLogger("DexAggregatorCheckHandler")
+	l := c.getMethodLogger(ctx, "DexAggregatorCheckHandler")
 
 	params, err := request.NewAggregatorHealthRequest(ctx)
 	if err != nil {
@@ -69,15 +82,44 @@ func (c *HealthCheckController) DexAggregatorCheckHandler(ctx echo.Context) erro
 		return ctx.JSON(http.StatusBadRequest, request.NewErrResponse("invalid request"))
 	}
 
-	return ctx.JSON(http.StatusOK, c.service.GetAggregatorHealth(params.Minutes))
+	return ctx.JSON(http.StatusOK, c.service.GetAggregatorHealth(ctx.Request().Context(), params.Minutes))
 }
 
 func (c *HealthCheckController) NodesCheckHandler(ctx echo.Context) error {
-	return ctx.JSON(http.StatusOK, c.service.GetNodesHealth())
+	return ctx.JSON(http.StatusOK, c.service.GetNodesHealth(ctx.Request().Context()))
+}
+
+// ReadinessHandler answers 503 when a dependency needed to serve requests is down, to be used by readiness probes
+func (c *HealthCheckController) ReadinessHandler(ctx echo.Context) error {
+	result := c.readiness.Check(ctx.Request().Context())
+	if !result.IsHealthy {
+		return ctx.JSON(http.StatusServiceUnavailable, result)
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// StatusHandler returns the state of the checks run by the listener, see alerting.Evaluator
+func (c *HealthCheckController) StatusHandler(ctx echo.Context) error {
+	status, err := c.getStatus(ctx)
+	if err != nil {
+		return respondErrResponse(ctx, c.getMethodLogger(ctx, "StatusHandler"), err)
+	}
+
+	return ctx.JSON(http.StatusOK, status)
 }
 
-func (c *HealthCheckController) getMethodLogger(method string) logrus.FieldLogger {
-	return c.logger.WithField("struct", "HealthCheckController").WithField("method", method)
+func (c *HealthCheckController) getStatus(ctx echo.Context) (*response.HealthStatus, error) {
+	params, err := request.NewHealthStatusParams(ctx)
+	if err != nil {
+		return nil, internal.NewInvalidRequestErr("invalid request")
+	}
+
+	return c.status.GetStatus(ctx.Request().Context(), params.Check, params.Limit)
+}
+
+func (c *HealthCheckController) getMethodLogger(ctx echo.Context, method string) logrus.FieldLogger {
+	return logging.FromContext(ctx, c.logger).WithField("struct", "HealthCheckController").WithField("method", method)
 }
 
 func (c *HealthCheckController) CheckBalancesHandler(ctx echo.Context) error {
@@ -86,26 +128,9 @@ func (c *HealthCheckController) CheckBalancesHandler(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, request.NewErrResponse("invalid request"))
 	}
 
-	result := response.BalanceHealthResponse{
-		IsHealthy: true,
-		Errors:    "",
-	}
-	checkResult := c.balanceChecker.CheckBalances(params)
-	for _, cr := range checkResult {
-		if cr.IsHealthy {
-			continue
-		}
-		result.IsHealthy = false
-
-		addrErr := fmt.Sprintf("[%s]: [%s]", cr.Address, cr.Error)
-		//add first error
-		if result.Errors == "" {
-			result.Errors = addrErr
-			continue
-		}
-
-		result.Errors = fmt.Sprintf("%s; %s", result.Errors, addrErr)
+	if err = params.Validate(); err != nil {
+		return ctx.JSON(http.StatusBadRequest, request.NewErrResponse(err.Error()))
 	}
 
-	return ctx.JSON(http.StatusOK, result)
+	return ctx.JSON(http.StatusOK, response.NewBalanceHealthResponse(c.balanceChecker.CheckBalances(ctx.Request().Context(), params)))
 }
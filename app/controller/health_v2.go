@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/labstack/echo/v4"
+)
+
+func (c *HealthCheckController) DexMarketCheckV2Handler(ctx echo.Context) error {
+	l := c.getMethodLogger(ctx, "DexMarketCheckV2Handler")
+
+	params, err := request.NewMarketHealthRequest(ctx)
+	if err != nil {
+		return respondError(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	if err = params.Validate(); err != nil {
+		return respondError(ctx, l, validationErr(err))
+	}
+
+	return respondData(ctx, c.service.GetMarketHealth(ctx.Request().Context(), params.MarketId, params.Minutes))
+}
+
+func (c *HealthCheckController) DexAggregatorCheckV2Handler(ctx echo.Context) error {
+	l := c.getMethodLogger(ctx, "DexAggregatorCheckV2Handler")
+
+	params, err := request.NewAggregatorHealthRequest(ctx)
+	if err != nil {
+		return respondError(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	return respondData(ctx, c.service.GetAggregatorHealth(ctx.Request().Context(), params.Minutes))
+}
+
+func (c *HealthCheckController) NodesCheckV2Handler(ctx echo.Context) error {
+	return respondData(ctx, c.service.GetNodesHealth(ctx.Request().Context()))
+}
+
+func (c *HealthCheckController) CheckBalancesV2Handler(ctx echo.Context) error {
+	l := c.getMethodLogger(ctx, "CheckBalancesV2Handler")
+
+	params, err := request.NewBalanceHealthParams(ctx)
+	if err != nil {
+		return respondError(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	if err = params.Validate(); err != nil {
+		return respondError(ctx, l, validationErr(err))
+	}
+
+	return respondData(ctx, response.NewBalanceHealthResponse(c.balanceChecker.CheckBalances(ctx.Request().Context(), params)))
+}
+
+func (c *HealthCheckController) StatusV2Handler(ctx echo.Context) error {
+	status, err := c.getStatus(ctx)
+	if err != nil {
+		return respondError(ctx, c.getMethodLogger(ctx, "StatusV2Handler"), err)
+	}
+
+	return respondData(ctx, status)
+}
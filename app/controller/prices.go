@@ -1,31 +1,100 @@
 package controller
 
 import (
+	"context"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/logging"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 	"net/http"
+	"time"
 )
 
 type PricesService interface {
-	GetPrices() []dto.CoinPrice
+	GetPrices(ctx context.Context, currencies []string) ([]dto.CoinPrice, error)
+}
+
+type pricesHistoryService interface {
+	GetHistory(ctx context.Context, denom, currency string, from, to time.Time, step time.Duration) ([]response.PricePoint, error)
 }
 
 type PricesController struct {
 	service PricesService
+	history pricesHistoryService
 	logger  logrus.FieldLogger
 }
 
-func NewPricesController(logger logrus.FieldLogger, service PricesService) (*PricesController, error) {
-	if logger == nil || service == nil {
+func NewPricesController(logger logrus.FieldLogger, service PricesService, history pricesHistoryService) (*PricesController, error) {
+	if logger == nil || service == nil || history == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewPricesController")
 	}
 
-	return &PricesController{service: service, logger: logger}, nil
+	return &PricesController{service: service, history: history, logger: logger}, nil
 }
 
 func (c *PricesController) PricesHandler(ctx echo.Context) error {
+	prices, err := c.getPrices(ctx)
+	if err != nil {
+		return respondErrResponse(ctx, c.getMethodLogger(ctx, "PricesHandler"), err)
+	}
+
+	return ctx.JSON(http.StatusOK, prices)
+}
+
+func (c *PricesController) PricesV2Handler(ctx echo.Context) error {
+	prices, err := c.getPrices(ctx)
+	if err != nil {
+		return respondError(ctx, c.getMethodLogger(ctx, "PricesV2Handler"), err)
+	}
+
+	return respondData(ctx, emptyIfNil(prices))
+}
+
+func (c *PricesController) HistoryHandler(ctx echo.Context) error {
+	points, err := c.getHistory(ctx)
+	if err != nil {
+		return respondErrResponse(ctx, c.getMethodLogger(ctx, "HistoryHandler"), err)
+	}
+
+	return ctx.JSON(http.StatusOK, emptyIfNil(points))
+}
+
+func (c *PricesController) HistoryV2Handler(ctx echo.Context) error {
+	points, err := c.getHistory(ctx)
+	if err != nil {
+		return respondError(ctx, c.getMethodLogger(ctx, "HistoryV2Handler"), err)
+	}
+
+	return respondData(ctx, emptyIfNil(points))
+}
+
+func (c *PricesController) getPrices(ctx echo.Context) ([]dto.CoinPrice, error) {
+	params, err := request.NewPricesParams(ctx)
+	if err != nil {
+		return nil, internal.NewInvalidRequestErr("invalid request")
+	}
+
+	return c.service.GetPrices(ctx.Request().Context(), params.Currencies())
+}
+
+func (c *PricesController) getHistory(ctx echo.Context) ([]response.PricePoint, error) {
+	params, err := request.NewPriceHistoryParams(ctx)
+	if err != nil {
+		return nil, internal.NewInvalidRequestErr("invalid request")
+	}
+
+	if err = params.Validate(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	from, to := params.Range()
+
+	return c.history.GetHistory(ctx.Request().Context(), params.Denom, params.Currency, from, to, params.Step())
+}
 
-	return ctx.JSON(http.StatusOK, c.service.GetPrices())
+func (c *PricesController) getMethodLogger(ctx echo.Context, method string) logrus.FieldLogger {
+	return logging.FromContext(ctx, c.logger).WithField("struct", "PricesController").WithField("func", method)
 }
@@ -1,33 +1,47 @@
 package controller
 
 import (
+	"context"
+	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/logging"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type SupplyService interface {
-	GetTotalSupply(denom string) (string, error)
-	GetCirculatingSupply(denom string) (string, error)
+	GetTotalSupply(ctx context.Context, denom string) (string, error)
+	GetCirculatingSupply(ctx context.Context, denom string) (string, error)
+	GetCirculatingBreakdown(ctx context.Context, denom string) (*response.CirculatingSupply, error)
+	ListSupply(ctx context.Context) ([]response.SupplyListItem, error)
+}
+
+type supplyHistoryService interface {
+	GetHistory(ctx context.Context, denom string, from, to time.Time, step time.Duration) ([]response.SupplyPoint, error)
 }
 
 type SupplyController struct {
 	service SupplyService
+	history supplyHistoryService
 	logger  logrus.FieldLogger
 }
 
-func NewSupplyController(logger logrus.FieldLogger, service SupplyService) (*SupplyController, error) {
-	if logger == nil || service == nil {
+func NewSupplyController(logger logrus.FieldLogger, service SupplyService, history supplyHistoryService) (*SupplyController, error) {
+	if logger == nil || service == nil || history == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewSupplyController")
 	}
 
-	return &SupplyController{service: service, logger: logger.WithField("struct", "SupplyController")}, nil
+	return &SupplyController{service: service, history: history, logger: logger}, nil
 }
 
 func (c *SupplyController) TotalSupplyHandler(ctx echo.Context) error {
-	l := c.logger.WithField("func", "TotalSupplyHandler")
+	l := c.getMethodLogger(ctx, "TotalSupplyHandler")
 	params, err := request.NewSupplyParams(ctx)
 	if err != nil {
 		l.WithError(err).Error("failed to create total supply params")
@@ -35,27 +49,103 @@ func (c *SupplyController) TotalSupplyHandler(ctx echo.Context) error {
 		return ctx.String(http.StatusBadRequest, "invalid request")
 	}
 
-	supply, err := c.service.GetTotalSupply(params.Denom)
+	supply, err := c.service.GetTotalSupply(ctx.Request().Context(), params.Denom)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, err.Error())
+		l.WithError(err).Warn("failed to get total supply")
+		if service.IsRegistryErr(err) {
+			return ctx.String(http.StatusBadRequest, err.Error())
+		}
+
+		//v1 integrators expect 0 when the supply can not be fetched
+		return ctx.String(http.StatusOK, "0")
 	}
 
 	return ctx.String(http.StatusOK, supply)
 }
 
 func (c *SupplyController) CirculatingSupplyHandler(ctx echo.Context) error {
-	l := c.logger.WithField("func", "CirculatingSupplyHandler")
-	params, err := request.NewSupplyParams(ctx)
+	l := c.getMethodLogger(ctx, "CirculatingSupplyHandler")
+	params, err := request.NewCirculatingSupplyParams(ctx)
 	if err != nil {
 		l.WithError(err).Error("failed to create circulating supply params")
 
 		return ctx.String(http.StatusBadRequest, "invalid request")
 	}
 
-	supply, err := c.service.GetCirculatingSupply(params.Denom)
+	if params.Verbose {
+		breakdown, err := c.service.GetCirculatingBreakdown(ctx.Request().Context(), params.Denom)
+		if err != nil {
+			return respondErrResponse(ctx, l, err)
+		}
+
+		return ctx.JSON(http.StatusOK, breakdown)
+	}
+
+	supply, err := c.service.GetCirculatingSupply(ctx.Request().Context(), params.Denom)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, err.Error())
+		l.WithError(err).Warn("failed to get circulating supply")
+		if service.IsRegistryErr(err) {
+			return ctx.String(http.StatusBadRequest, err.Error())
+		}
+
+		//v1 integrators expect 0 when the supply can not be fetched
+		return ctx.String(http.StatusOK, "0")
 	}
 
 	return ctx.String(http.StatusOK, supply)
 }
+
+// ListHandler returns the supply of every chain registry asset as JSON or, with format=text, one asset per line
+func (c *SupplyController) ListHandler(ctx echo.Context) error {
+	l := c.getMethodLogger(ctx, "ListHandler")
+	params, err := request.NewSupplyListParams(ctx)
+	if err != nil {
+		return respondErrResponse(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	list, err := c.service.ListSupply(ctx.Request().Context())
+	if err != nil {
+		return respondErrResponse(ctx, l, err)
+	}
+
+	if !params.IsTextFormat() {
+		return ctx.JSON(http.StatusOK, list)
+	}
+
+	var b strings.Builder
+	b.WriteString("denom symbol total circulating\n")
+	for _, item := range list {
+		b.WriteString(fmt.Sprintf("%s %s %s %s\n", item.Denom, item.Symbol, item.Total, item.Circulating))
+	}
+
+	return ctx.String(http.StatusOK, b.String())
+}
+
+func (c *SupplyController) HistoryHandler(ctx echo.Context) error {
+	l := c.getMethodLogger(ctx, "HistoryHandler")
+	points, err := c.getHistory(ctx)
+	if err != nil {
+		return respondErrResponse(ctx, l, err)
+	}
+
+	return ctx.JSON(http.StatusOK, emptyIfNil(points))
+}
+
+func (c *SupplyController) getHistory(ctx echo.Context) ([]response.SupplyPoint, error) {
+	params, err := request.NewSupplyHistoryParams(ctx)
+	if err != nil {
+		return nil, internal.NewInvalidRequestErr("invalid request")
+	}
+
+	if err = params.Validate(); err != nil {
+		return nil, validationErr(err)
+	}
+
+	from, to := params.Range()
+
+	return c.history.GetHistory(ctx.Request().Context(), params.Denom, from, to, params.Step())
+}
+
+func (c *SupplyController) getMethodLogger(ctx echo.Context, method string) logrus.FieldLogger {
+	return logging.FromContext(ctx, c.logger).WithField("struct", "SupplyController").WithField("func", method)
+}
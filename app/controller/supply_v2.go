@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/labstack/echo/v4"
+)
+
+func (c *SupplyController) TotalSupplyV2Handler(ctx echo.Context) error {
+	l := c.getMethodLogger(ctx, "TotalSupplyV2Handler")
+	params, err := request.NewSupplyParams(ctx)
+	if err != nil {
+		return respondError(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	supply, err := c.service.GetTotalSupply(ctx.Request().Context(), params.Denom)
+	if err != nil {
+		return respondError(ctx, l, err)
+	}
+
+	return respondData(ctx, response.Supply{Denom: params.Denom, Amount: supply})
+}
+
+func (c *SupplyController) HistoryV2Handler(ctx echo.Context) error {
+	points, err := c.getHistory(ctx)
+	if err != nil {
+		return respondError(ctx, c.getMethodLogger(ctx, "HistoryV2Handler"), err)
+	}
+
+	return respondData(ctx, emptyIfNil(points))
+}
+
+func (c *SupplyController) CirculatingSupplyV2Handler(ctx echo.Context) error {
+	l := c.getMethodLogger(ctx, "CirculatingSupplyV2Handler")
+	params, err := request.NewCirculatingSupplyParams(ctx)
+	if err != nil {
+		return respondError(ctx, l, internal.NewInvalidRequestErr("invalid request"))
+	}
+
+	if params.Verbose {
+		breakdown, err := c.service.GetCirculatingBreakdown(ctx.Request().Context(), params.Denom)
+		if err != nil {
+			return respondError(ctx, l, err)
+		}
+
+		return respondData(ctx, breakdown)
+	}
+
+	supply, err := c.service.GetCirculatingSupply(ctx.Request().Context(), params.Denom)
+	if err != nil {
+		return respondError(ctx, l, err)
+	}
+
+	return respondData(ctx, response.Supply{Denom: params.Denom, Amount: supply})
+}
+
+func (c *SupplyController) ListV2Handler(ctx echo.Context) error {
+	list, err := c.service.ListSupply(ctx.Request().Context())
+	if err != nil {
+		return respondError(ctx, c.getMethodLogger(ctx, "ListV2Handler"), err)
+	}
+
+	return respondData(ctx, list)
+}
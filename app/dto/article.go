@@ -9,4 +9,7 @@ type Article struct {
 	Description string    `json:"description"`
 	PublishDate time.Time `json:"publish_date"`
 	AuthorName  string    `json:"author_name"`
+	//the name of the feed the article was read from, e.g. medium
+	Source string   `json:"source"`
+	Tags   []string `json:"tags"`
 }
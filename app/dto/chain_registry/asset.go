@@ -3,6 +3,15 @@ package chain_registry
 const (
 	DenomBze  = "bze"
 	DenomUbze = "ubze"
+
+	TraceTypeIbc = "ibc"
+)
+
+// the sources an asset can be resolved from
+const (
+	AssetSourceRegistry = "registry"
+	AssetSourceIbc      = "ibc"
+	AssetSourceDefault  = "default"
 )
 
 type ChainRegistryAssetDenom struct {
@@ -17,6 +26,23 @@ type ChainRegistryAsset struct {
 	Name       string                    `json:"name"`
 	Display    string                    `json:"display"`
 	Symbol     string                    `json:"symbol"`
+	Traces     []ChainRegistryAssetTrace `json:"traces,omitempty"`
+
+	//where the asset was resolved from, it is not part of the chain registry schema
+	Source string `json:"source,omitempty"`
+}
+
+type ChainRegistryAssetTrace struct {
+	Type         string                          `json:"type"`
+	Counterparty ChainRegistryAssetTraceEndpoint `json:"counterparty"`
+	Chain        ChainRegistryAssetTraceEndpoint `json:"chain"`
+}
+
+type ChainRegistryAssetTraceEndpoint struct {
+	ChainName string `json:"chain_name,omitempty"`
+	BaseDenom string `json:"base_denom,omitempty"`
+	ChannelId string `json:"channel_id,omitempty"`
+	Path      string `json:"path,omitempty"`
 }
 
 // ChainRegistryAssetList -https://github.com/cosmos/chain-registry/blob/master/beezee/assetlist.json
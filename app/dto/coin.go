@@ -9,4 +9,16 @@ type CoinPrice struct {
 	Denom      string  `json:"denom"`
 	Price      float64 `json:"price"`
 	PriceDenom string  `json:"price_denom"`
+	//the price source, e.g. coingecko
+	Source string `json:"source"`
+	//when the source last updated the price (RFC 3339)
+	UpdatedAt string `json:"updated_at"`
+	//the price was updated longer ago than the configured staleness, usually because all the sources failed
+	Stale bool `json:"stale"`
+}
+
+// DenomTrace is the origin of an IBC denom: the path is made of port/channel pairs, e.g. transfer/channel-0
+type DenomTrace struct {
+	Path      string `json:"path"`
+	BaseDenom string `json:"base_denom"`
 }
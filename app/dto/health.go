@@ -2,6 +2,13 @@ package dto
 
 import "time"
 
+// the statuses of an address balance check
+const (
+	BalanceStatusOk    = "ok"
+	BalanceStatusLow   = "below_min"
+	BalanceStatusError = "error"
+)
+
 type MarketHealth struct {
 	IsHealthy bool      `json:"is_healthy"`
 	LastTrade time.Time `json:"last_trade"`
@@ -13,12 +20,46 @@ type AggregatorHealth struct {
 }
 
 type NodesHealth struct {
-	IsHealthy bool   `json:"is_healthy"`
-	Errors    string `json:"errors"`
+	IsHealthy bool `json:"is_healthy"`
+	//highest block among the nodes that answered
+	MaxHeight int64        `json:"max_height"`
+	Nodes     []NodeHealth `json:"nodes"`
+	//the errors of the nodes joined with ";", kept for the existing monitors
+	Errors string `json:"errors"`
+}
+
+// NodeHealth is the state of a single health node
+type NodeHealth struct {
+	Name       string `json:"name"`
+	IsHealthy  bool   `json:"is_healthy"`
+	Height     int64  `json:"height"`
+	CatchingUp bool   `json:"catching_up"`
+	LatencyMs  int64  `json:"latency_ms"`
+	//blocks behind the highest node
+	Lag   int64  `json:"lag"`
+	Error string `json:"error,omitempty"`
 }
 
 type AddressHealthCheck struct {
 	Address   string `json:"address"`
+	Denom     string `json:"denom"`
+	Balance   string `json:"balance"`
+	MinAmount string `json:"min_amount"`
+	//one of ok, below_min, error
+	Status    string `json:"status"`
+	IsHealthy bool   `json:"is_healthy"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Readiness tells if the API can serve requests, see health.Readiness
+type Readiness struct {
+	IsHealthy bool             `json:"is_healthy"`
+	Checks    []ReadinessCheck `json:"checks"`
+}
+
+type ReadinessCheck struct {
+	Name      string `json:"name"`
 	IsHealthy bool   `json:"is_healthy"`
-	Error     string `json:"error"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
 }
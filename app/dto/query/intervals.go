@@ -10,6 +10,8 @@ type IntervalsParams struct {
 	Length   int
 	Limit    int
 	StartAt  time.Time
+	//optional, excluded
+	EndAt time.Time
 }
 
 type IntervalsMap map[int64]entity.MarketHistoryInterval
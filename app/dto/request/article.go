@@ -0,0 +1,75 @@
+package request
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultArticlesLimit = 20
+	maxArticlesLimit     = 100
+)
+
+// PageParams selects a page of a list
+type PageParams struct {
+	Page  int `query:"page"`
+	Limit int `query:"limit"`
+}
+
+type ArticlesParams struct {
+	Source string `query:"source"`
+	Tag    string `query:"tag"`
+	PageParams
+}
+
+type OnchainArticlesParams struct {
+	//bech32 address of the publisher
+	Publisher string `query:"publisher"`
+	PageParams
+}
+
+func NewArticlesParams(ctx echo.Context) (*ArticlesParams, error) {
+	params := &ArticlesParams{}
+	if err := ctx.Bind(params); err != nil {
+		return nil, err
+	}
+
+	params.Tag = strings.ToLower(strings.TrimSpace(params.Tag))
+	params.setDefaults(defaultArticlesLimit, maxArticlesLimit)
+
+	return params, nil
+}
+
+func NewOnchainArticlesParams(ctx echo.Context) (*OnchainArticlesParams, error) {
+	params := &OnchainArticlesParams{}
+	if err := ctx.Bind(params); err != nil {
+		return nil, err
+	}
+
+	params.Publisher = strings.TrimSpace(params.Publisher)
+	params.setDefaults(defaultArticlesLimit, maxArticlesLimit)
+
+	return params, nil
+}
+
+func (p *PageParams) setDefaults(limit, maxLimit int) {
+	if p.Page == 0 {
+		p.Page = 1
+	}
+
+	if p.Limit <= 0 {
+		p.Limit = limit
+	} else if p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+}
+
+func (p *PageParams) Validate() error {
+	if p.Page < 1 {
+		return fmt.Errorf("page must be at least 1")
+	}
+
+	return nil
+}
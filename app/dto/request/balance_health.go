@@ -1,11 +1,24 @@
 package request
 
-import "github.com/labstack/echo/v4"
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"cosmossdk.io/math"
+	"github.com/labstack/echo/v4"
+)
+
+// maxBalanceAddresses bounds the number of balances fetched from the blockchain nodes by a single request
+const maxBalanceAddresses = 50
+
+var groupNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
 
 type AddressBalanceParams struct {
-	Address   string `json:"address"`
-	MinAmount int64  `json:"min_amount"`
-	Denom     string `json:"denom"`
+	Address string `json:"address"`
+	//a number or a string, in display units when the denom is a display denom of the chain registry (e.g. bze)
+	MinAmount json.Number `json:"min_amount"`
+	Denom     string      `json:"denom"`
 }
 
 type BalanceHealthParams struct {
@@ -18,5 +31,34 @@ func NewBalanceHealthParams(ctx echo.Context) (*BalanceHealthParams, error) {
 		return nil, err
 	}
 
+	if len(params.Addresses) > maxBalanceAddresses {
+		return nil, fmt.Errorf("too many addresses, max: %d", maxBalanceAddresses)
+	}
+
 	return params, nil
 }
+
+func (p *BalanceHealthParams) Validate() error {
+	for i, a := range p.Addresses {
+		if a.Address == "" || a.Denom == "" {
+			return fmt.Errorf("addresses[%d]: address and denom are required", i)
+		}
+
+		amount, err := math.LegacyNewDecFromStr(a.MinAmount.String())
+		if err != nil || amount.IsNegative() {
+			return fmt.Errorf("addresses[%d]: min_amount must be a number of at least 0", i)
+		}
+	}
+
+	return nil
+}
+
+// BalanceGroupName validates the name of a balance watch group given in the path
+func BalanceGroupName(ctx echo.Context) (string, error) {
+	name := ctx.Param("group")
+	if !groupNameRegexp.MatchString(name) {
+		return "", fmt.Errorf("the group name must have up to 64 lowercase letters, digits, - and _")
+	}
+
+	return name, nil
+}
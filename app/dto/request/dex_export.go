@@ -0,0 +1,103 @@
+package request
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+)
+
+type DexExportTrades struct {
+	MarketId  string `query:"market_id"` // ubze/uvdl
+	TickerId  string `query:"ticker_id"` // ubze_uvdl
+	Format    string `query:"format"`
+	StartTime int64  `query:"start_time"`
+	EndTime   int64  `query:"end_time"`
+}
+
+func NewDexExportTrades(ctx echo.Context) (*DexExportTrades, error) {
+	params := &DexExportTrades{}
+	if err := ctx.Bind(params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+func (e *DexExportTrades) Validate() error {
+	return validateExport(&e.Format, e.MarketId, e.TickerId, e.StartTime, e.EndTime)
+}
+
+func (e *DexExportTrades) MustGetMarketId() string {
+	return exportMarketId(e.MarketId, e.TickerId)
+}
+
+type DexExportCandles struct {
+	MarketId  string `query:"market_id"` // ubze/uvdl
+	TickerId  string `query:"ticker_id"` // ubze_uvdl
+	Minutes   int    `query:"minutes"`
+	Format    string `query:"format"`
+	StartTime int64  `query:"start_time"`
+	EndTime   int64  `query:"end_time"`
+}
+
+func NewDexExportCandles(ctx echo.Context) (*DexExportCandles, error) {
+	params := &DexExportCandles{}
+	if err := ctx.Bind(params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+func (e *DexExportCandles) Validate() error {
+	allIntervals := []int{intervalFiveMinutes, intervalQuarterHour, intervalHour, intervalFourHours, intervalDay}
+	if !slices.Contains(allIntervals, e.Minutes) {
+		return fmt.Errorf("invalid minutes. expected: %d, %d, %d, %d, %d", intervalFiveMinutes, intervalQuarterHour, intervalHour, intervalFourHours, intervalDay)
+	}
+
+	return validateExport(&e.Format, e.MarketId, e.TickerId, e.StartTime, e.EndTime)
+}
+
+func (e *DexExportCandles) MustGetMarketId() string {
+	return exportMarketId(e.MarketId, e.TickerId)
+}
+
+// validateExport checks the params shared by the exports, defaulting the format to csv
+func validateExport(format *string, marketId, tickerId string, startTime, endTime int64) error {
+	if *format == "" {
+		*format = exportFormatCSV
+	}
+
+	if *format != exportFormatCSV && *format != exportFormatNDJSON {
+		return fmt.Errorf("invalid format. expected: %s, %s", exportFormatCSV, exportFormatNDJSON)
+	}
+
+	if startTime < 0 || endTime < 0 {
+		return fmt.Errorf("start_time and end_time can not be negative")
+	}
+
+	if startTime > 0 && endTime > 0 && startTime >= endTime {
+		return fmt.Errorf("start_time must be before end_time")
+	}
+
+	if len(marketId) > 1 || len(tickerId) > 1 {
+		return nil
+	}
+
+	return fmt.Errorf("please provide market_id or ticker_id")
+}
+
+func exportMarketId(marketId, tickerId string) string {
+	if len(marketId) > 0 {
+		return marketId
+	}
+
+	return strings.ReplaceAll(tickerId, "_", "/")
+}
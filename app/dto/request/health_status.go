@@ -0,0 +1,29 @@
+package request
+
+import "github.com/labstack/echo/v4"
+
+const (
+	defaultHealthEventsLimit = 50
+	maxHealthEventsLimit     = 500
+)
+
+type HealthStatusParams struct {
+	//only the history of this check, e.g. market:ubze/uvdl
+	Check string `query:"check"`
+	Limit int    `query:"limit"`
+}
+
+func NewHealthStatusParams(ctx echo.Context) (*HealthStatusParams, error) {
+	params := &HealthStatusParams{}
+	if err := ctx.Bind(params); err != nil {
+		return nil, err
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = defaultHealthEventsLimit
+	} else if params.Limit > maxHealthEventsLimit {
+		params.Limit = maxHealthEventsLimit
+	}
+
+	return params, nil
+}
@@ -0,0 +1,65 @@
+package request
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const defaultPriceCurrency = "usd"
+
+type PricesParams struct {
+	//separated by comma, e.g. usd,eur,btc. Default: all the configured currencies
+	VsCurrencies string `query:"vs_currencies"`
+}
+
+func NewPricesParams(ctx echo.Context) (*PricesParams, error) {
+	params := &PricesParams{}
+	if err := ctx.Bind(params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// Currencies returns the requested currencies, lower case
+func (p *PricesParams) Currencies() []string {
+	var result []string
+	for _, c := range strings.Split(strings.ToLower(p.VsCurrencies), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
+
+type PriceHistoryParams struct {
+	Denom    string `query:"denom"`
+	Currency string `query:"currency"`
+	HistoryRange
+}
+
+func NewPriceHistoryParams(ctx echo.Context) (*PriceHistoryParams, error) {
+	params := &PriceHistoryParams{}
+	if err := ctx.Bind(params); err != nil {
+		return nil, err
+	}
+
+	params.Currency = strings.ToLower(params.Currency)
+	if params.Currency == "" {
+		params.Currency = defaultPriceCurrency
+	}
+	params.setDefaults()
+
+	return params, nil
+}
+
+func (p *PriceHistoryParams) Validate() error {
+	if p.Denom == "" {
+		return fmt.Errorf("denom is required")
+	}
+
+	return p.HistoryRange.Validate()
+}
@@ -7,6 +7,9 @@ import (
 
 const (
 	defaultDenom = chain_registry.DenomUbze
+
+	SupplyFormatJson = "json"
+	SupplyFormatText = "text"
 )
 
 type SupplyParams struct {
@@ -25,3 +28,43 @@ func NewSupplyParams(ctx echo.Context) (*SupplyParams, error) {
 
 	return mhr, nil
 }
+
+type CirculatingSupplyParams struct {
+	Denom string `query:"denom"`
+	//return the excluded amounts together with the circulating supply
+	Verbose bool `query:"verbose"`
+}
+
+func NewCirculatingSupplyParams(ctx echo.Context) (*CirculatingSupplyParams, error) {
+	params := &CirculatingSupplyParams{}
+	if err := ctx.Bind(params); err != nil {
+		return nil, err
+	}
+
+	if params.Denom == "" {
+		params.Denom = defaultDenom
+	}
+
+	return params, nil
+}
+
+type SupplyListParams struct {
+	Format string `query:"format"`
+}
+
+func NewSupplyListParams(ctx echo.Context) (*SupplyListParams, error) {
+	params := &SupplyListParams{}
+	if err := ctx.Bind(params); err != nil {
+		return nil, err
+	}
+
+	if params.Format != SupplyFormatText {
+		params.Format = SupplyFormatJson
+	}
+
+	return params, nil
+}
+
+func (p *SupplyListParams) IsTextFormat() bool {
+	return p.Format == SupplyFormatText
+}
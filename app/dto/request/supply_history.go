@@ -0,0 +1,122 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	resolutionHour = "hour"
+	resolutionDay  = "day"
+	resolutionWeek = "week"
+
+	defaultHistoryDays = 30
+	maxHistoryPoints   = 1000
+)
+
+var historyResolutions = map[string]time.Duration{
+	resolutionHour: time.Hour,
+	resolutionDay:  time.Hour * 24,
+	resolutionWeek: time.Hour * 24 * 7,
+}
+
+// HistoryRange is the time range of the history endpoints, split in points of one resolution
+type HistoryRange struct {
+	//timestamp in milliseconds, 2006-01-02 or RFC 3339
+	From       string `query:"from"`
+	To         string `query:"to"`
+	Resolution string `query:"resolution"`
+
+	from time.Time
+	to   time.Time
+	step time.Duration
+}
+
+type SupplyHistoryParams struct {
+	Denom string `query:"denom"`
+	HistoryRange
+}
+
+func NewSupplyHistoryParams(ctx echo.Context) (*SupplyHistoryParams, error) {
+	params := &SupplyHistoryParams{}
+	if err := ctx.Bind(params); err != nil {
+		return nil, err
+	}
+
+	if params.Denom == "" {
+		params.Denom = defaultDenom
+	}
+	params.setDefaults()
+
+	return params, nil
+}
+
+func (r *HistoryRange) setDefaults() {
+	if r.Resolution == "" {
+		r.Resolution = resolutionDay
+	}
+}
+
+// Validate checks the params and resolves the time range: to defaults to now and from to 30 days before to
+func (r *HistoryRange) Validate() (err error) {
+	step, ok := historyResolutions[r.Resolution]
+	if !ok {
+		return fmt.Errorf("invalid resolution. expected: %s, %s, %s", resolutionHour, resolutionDay, resolutionWeek)
+	}
+	r.step = step
+
+	r.to = time.Now().UTC()
+	if r.To != "" {
+		if r.to, err = parseTimeParam(r.To); err != nil {
+			return fmt.Errorf("invalid to: %w", err)
+		}
+	}
+
+	r.from = r.to.AddDate(0, 0, -defaultHistoryDays)
+	if r.From != "" {
+		if r.from, err = parseTimeParam(r.From); err != nil {
+			return fmt.Errorf("invalid from: %w", err)
+		}
+	}
+
+	if !r.from.Before(r.to) {
+		return fmt.Errorf("from must be before to")
+	}
+
+	if r.to.Sub(r.from)/r.step > maxHistoryPoints {
+		return fmt.Errorf("the range can have at most %d points of one %s, use a coarser resolution", maxHistoryPoints, r.Resolution)
+	}
+
+	return nil
+}
+
+// Range returns the time range resolved by Validate
+func (r *HistoryRange) Range() (from, to time.Time) {
+	return r.from, r.to
+}
+
+// Step returns the duration of one point of the resolution
+func (r *HistoryRange) Step() time.Duration {
+	return r.step
+}
+
+// parseTimeParam parses a timestamp in milliseconds, a date (2006-01-02) or an RFC 3339 time
+func parseTimeParam(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a timestamp in milliseconds, 2006-01-02 or RFC 3339")
+	}
+
+	return t.UTC(), nil
+}
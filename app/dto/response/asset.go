@@ -0,0 +1,23 @@
+package response
+
+// Asset is the metadata of a denom of the chain registry or of a market
+type Asset struct {
+	Denom   string `json:"denom"`
+	Name    string `json:"name"`
+	Symbol  string `json:"symbol"`
+	Display string `json:"display"`
+	//exponent of the display denom
+	Exponent int `json:"exponent"`
+	//registry, ibc or default
+	Source string `json:"source"`
+	//only for the IBC denoms resolved through their trace
+	Ibc *AssetIbcTrace `json:"ibc,omitempty"`
+}
+
+// AssetIbcTrace is the origin of an IBC denom
+type AssetIbcTrace struct {
+	//port/channel pairs followed by the base denom, e.g. transfer/channel-0/uosmo
+	Path      string `json:"path"`
+	BaseDenom string `json:"base_denom"`
+	ChainName string `json:"chain_name,omitempty"`
+}
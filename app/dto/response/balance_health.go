@@ -1,6 +1,49 @@
 package response
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+)
+
 type BalanceHealthResponse struct {
-	IsHealthy bool   `json:"is_healthy"`
-	Errors    string `json:"errors"`
+	IsHealthy bool                     `json:"is_healthy"`
+	Addresses []dto.AddressHealthCheck `json:"addresses"`
+	//the errors of the addresses joined with ";", kept for the existing monitors
+	Errors string `json:"errors"`
+}
+
+// NewBalanceHealthResponse sums up the balance checks in a single result, healthy when all the addresses are
+func NewBalanceHealthResponse(checks []dto.AddressHealthCheck) BalanceHealthResponse {
+	result := BalanceHealthResponse{
+		IsHealthy: true,
+		Addresses: checks,
+	}
+
+	var errs []string
+	for _, cr := range checks {
+		if cr.IsHealthy {
+			continue
+		}
+		result.IsHealthy = false
+		errs = append(errs, fmt.Sprintf("[%s]: [%s]", cr.Address, cr.Error))
+	}
+	result.Errors = strings.Join(errs, "; ")
+
+	return result
+}
+
+// BalanceGroup is a named list of balances checked together
+type BalanceGroup struct {
+	Name string `json:"name"`
+	//config groups are read only, api groups are managed through the admin API
+	Source    string                `json:"source"`
+	Addresses []BalanceGroupAddress `json:"addresses"`
+}
+
+type BalanceGroupAddress struct {
+	Address   string `json:"address"`
+	Denom     string `json:"denom"`
+	MinAmount string `json:"min_amount"`
 }
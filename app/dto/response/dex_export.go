@@ -0,0 +1,57 @@
+package response
+
+import (
+	"strconv"
+)
+
+// ExportRow is one record of an export. Its JSON tags match Columns, so it is written as is in NDJSON.
+type ExportRow interface {
+	Columns() []string
+	Values() []string
+}
+
+var exportTradeColumns = []string{"order_id", "market_id", "order_type", "price", "base_volume", "quote_volume", "executed_at", "maker", "taker"}
+
+type ExportTrade struct {
+	OrderId     int    `json:"order_id"`
+	MarketId    string `json:"market_id"`
+	OrderType   string `json:"order_type"`
+	Price       string `json:"price"`
+	BaseVolume  string `json:"base_volume"`
+	QuoteVolume string `json:"quote_volume"`
+	ExecutedAt  string `json:"executed_at"`
+	Maker       string `json:"maker"`
+	Taker       string `json:"taker"`
+}
+
+func (t *ExportTrade) Columns() []string {
+	return exportTradeColumns
+}
+
+func (t *ExportTrade) Values() []string {
+	return []string{strconv.Itoa(t.OrderId), t.MarketId, t.OrderType, t.Price, t.BaseVolume, t.QuoteVolume, t.ExecutedAt, t.Maker, t.Taker}
+}
+
+var exportCandleColumns = []string{"market_id", "minutes", "start_at", "end_at", "open", "high", "low", "close", "average", "base_volume", "quote_volume"}
+
+type ExportCandle struct {
+	MarketId    string `json:"market_id"`
+	Minutes     int    `json:"minutes"`
+	StartAt     string `json:"start_at"`
+	EndAt       string `json:"end_at"`
+	Open        string `json:"open"`
+	High        string `json:"high"`
+	Low         string `json:"low"`
+	Close       string `json:"close"`
+	Average     string `json:"average"`
+	BaseVolume  string `json:"base_volume"`
+	QuoteVolume string `json:"quote_volume"`
+}
+
+func (c *ExportCandle) Columns() []string {
+	return exportCandleColumns
+}
+
+func (c *ExportCandle) Values() []string {
+	return []string{c.MarketId, strconv.Itoa(c.Minutes), c.StartAt, c.EndAt, c.Open, c.High, c.Low, c.Close, c.Average, c.BaseVolume, c.QuoteVolume}
+}
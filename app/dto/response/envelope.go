@@ -0,0 +1,21 @@
+package response
+
+// Envelope wraps all the /api/v2 responses. On success Error is null, on failure Data is null.
+type Envelope struct {
+	Data      any            `json:"data"`
+	Error     *EnvelopeError `json:"error"`
+	RequestId string         `json:"request_id"`
+}
+
+type EnvelopeError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func NewEnvelope(data any, requestId string) Envelope {
+	return Envelope{Data: data, RequestId: requestId}
+}
+
+func NewErrorEnvelope(code, message, requestId string) Envelope {
+	return Envelope{Error: &EnvelopeError{Code: code, Message: message}, RequestId: requestId}
+}
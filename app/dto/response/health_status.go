@@ -0,0 +1,26 @@
+package response
+
+// HealthStatus is the state of the health checks run by the listener together with their latest state changes
+type HealthStatus struct {
+	//false when at least one check is unhealthy
+	IsHealthy bool                `json:"is_healthy"`
+	Checks    []HealthCheckStatus `json:"checks"`
+	History   []HealthEvent       `json:"history"`
+}
+
+type HealthCheckStatus struct {
+	Check string `json:"check"`
+	//healthy or unhealthy
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	//when the check got the status (RFC 3339)
+	Since string `json:"since"`
+}
+
+type HealthEvent struct {
+	Check string `json:"check"`
+	//healthy (first run), unhealthy or recovered
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Time    string `json:"time"`
+}
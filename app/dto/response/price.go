@@ -0,0 +1,11 @@
+package response
+
+// PricePoint is the price at the end of a period
+type PricePoint struct {
+	//start of the period
+	Time string `json:"time"`
+	//when the price was read, the last snapshot of the period
+	TakenAt string  `json:"taken_at"`
+	Price   float64 `json:"price"`
+	Source  string  `json:"source"`
+}
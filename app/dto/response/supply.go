@@ -0,0 +1,45 @@
+package response
+
+type Supply struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+// SupplyPoint is the supply at the end of a period, in display units (e.g. BZE)
+type SupplyPoint struct {
+	//start of the period
+	Time string `json:"time"`
+	//when the supply was read, the last snapshot of the period
+	TakenAt       string `json:"taken_at"`
+	Total         string `json:"total"`
+	CommunityPool string `json:"community_pool"`
+	Circulating   string `json:"circulating"`
+}
+
+// CirculatingSupply is the total supply minus every excluded amount, in display units (e.g. BZE)
+type CirculatingSupply struct {
+	Denom       string           `json:"denom"`
+	Total       string           `json:"total"`
+	Circulating string           `json:"circulating"`
+	Excluded    []ExcludedSupply `json:"excluded"`
+}
+
+// ExcludedSupply is an amount left out of the circulating supply
+type ExcludedSupply struct {
+	Label string `json:"label"`
+	//community_pool, module or address
+	Kind string `json:"kind"`
+	//the account holding the amount, empty for the community pool
+	Address string `json:"address,omitempty"`
+	//the module name, only for module accounts
+	Module string `json:"module,omitempty"`
+	Amount string `json:"amount"`
+}
+
+// SupplyListItem is the supply of a chain registry asset, in display units (e.g. BZE)
+type SupplyListItem struct {
+	Denom       string `json:"denom"`
+	Symbol      string `json:"symbol"`
+	Total       string `json:"total"`
+	Circulating string `json:"circulating"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// Article is an article read from one of the configured feeds
+type Article struct {
+	ID int `db:"id"`
+	//the link without query and fragment, the same article read from two feeds is saved once
+	UrlKey      string `db:"url_key"`
+	Source      string `db:"source"`
+	Title       string `db:"title"`
+	Url         string `db:"url"`
+	PictureUrl  string `db:"picture_url"`
+	Description string `db:"description"`
+	AuthorName  string `db:"author_name"`
+	//lower case tags wrapped in commas (e.g. ",bze,defi,") so a tag can be matched with LIKE
+	Tags        string    `db:"tags"`
+	PublishedAt time.Time `db:"published_at"`
+	FetchedAt   time.Time `db:"fetched_at"`
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// BalanceWatch is an address of a balance watch group saved through the admin API
+type BalanceWatch struct {
+	GroupName string    `db:"group_name"`
+	Address   string    `db:"address"`
+	Denom     string    `db:"denom"`
+	MinAmount string    `db:"min_amount"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// CointrunkArticle is an article published on chain through the cointrunk module
+type CointrunkArticle struct {
+	//the article id given by the chain
+	ID        uint64    `db:"id"`
+	Title     string    `db:"title"`
+	Url       string    `db:"url"`
+	Picture   string    `db:"picture"`
+	Publisher string    `db:"publisher"`
+	Paid      bool      `db:"paid"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// CointrunkArticleView is the article together with the name of its publisher, empty for anonymous (paid) articles
+type CointrunkArticleView struct {
+	CointrunkArticle
+	PublisherName string `db:"publisher_name"`
+}
+
+// CointrunkPublisher is an address accepted by the governance to publish articles for free
+type CointrunkPublisher struct {
+	Address       string    `db:"address"`
+	Name          string    `db:"name"`
+	Active        bool      `db:"active"`
+	ArticlesCount uint32    `db:"articles_count"`
+	Respect       int64     `db:"respect"`
+	CreatedAt     time.Time `db:"created_at"`
+}
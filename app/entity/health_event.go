@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// HealthEvent is a state change of a health check, e.g. a market that became unhealthy
+type HealthEvent struct {
+	ID        int       `db:"id"`
+	CheckName string    `db:"check_name"`
+	Status    string    `db:"status"`
+	Message   string    `db:"message"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -1,9 +1,6 @@
 package entity
 
-import (
-	"database/sql"
-	"time"
-)
+import "time"
 
 type Market struct {
 	ID        int       `db:"id"`
@@ -13,9 +10,3 @@ type Market struct {
 	CreatedBy string    `db:"created_by"`
 	CreatedAt time.Time `db:"i_created_at"`
 }
-
-type MarketWithLastPrice struct {
-	Market
-
-	LastPrice sql.NullString `db:"last_price"`
-}
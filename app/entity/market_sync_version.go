@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+// MarketSyncVersion is increased every time the data of a market is synced
+type MarketSyncVersion struct {
+	MarketID  string    `db:"market_id"`
+	Version   uint64    `db:"version"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// MarketTicker holds the precomputed ticker of a market. The 24h stats are built from the 5 minutes intervals.
+type MarketTicker struct {
+	MarketID    string         `db:"market_id"`
+	LastPrice   sql.NullString `db:"last_price"`
+	Bid         sql.NullString `db:"bid"`
+	Ask         sql.NullString `db:"ask"`
+	OpenPrice   sql.NullString `db:"open_price"`
+	High        sql.NullString `db:"high"`
+	Low         sql.NullString `db:"low"`
+	BaseVolume  sql.NullString `db:"base_volume"`
+	QuoteVolume sql.NullString `db:"quote_volume"`
+	UpdatedAt   sql.NullTime   `db:"updated_at"`
+}
+
+// MarketWithTicker is a market and its ticker, which is empty until the market is synced
+type MarketWithTicker struct {
+	MarketTicker
+
+	Base  string `db:"base"`
+	Quote string `db:"quote"`
+}
+
+// TickerStats are the 24h stats of a market
+type TickerStats struct {
+	MarketID    string
+	LastPrice   sql.NullString
+	OpenPrice   sql.NullString
+	High        string
+	Low         string
+	BaseVolume  string
+	QuoteVolume string
+	UpdatedAt   time.Time
+}
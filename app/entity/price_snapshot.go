@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// PriceSnapshot is the price of a coin in a currency at a point in time
+type PriceSnapshot struct {
+	ID       int     `db:"id"`
+	Denom    string  `db:"denom"`
+	Currency string  `db:"currency"`
+	Price    float64 `db:"price"`
+	//the price source, e.g. coingecko
+	Source  string    `db:"source"`
+	TakenAt time.Time `db:"taken_at"`
+}
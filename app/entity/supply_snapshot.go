@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// SupplySnapshot is the supply of a denom at a point in time. The amounts are in base units (e.g. ubze).
+type SupplySnapshot struct {
+	ID            int       `db:"id"`
+	Denom         string    `db:"denom"`
+	Total         string    `db:"total"`
+	CommunityPool string    `db:"community_pool"`
+	Circulating   string    `db:"circulating"`
+	TakenAt       time.Time `db:"taken_at"`
+}
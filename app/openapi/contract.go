@@ -0,0 +1,81 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// undocumented are the routes that are not part of the API contract
+var undocumented = map[string]bool{
+	"/metrics":     true,
+	DocsPath:       true,
+	DocsAssetsPath: true,
+}
+
+// CheckRoutes compares the routes registered on the server with the spec. It returns an error listing the routes
+// missing from the spec and the spec operations that have no route.
+func CheckRoutes(routes []*echo.Route) error {
+	doc, err := Build()
+	if err != nil {
+		return err
+	}
+
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item.operations() {
+			documented[routeKey(method, path)] = true
+		}
+	}
+
+	var problems []string
+	registered := make(map[string]bool)
+	for _, r := range routes {
+		if undocumented[r.Path] {
+			continue
+		}
+
+		key := routeKey(r.Method, specPath(r.Path))
+		registered[key] = true
+		if !documented[key] {
+			problems = append(problems, fmt.Sprintf("route %s is missing from the OpenAPI spec", key))
+		}
+	}
+
+	for key := range documented {
+		if !registered[key] {
+			problems = append(problems, fmt.Sprintf("OpenAPI spec documents %s but no such route is registered", key))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+	errs := make([]error, 0, len(problems))
+	for _, p := range problems {
+		errs = append(errs, errors.New(p))
+	}
+
+	return errors.Join(errs...)
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// specPath converts the :params of an echo route to the {params} of the spec
+func specPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
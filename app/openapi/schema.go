@@ -0,0 +1,247 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaBuilder generates the schemas from the json tags of the DTOs. Named structs are added to the components
+// and referenced. Types with a custom MarshalJSON can't be inspected, so they need an entry in overrides.
+type schemaBuilder struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type
+	overrides  map[reflect.Type]func(b *schemaBuilder) (*Schema, error)
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: make(map[string]*Schema),
+		types:      make(map[string]reflect.Type),
+		overrides:  make(map[reflect.Type]func(b *schemaBuilder) (*Schema, error)),
+	}
+}
+
+// schemaOf returns the schema of the JSON produced by encoding/json for v
+func (b *schemaBuilder) schemaOf(v any) (*Schema, error) {
+	return b.schema(reflect.TypeOf(v))
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	}
+
+	if t.Kind() == reflect.Struct && t.Name() != "" {
+		return b.component(t)
+	}
+
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return nil, fmt.Errorf("type %s has a custom JSON encoding and no schema override", t)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}, nil
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}, nil
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		values, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return b.object(t)
+	case reflect.Interface:
+		//any value
+		return &Schema{}, nil
+	default:
+		return nil, fmt.Errorf("type %s can not be described", t)
+	}
+}
+
+// component adds the named struct to the components, if needed, and returns a reference to it
+func (b *schemaBuilder) component(t reflect.Type) (*Schema, error) {
+	ref := &Schema{Ref: "#/components/schemas/" + t.Name()}
+	if existing, ok := b.types[t.Name()]; ok {
+		if existing != t {
+			return nil, fmt.Errorf("types %s and %s have the same name", existing, t)
+		}
+
+		return ref, nil
+	}
+
+	//registered before building it, so recursive types reference it instead of looping
+	b.types[t.Name()] = t
+
+	var s *Schema
+	var err error
+	if override, ok := b.overrides[t]; ok {
+		s, err = override(b)
+	} else if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		err = fmt.Errorf("type %s has a custom JSON encoding and no schema override", t)
+	} else {
+		s, err = b.object(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b.components[t.Name()] = s
+
+	return ref, nil
+}
+
+// object describes the exported fields of the struct the same way encoding/json encodes them
+func (b *schemaBuilder) object(t reflect.Type) (*Schema, error) {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omitEmpty, skip := jsonField(f)
+		if skip {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		//embedded structs without a json name are flattened into the parent
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded, err := b.object(ft)
+			if err != nil {
+				return nil, err
+			}
+			for n, p := range embedded.Properties {
+				s.Properties[n] = p
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := b.schema(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+
+		s.Properties[name] = prop
+		if !omitEmpty {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s, nil
+}
+
+// queryParameters describes the fields of a request struct bound by echo from the query string
+func (b *schemaBuilder) queryParameters(v any, required ...string) ([]Parameter, error) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var result []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("query")
+		//echo binds the fields of the embedded structs too
+		if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			embedded, err := b.queryParameters(reflect.New(f.Type).Elem().Interface())
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, embedded...)
+
+			continue
+		}
+
+		if name == "" || name == "-" {
+			continue
+		}
+
+		s, err := b.schema(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+
+		result = append(result, Parameter{
+			Name:        name,
+			In:          "query",
+			Description: paramDescriptions[name],
+			Required:    slices.Contains(required, name),
+			Schema:      s,
+		})
+	}
+
+	for _, r := range required {
+		if !hasParameter(result, r) {
+			return nil, fmt.Errorf("required parameter %s is not a query field of %s", r, t.Name())
+		}
+	}
+
+	return result, nil
+}
+
+func jsonField(f reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return parts[0], omitEmpty, false
+}
+
+func hasParameter(params []Parameter, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
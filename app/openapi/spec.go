@@ -0,0 +1,567 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+)
+
+const (
+	SpecPath = "/api/openapi.json"
+	DocsPath = "/api/docs"
+	//DocsAssetsPath serves the Swagger UI files embedded in the binary
+	DocsAssetsPath = "/api/docs/assets/*"
+
+	version = "1.0.0"
+
+	adminSecurity = "adminApiKey"
+)
+
+var paramDescriptions = map[string]string{
+	"denom":         "denomination of the coin. Default: ubze",
+	"market_id":     "market id, e.g. ubze/uvdl. Required when ticker_id is missing",
+	"ticker_id":     "ticker id, e.g. ubze_uvdl. Can be used instead of market_id",
+	"format":        "response format",
+	"minutes":       "number of minutes",
+	"limit":         "max number of results",
+	"depth":         "order book depth. Default: 10",
+	"type":          "order type. Options: buy, sell",
+	"start_time":    "only trades executed after this time (timestamp in milliseconds)",
+	"end_time":      "only trades executed before this time (timestamp in milliseconds)",
+	"address":       "only trades made by this address",
+	"from":          "start of the range: timestamp in milliseconds, 2006-01-02 or RFC 3339. Default: 30 days before to",
+	"to":            "end of the range (excluded): timestamp in milliseconds, 2006-01-02 or RFC 3339. Default: now",
+	"resolution":    "length of a point: hour, day or week. Default: day",
+	"verbose":       "also return the amounts excluded from the total supply",
+	"vs_currencies": "currencies separated by comma, e.g. usd,eur,btc. Default: all the configured currencies",
+	"currency":      "currency of the price. Default: usd",
+	"source":        "only the articles of this feed",
+	"tag":           "only the articles with this tag",
+	"page":          "page number, starting at 1. Default: 1",
+	"publisher":     "only the articles of this publisher address",
+	"check":         "only the history of this check, e.g. market:ubze/uvdl, aggregator, nodes or balance:bze1...:ubze",
+	"group":         "name of the balance group: up to 64 lowercase letters, digits, - and _",
+}
+
+// exportParamDescriptions replace paramDescriptions on the export endpoints
+var exportParamDescriptions = map[string]string{
+	"start_time": "only rows from this time on (timestamp in milliseconds). Default: the first row",
+	"end_time":   "only rows before this time (timestamp in milliseconds). Default: now",
+	"format":     "export format. Default: csv",
+}
+
+// endpoint describes one route of the API. The parameters and responses are generated from the given DTOs.
+type endpoint struct {
+	method      string
+	path        string
+	tag         string
+	summary     string
+	description string
+	query       any
+	required    []string
+	formats     []any
+	body        any
+	//plain text response instead of JSON, a single entry of responses is the JSON answered depending on the query
+	text bool
+	//JSON responses. When more than one is given the response is one of them, depending on the format param
+	responses []any
+	//the status codes returning an error: request.ErrResponse or, for the envelope endpoints, response.Envelope
+	errors []int
+	//the JSON response is also answered with 503 when a dependency is down (readiness probes)
+	unavailable bool
+	//the endpoint needs the ADMIN_API_KEY bearer token
+	admin bool
+	//the responses are wrapped in response.Envelope (/api/v2)
+	envelope bool
+	//the responses carry an ETag and can be revalidated with If-None-Match
+	cached bool
+	//streamed CSV or NDJSON rows of the given DTO instead of a JSON response
+	export any
+}
+
+var endpoints = []endpoint{
+	{
+		method:      http.MethodGet,
+		path:        "/api/supply",
+		tag:         "supply",
+		summary:     "Supply of every chain registry asset",
+		description: "JSON by default. With `format=text` returns a header line and one `denom symbol total circulating` line per asset.",
+		query:       request.SupplyListParams{},
+		formats:     []any{request.SupplyFormatJson, request.SupplyFormatText},
+		responses:   []any{[]response.SupplyListItem{}},
+		errors:      []int{http.StatusBadRequest, http.StatusServiceUnavailable},
+		text:        true,
+	},
+	{
+		method:  http.MethodGet,
+		path:    "/api/supply/total",
+		tag:     "supply",
+		summary: "Total supply of a coin",
+		query:   request.SupplyParams{},
+		text:    true,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/supply/circulating",
+		tag:         "supply",
+		summary:     "Circulating supply of a coin",
+		description: "Plain text by default. With `verbose=true` returns JSON with the total supply and every excluded amount, the errors are JSON too.",
+		query:       request.CirculatingSupplyParams{},
+		responses:   []any{response.CirculatingSupply{}},
+		errors:      []int{http.StatusNotFound, http.StatusServiceUnavailable},
+		text:        true,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/supply/history",
+		tag:         "supply",
+		summary:     "Supply of a coin over time",
+		description: "Returns the last snapshot of each period of the resolution, in display units. Periods without snapshots are skipped.",
+		query:       request.SupplyHistoryParams{},
+		responses:   []any{[]response.SupplyPoint{}},
+		errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/assets",
+		tag:         "assets",
+		summary:     "Metadata of the assets",
+		description: "The chain registry assets followed by the denoms of the markets missing from it. `source` is `registry`, `ibc` when the denom was resolved through its IBC trace and the asset list of the counterparty chain, or `default` when it was given `CHAIN_REGISTRY_DEFAULT_EXPONENT`.",
+		responses:   []any{[]response.Asset{}},
+		errors:      []int{http.StatusServiceUnavailable},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/articles",
+		tag:         "articles",
+		summary:     "Latest articles of the configured feeds",
+		description: "Articles of all the feeds merged, latest first. An article published in two feeds is returned once.",
+		query:       request.ArticlesParams{},
+		responses:   []any{[]dto.Article{}},
+		errors:      []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/articles/onchain",
+		tag:         "articles",
+		summary:     "Latest articles published on chain",
+		description: "Articles published through the cointrunk module, latest first. The author is the publisher name, or the address of anonymous (paid) articles.",
+		query:       request.OnchainArticlesParams{},
+		responses:   []any{[]dto.Article{}},
+		errors:      []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/articles/medium",
+		tag:         "articles",
+		summary:     "Latest 6 medium articles",
+		description: "Kept for backward compatibility, same as `/api/articles?source=medium&limit=6`.",
+		responses:   []any{[]dto.Article{}},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/prices",
+		tag:         "prices",
+		summary:     "Prices of the configured coins",
+		description: "The configured sources are asked in order until every coin has a price. `stale` flags prices older than the configured staleness.",
+		query:       request.PricesParams{},
+		responses:   []any{[]dto.CoinPrice{}},
+		errors:      []int{http.StatusUnprocessableEntity},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/prices/history",
+		tag:         "prices",
+		summary:     "Price of a coin over time",
+		description: "Returns the last saved price of each period of the resolution. Periods without prices are skipped.",
+		query:       request.PriceHistoryParams{},
+		required:    []string{"denom"},
+		responses:   []any{[]response.PricePoint{}},
+		errors:      []int{http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/health",
+		tag:         "health",
+		summary:     "Readiness",
+		description: "Probes the database and the pools of REST and gRPC nodes. Answers 503 when one of them is down, to be used by readiness probes. Not rate limited.",
+		responses:   []any{dto.Readiness{}},
+		unavailable: true,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/health/market",
+		tag:         "health",
+		summary:     "Market health",
+		description: "A market is healthy when it had trades in the last `minutes` (default: 10).",
+		query:       request.MarketHealthRequest{},
+		required:    []string{"market_id"},
+		responses:   []any{dto.MarketHealth{}},
+		errors:      []int{http.StatusBadRequest},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/health/aggregator",
+		tag:         "health",
+		summary:     "Aggregator health",
+		description: "The aggregator is healthy when it synced data in the last `minutes` (default: 10, max: 720).",
+		query:       request.AggregatorHealthRequest{},
+		responses:   []any{dto.AggregatorHealth{}},
+		errors:      []int{http.StatusBadRequest},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/health/nodes",
+		tag:         "health",
+		summary:     "Blockchain nodes health",
+		description: "The nodes are healthy when all of them respond, none is catching up or behind the highest one by more than `HEALTH_NODES_MAX_HEIGHT_DIFF` blocks and, when `HEALTH_NODES_MAX_LATENCY_MILLIS` is set, none answers slower. `errors` joins the errors of the nodes, use `nodes` instead.",
+		responses:   []any{dto.NodesHealth{}},
+	},
+	{
+		method:      http.MethodPost,
+		path:        "/api/health/balances",
+		tag:         "health",
+		summary:     "Balances health",
+		description: "Checks that each address holds at least `min_amount` of `denom`. `min_amount` is a number or a string, in display units when `denom` is a display denom of the chain registry (e.g. `bze`). `errors` joins the errors of the addresses, use `addresses` instead.",
+		body:        request.BalanceHealthParams{},
+		responses:   []any{response.BalanceHealthResponse{}},
+		errors:      []int{http.StatusBadRequest},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/health/balances/{group}",
+		tag:         "health",
+		summary:     "Balance group health",
+		description: "Checks the balances of a group defined by `HEALTH_BALANCE_GROUPS` or saved through the admin API.",
+		responses:   []any{response.BalanceHealthResponse{}},
+		errors:      []int{http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/health/status",
+		tag:         "health",
+		summary:     "Alerting state and history",
+		description: "The current state of each check run by `sync listener` and its latest state changes (healthy, unhealthy, recovered), newest first.",
+		query:       request.HealthStatusParams{},
+		responses:   []any{response.HealthStatus{}},
+		errors:      []int{http.StatusBadRequest},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/dex/tickers",
+		tag:         "dex",
+		summary:     "Tickers of all markets",
+		description: "Use `format=coingecko` for the coingecko format.",
+		query:       request.TickersParams{},
+		formats:     []any{"coingecko"},
+		responses:   []any{[]response.Ticker{}, []response.CoingeckoTicker{}},
+		errors:      []int{http.StatusBadRequest, http.StatusInternalServerError},
+		cached:      true,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/dex/orders",
+		tag:         "dex",
+		summary:     "Order book of a market",
+		description: "Use `format=coingecko` for the coingecko format.",
+		query:       request.OrdersParams{},
+		formats:     []any{"coingecko"},
+		responses:   []any{response.Orders{}, response.CoingeckoOrders{}},
+		errors:      []int{http.StatusBadRequest},
+		cached:      true,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/dex/history",
+		tag:         "dex",
+		summary:     "Trades executed on a market",
+		description: "Use `format=coingecko` for the coingecko format.",
+		query:       request.HistoryParams{},
+		formats:     []any{"coingecko"},
+		responses:   []any{[]response.HistoryTrade{}, response.CoingeckoHistory{}},
+		errors:      []int{http.StatusBadRequest},
+		cached:      true,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/dex/intervals",
+		tag:         "dex",
+		summary:     "Price intervals (candles) of a market",
+		description: "`minutes` is the length of an interval: 5, 15, 60, 240 or 1440. Use `format=tv` for the TradingView format.",
+		query:       request.DexInterval{},
+		required:    []string{"minutes"},
+		formats:     []any{"tv"},
+		responses:   []any{[]entity.MarketHistoryInterval{}, []entity.TradingViewInterval{}},
+		errors:      []int{http.StatusBadRequest},
+		cached:      true,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/dex/export/trades",
+		tag:         "dex",
+		summary:     "Export all trades of a market",
+		description: "Streams the trades executed in the time range, oldest first. Times are RFC 3339 in UTC.",
+		query:       request.DexExportTrades{},
+		formats:     []any{"csv", "ndjson"},
+		export:      response.ExportTrade{},
+		errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity},
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/api/dex/export/candles",
+		tag:         "dex",
+		summary:     "Export all candles of a market",
+		description: "Streams the intervals started in the time range, oldest first. `minutes` is the length of an interval: 5, 15, 60, 240 or 1440. Times are RFC 3339 in UTC.",
+		query:       request.DexExportCandles{},
+		required:    []string{"minutes"},
+		formats:     []any{"csv", "ndjson"},
+		export:      response.ExportCandle{},
+		errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity},
+	},
+	{
+		method:    http.MethodGet,
+		path:      SpecPath,
+		tag:       "docs",
+		summary:   "This OpenAPI specification",
+		responses: []any{map[string]any{}},
+	},
+}
+
+// schemaOverrides describes the types that have a custom JSON encoding
+var schemaOverrides = map[reflect.Type]func(b *schemaBuilder) (*Schema, error){
+	reflect.TypeOf(entity.TradingViewInterval{}): func(b *schemaBuilder) (*Schema, error) {
+		s, err := b.object(reflect.TypeOf(entity.TradingViewInterval{}))
+		if err != nil {
+			return nil, err
+		}
+
+		s.Properties["time"] = &Schema{Type: "integer", Format: "int64", Description: "start of the interval (unix timestamp)"}
+		s.Required = append(s.Required, "time")
+
+		return s, nil
+	},
+}
+
+// Build generates the OpenAPI document of the API
+func Build() (*Document, error) {
+	b := newSchemaBuilder()
+	b.overrides = schemaOverrides
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "BZE Aggregator API",
+			Description: "Blockchain data aggregated by the BZE aggregator: supply, prices, DEX markets and health checks.",
+			Version:     version,
+		},
+		Paths: make(map[string]*PathItem),
+	}
+
+	all := append([]endpoint{}, endpoints...)
+	for _, e := range v2Endpoints {
+		e.envelope = true
+		e.description = v2Description
+		all = append(all, e)
+	}
+
+	for _, e := range all {
+		op, err := buildOperation(b, e)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", e.method, e.path, err)
+		}
+
+		item, ok := doc.Paths[e.path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[e.path] = item
+		}
+
+		switch e.method {
+		case http.MethodGet:
+			item.Get = op
+		case http.MethodPost:
+			item.Post = op
+		case http.MethodPut:
+			item.Put = op
+		case http.MethodDelete:
+			item.Delete = op
+		default:
+			return nil, fmt.Errorf("%s %s: method not supported", e.method, e.path)
+		}
+	}
+
+	doc.Components.Schemas = b.components
+	doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+		adminSecurity: {Type: "http", Scheme: "bearer", Description: "the ADMIN_API_KEY"},
+	}
+
+	return doc, nil
+}
+
+// JSON returns the OpenAPI document encoded as JSON
+func JSON() ([]byte, error) {
+	doc, err := Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func buildOperation(b *schemaBuilder, e endpoint) (*Operation, error) {
+	op := &Operation{
+		Summary:     e.summary,
+		Description: e.description,
+		Tags:        []string{e.tag},
+		Responses:   make(map[string]*Response),
+	}
+
+	for _, name := range pathParameters(e.path) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        name,
+			In:          "path",
+			Description: paramDescriptions[name],
+			Required:    true,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	if e.admin {
+		op.Security = []map[string][]string{{adminSecurity: {}}}
+		op.Responses[fmt.Sprint(http.StatusUnauthorized)] = &Response{Description: "missing or invalid admin API key"}
+	}
+
+	if e.query != nil {
+		params, err := b.queryParameters(e.query, e.required...)
+		if err != nil {
+			return nil, err
+		}
+		op.Parameters = append(op.Parameters, params...)
+	}
+	for i := range op.Parameters {
+		if op.Parameters[i].Name == "format" && len(e.formats) > 0 {
+			op.Parameters[i].Schema.Enum = e.formats
+		}
+		if desc, ok := exportParamDescriptions[op.Parameters[i].Name]; ok && e.export != nil {
+			op.Parameters[i].Description = desc
+		}
+	}
+
+	if e.cached {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        "If-None-Match",
+			In:          "header",
+			Description: "ETag of a previous response",
+			Schema:      &Schema{Type: "string"},
+		})
+		op.Responses[fmt.Sprint(http.StatusNotModified)] = &Response{Description: "the ETag matches If-None-Match"}
+	}
+
+	if e.body != nil {
+		s, err := b.schemaOf(e.body)
+		if err != nil {
+			return nil, err
+		}
+		op.RequestBody = &RequestBody{Required: true, Content: jsonContent(s)}
+	}
+
+	ok := &Response{Description: "OK"}
+	switch {
+	case e.export != nil:
+		s, err := b.schemaOf(e.export)
+		if err != nil {
+			return nil, err
+		}
+		ok.Content = map[string]*MediaType{
+			"text/csv":             {Schema: &Schema{Type: "string", Description: "a header line with the NDJSON keys, then one line per row"}},
+			"application/x-ndjson": {Schema: s},
+		}
+	case e.text:
+		ok.Content = map[string]*MediaType{"text/plain": {Schema: &Schema{Type: "string"}}}
+		op.Responses[fmt.Sprint(http.StatusBadRequest)] = &Response{Description: "invalid request", Content: ok.Content}
+		//the text endpoints can also answer with JSON, depending on the query
+		if len(e.responses) == 1 {
+			s, err := b.schemaOf(e.responses[0])
+			if err != nil {
+				return nil, err
+			}
+			ok.Content = map[string]*MediaType{"text/plain": ok.Content["text/plain"], "application/json": {Schema: s}}
+		}
+	case len(e.responses) == 1:
+		s, err := b.schemaOf(e.responses[0])
+		if err != nil {
+			return nil, err
+		}
+		ok.Content = jsonContent(wrap(e, s))
+	case len(e.responses) > 1:
+		s := &Schema{}
+		for _, r := range e.responses {
+			variant, err := b.schemaOf(r)
+			if err != nil {
+				return nil, err
+			}
+			s.OneOf = append(s.OneOf, variant)
+		}
+		ok.Content = jsonContent(wrap(e, s))
+	}
+	op.Responses[fmt.Sprint(http.StatusOK)] = ok
+	if e.unavailable {
+		op.Responses[fmt.Sprint(http.StatusServiceUnavailable)] = &Response{Description: "not ready", Content: ok.Content}
+	}
+
+	if len(e.errors) > 0 {
+		var errResponse any = request.ErrResponse{}
+		if e.envelope {
+			errResponse = response.Envelope{}
+		}
+		errSchema, err := b.schemaOf(errResponse)
+		if err != nil {
+			return nil, err
+		}
+		for _, code := range e.errors {
+			op.Responses[fmt.Sprint(code)] = &Response{Description: http.StatusText(code), Content: jsonContent(errSchema)}
+		}
+	}
+
+	return op, nil
+}
+
+// pathParameters returns the names of the {params} of the path
+func pathParameters(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			names = append(names, strings.TrimSuffix(name, "}"))
+		}
+	}
+
+	return names
+}
+
+// wrap puts the data schema in the envelope when the endpoint uses it
+func wrap(e endpoint, data *Schema) *Schema {
+	if !e.envelope {
+		return data
+	}
+
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"data":       data,
+			"error":      {Type: "object", Nullable: true, Description: "always null on success"},
+			"request_id": {Type: "string"},
+		},
+		Required: []string{"data", "error", "request_id"},
+	}
+}
+
+func jsonContent(s *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: s}}
+}
@@ -0,0 +1,231 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+)
+
+const v2Description = "The response is wrapped in the v2 envelope. " +
+	"Error codes: 400 invalid_request, 404 not_found, 422 validation_failed, 503 service_unavailable, 500 internal_error."
+
+// v2Endpoints describes the /api/v2 routes. Their data is wrapped in response.Envelope and their errors
+// are response.Envelope with the error set.
+var v2Endpoints = []endpoint{
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/supply",
+		tag:       "v2",
+		summary:   "Supply of every chain registry asset",
+		responses: []any{[]response.SupplyListItem{}},
+		errors:    []int{http.StatusServiceUnavailable},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/supply/total",
+		tag:       "v2",
+		summary:   "Total supply of a coin",
+		query:     request.SupplyParams{},
+		responses: []any{response.Supply{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusServiceUnavailable},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/supply/circulating",
+		tag:       "v2",
+		summary:   "Circulating supply of a coin",
+		query:     request.CirculatingSupplyParams{},
+		responses: []any{response.Supply{}, response.CirculatingSupply{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusServiceUnavailable},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/supply/history",
+		tag:       "v2",
+		summary:   "Supply of a coin over time",
+		query:     request.SupplyHistoryParams{},
+		responses: []any{[]response.SupplyPoint{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/assets",
+		tag:       "v2",
+		summary:   "Metadata of the assets",
+		responses: []any{[]response.Asset{}},
+		errors:    []int{http.StatusServiceUnavailable},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/articles",
+		tag:       "v2",
+		summary:   "Latest articles of the configured feeds",
+		query:     request.ArticlesParams{},
+		responses: []any{[]dto.Article{}},
+		errors:    []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/articles/onchain",
+		tag:       "v2",
+		summary:   "Latest articles published on chain",
+		query:     request.OnchainArticlesParams{},
+		responses: []any{[]dto.Article{}},
+		errors:    []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/prices",
+		tag:       "v2",
+		summary:   "Prices of the configured coins",
+		query:     request.PricesParams{},
+		responses: []any{[]dto.CoinPrice{}},
+		errors:    []int{http.StatusUnprocessableEntity},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/prices/history",
+		tag:       "v2",
+		summary:   "Price of a coin over time",
+		query:     request.PriceHistoryParams{},
+		required:  []string{"denom"},
+		responses: []any{[]response.PricePoint{}},
+		errors:    []int{http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/health/market",
+		tag:       "v2",
+		summary:   "Market health",
+		query:     request.MarketHealthRequest{},
+		required:  []string{"market_id"},
+		responses: []any{dto.MarketHealth{}},
+		errors:    []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/health/aggregator",
+		tag:       "v2",
+		summary:   "Aggregator health",
+		query:     request.AggregatorHealthRequest{},
+		responses: []any{dto.AggregatorHealth{}},
+		errors:    []int{http.StatusBadRequest},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/health/nodes",
+		tag:       "v2",
+		summary:   "Blockchain nodes health",
+		responses: []any{dto.NodesHealth{}},
+	},
+	{
+		method:    http.MethodPost,
+		path:      "/api/v2/health/balances",
+		tag:       "v2",
+		summary:   "Balances health",
+		body:      request.BalanceHealthParams{},
+		responses: []any{response.BalanceHealthResponse{}},
+		errors:    []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/health/balances/{group}",
+		tag:       "v2",
+		summary:   "Balance group health",
+		responses: []any{response.BalanceHealthResponse{}},
+		errors:    []int{http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/admin/balance-groups",
+		tag:       "admin",
+		summary:   "List the balance groups",
+		responses: []any{[]response.BalanceGroup{}},
+		errors:    []int{http.StatusServiceUnavailable},
+		admin:     true,
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/admin/balance-groups/{group}",
+		tag:       "admin",
+		summary:   "Get a balance group",
+		responses: []any{response.BalanceGroup{}},
+		errors:    []int{http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+		admin:     true,
+	},
+	{
+		method:    http.MethodPut,
+		path:      "/api/v2/admin/balance-groups/{group}",
+		tag:       "admin",
+		summary:   "Create or replace a balance group",
+		body:      request.BalanceHealthParams{},
+		responses: []any{response.BalanceGroup{}},
+		errors:    []int{http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+		admin:     true,
+	},
+	{
+		method:  http.MethodDelete,
+		path:    "/api/v2/admin/balance-groups/{group}",
+		tag:     "admin",
+		summary: "Delete a balance group",
+		errors:  []int{http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+		admin:   true,
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/health/status",
+		tag:       "v2",
+		summary:   "Alerting state and history",
+		query:     request.HealthStatusParams{},
+		responses: []any{response.HealthStatus{}},
+		errors:    []int{http.StatusBadRequest},
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/dex/tickers",
+		tag:       "v2",
+		summary:   "Tickers of all markets",
+		query:     request.TickersParams{},
+		formats:   []any{"coingecko"},
+		responses: []any{[]response.Ticker{}, []response.CoingeckoTicker{}},
+		errors:    []int{http.StatusBadRequest, http.StatusServiceUnavailable},
+		cached:    true,
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/dex/orders",
+		tag:       "v2",
+		summary:   "Order book of a market",
+		query:     request.OrdersParams{},
+		formats:   []any{"coingecko"},
+		responses: []any{response.Orders{}, response.CoingeckoOrders{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+		cached:    true,
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/dex/history",
+		tag:       "v2",
+		summary:   "Trades executed on a market",
+		query:     request.HistoryParams{},
+		formats:   []any{"coingecko"},
+		responses: []any{[]response.HistoryTrade{}, response.CoingeckoHistory{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+		cached:    true,
+	},
+	{
+		method:    http.MethodGet,
+		path:      "/api/v2/dex/intervals",
+		tag:       "v2",
+		summary:   "Price intervals (candles) of a market",
+		query:     request.DexInterval{},
+		required:  []string{"minutes"},
+		formats:   []any{"tv"},
+		responses: []any{[]entity.MarketHistoryInterval{}, []entity.TradingViewInterval{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusServiceUnavailable},
+		cached:    true,
+	},
+}
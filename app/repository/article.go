@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type ArticleRepository struct {
+	db internal.Database
+}
+
+func NewArticleRepository(db internal.Database) (*ArticleRepository, error) {
+	if db == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewArticleRepository")
+	}
+
+	return &ArticleRepository{db: db}, nil
+}
+
+// Save inserts the articles, updating the ones already saved. An article keeps the source it was first read from.
+func (r *ArticleRepository) Save(ctx context.Context, items []*entity.Article) error {
+	query := `
+	INSERT INTO article (url_key, source, title, url, picture_url, description, author_name, tags, published_at, fetched_at)
+	VALUES (:url_key, :source, :title, :url, :picture_url, :description, :author_name, :tags, :published_at, :fetched_at)
+	ON DUPLICATE KEY UPDATE
+		title = VALUES(title),
+		url = VALUES(url),
+		picture_url = VALUES(picture_url),
+		description = VALUES(description),
+		author_name = VALUES(author_name),
+		tags = VALUES(tags),
+		fetched_at = VALUES(fetched_at);`
+
+	_, err := r.db.NamedExecContext(ctx, query, items)
+
+	return err
+}
+
+// Find returns the latest articles first, only the ones of the source and with the tag when they are not empty
+func (r *ArticleRepository) Find(ctx context.Context, source, tag string, limit, offset int) ([]entity.Article, error) {
+	query := `SELECT * FROM article WHERE 1 = 1`
+	var args []any
+	if source != "" {
+		query += ` AND source = ?`
+		args = append(args, source)
+	}
+
+	if tag != "" {
+		query += ` AND tags LIKE ?`
+		args = append(args, "%,"+likeEscaper.Replace(tag)+",%")
+	}
+
+	query += ` ORDER BY published_at DESC, id DESC LIMIT ? OFFSET ?;`
+	args = append(args, limit, offset)
+
+	var results []entity.Article
+	err := r.db.SelectContext(ctx, &results, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+type BalanceWatchRepository struct {
+	db internal.Database
+}
+
+func NewBalanceWatchRepository(db internal.Database) (*BalanceWatchRepository, error) {
+	if db == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewBalanceWatchRepository")
+	}
+
+	return &BalanceWatchRepository{db: db}, nil
+}
+
+// GetAll returns the addresses of all the groups, sorted by group, address and denom
+func (r *BalanceWatchRepository) GetAll(ctx context.Context) ([]entity.BalanceWatch, error) {
+	query := `SELECT * FROM balance_watch ORDER BY group_name, address, denom;`
+
+	var results []entity.BalanceWatch
+	err := r.db.SelectContext(ctx, &results, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetGroup returns the addresses of the group, sorted by address and denom
+func (r *BalanceWatchRepository) GetGroup(ctx context.Context, group string) ([]entity.BalanceWatch, error) {
+	query := `SELECT * FROM balance_watch WHERE group_name = ? ORDER BY address, denom;`
+
+	var results []entity.BalanceWatch
+	err := r.db.SelectContext(ctx, &results, query, group)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ReplaceGroup replaces all the addresses of the group in a transaction
+func (r *BalanceWatchRepository) ReplaceGroup(ctx context.Context, group string, list []entity.BalanceWatch) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM balance_watch WHERE group_name = ?;`, group)
+	if err != nil {
+		return err
+	}
+
+	if len(list) > 0 {
+		query := `
+		INSERT INTO balance_watch (group_name, address, denom, min_amount, created_at)
+		VALUES (:group_name, :address, :denom, :min_amount, :created_at);`
+
+		_, err = tx.NamedExecContext(ctx, query, list)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteGroup deletes the group and returns false when it did not exist
+func (r *BalanceWatchRepository) DeleteGroup(ctx context.Context, group string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM balance_watch WHERE group_name = ?;`, group)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+type CointrunkRepository struct {
+	db internal.Database
+}
+
+func NewCointrunkRepository(db internal.Database) (*CointrunkRepository, error) {
+	if db == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewCointrunkRepository")
+	}
+
+	return &CointrunkRepository{db: db}, nil
+}
+
+// SaveArticles inserts the articles, updating the ones already saved
+func (r *CointrunkRepository) SaveArticles(ctx context.Context, items []*entity.CointrunkArticle) error {
+	query := `
+	INSERT INTO cointrunk_article (id, title, url, picture, publisher, paid, created_at)
+	VALUES (:id, :title, :url, :picture, :publisher, :paid, :created_at)
+	ON DUPLICATE KEY UPDATE
+		title = VALUES(title),
+		url = VALUES(url),
+		picture = VALUES(picture),
+		publisher = VALUES(publisher),
+		paid = VALUES(paid);`
+
+	_, err := r.db.NamedExecContext(ctx, query, items)
+
+	return err
+}
+
+// SavePublishers inserts the publishers, updating the ones already saved
+func (r *CointrunkRepository) SavePublishers(ctx context.Context, items []*entity.CointrunkPublisher) error {
+	query := `
+	INSERT INTO cointrunk_publisher (address, name, active, articles_count, respect, created_at)
+	VALUES (:address, :name, :active, :articles_count, :respect, :created_at)
+	ON DUPLICATE KEY UPDATE
+		name = VALUES(name),
+		active = VALUES(active),
+		articles_count = VALUES(articles_count),
+		respect = VALUES(respect);`
+
+	_, err := r.db.NamedExecContext(ctx, query, items)
+
+	return err
+}
+
+// GetLastArticleId returns the id of the latest article saved, 0 when no article is saved
+func (r *CointrunkRepository) GetLastArticleId(ctx context.Context) (uint64, error) {
+	var id uint64
+	err := r.db.GetContext(ctx, &id, "SELECT COALESCE(MAX(id), 0) FROM cointrunk_article;")
+
+	return id, err
+}
+
+// FindArticles returns the latest articles first, only the ones of the publisher when it is not empty
+func (r *CointrunkRepository) FindArticles(ctx context.Context, publisher string, limit, offset int) ([]entity.CointrunkArticleView, error) {
+	query := `
+	SELECT a.*, COALESCE(p.name, '') AS publisher_name
+	FROM cointrunk_article a
+	LEFT JOIN cointrunk_publisher p ON p.address = a.publisher`
+	var args []any
+	if publisher != "" {
+		query += ` WHERE a.publisher = ?`
+		args = append(args, publisher)
+	}
+
+	query += ` ORDER BY a.id DESC LIMIT ? OFFSET ?;`
+	args = append(args, limit, offset)
+
+	var results []entity.CointrunkArticleView
+	err := r.db.SelectContext(ctx, &results, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+type HealthEventRepository struct {
+	db internal.Database
+}
+
+func NewHealthEventRepository(db internal.Database) (*HealthEventRepository, error) {
+	if db == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewHealthEventRepository")
+	}
+
+	return &HealthEventRepository{db: db}, nil
+}
+
+func (r *HealthEventRepository) Save(ctx context.Context, e *entity.HealthEvent) error {
+	query := `
+	INSERT INTO health_event (check_name, status, message, created_at)
+	VALUES (:check_name, :status, :message, :created_at);`
+
+	_, err := r.db.NamedExecContext(ctx, query, e)
+
+	return err
+}
+
+// GetCurrent returns the last event of every check, sorted by check name
+func (r *HealthEventRepository) GetCurrent(ctx context.Context) ([]entity.HealthEvent, error) {
+	query := `
+	SELECT e.* FROM health_event e
+	INNER JOIN (SELECT MAX(id) AS id FROM health_event GROUP BY check_name) l ON l.id = e.id
+	ORDER BY e.check_name ASC;`
+
+	var results []entity.HealthEvent
+	err := r.db.SelectContext(ctx, &results, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetLatest returns the latest events first, only the ones of the check when it is not empty
+func (r *HealthEventRepository) GetLatest(ctx context.Context, checkName string, limit int) ([]entity.HealthEvent, error) {
+	query := `SELECT * FROM health_event`
+	var args []any
+	if checkName != "" {
+		query += ` WHERE check_name = ?`
+		args = append(args, checkName)
+	}
+
+	query += ` ORDER BY id DESC LIMIT ?;`
+	args = append(args, limit)
+
+	var results []entity.HealthEvent
+	err := r.db.SelectContext(ctx, &results, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
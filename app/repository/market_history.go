@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -9,7 +10,6 @@ import (
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/jmoiron/sqlx"
-	"log"
 	"time"
 )
 
@@ -25,11 +25,11 @@ func NewMarketHistoryRepository(db internal.Database) (*MarketHistoryRepository,
 	return &MarketHistoryRepository{db: db}, nil
 }
 
-func (r *MarketHistoryRepository) GetLastHistoryOrder(marketId string) (*entity.MarketHistory, error) {
+func (r *MarketHistoryRepository) GetLastHistoryOrder(ctx context.Context, marketId string) (*entity.MarketHistory, error) {
 	ent := entity.MarketHistory{}
 	query := `SELECT * FROM market_history WHERE market_id = ? ORDER BY executed_at DESC LIMIT 1`
 
-	err := r.db.Get(&ent, query, marketId)
+	err := r.db.GetContext(ctx, &ent, query, marketId)
 	if err == nil {
 		return &ent, nil
 	}
@@ -41,10 +41,10 @@ func (r *MarketHistoryRepository) GetLastHistoryOrder(marketId string) (*entity.
 	return nil, err
 }
 
-func (r *MarketHistoryRepository) SaveMarketHistoryOrders(marketId string, list []*entity.MarketHistory, clearExecutedAt []time.Time) error {
-	tx, err := r.db.Beginx()
+func (r *MarketHistoryRepository) SaveMarketHistoryOrders(ctx context.Context, marketId string, list []*entity.MarketHistory, clearExecutedAt []time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		log.Fatalln(err)
+		return err
 	}
 	defer tx.Rollback()
 
@@ -56,7 +56,7 @@ func (r *MarketHistoryRepository) SaveMarketHistoryOrders(marketId string, list
 		}
 		deleteQ = tx.Rebind(deleteQ)
 
-		_, err = tx.Exec(deleteQ, deleteArgs...)
+		_, err = tx.ExecContext(ctx, deleteQ, deleteArgs...)
 		if err != nil {
 			return err
 		}
@@ -70,7 +70,7 @@ func (r *MarketHistoryRepository) SaveMarketHistoryOrders(marketId string, list
 	);
 `
 
-	_, err = tx.NamedExec(query, list)
+	_, err = tx.NamedExecContext(ctx, query, list)
 	if err != nil {
 		return err
 	}
@@ -83,11 +83,11 @@ func (r *MarketHistoryRepository) SaveMarketHistoryOrders(marketId string, list
 	return nil
 }
 
-func (r *MarketHistoryRepository) GetByExecutedAt(marketId string, executedAt time.Time) ([]entity.MarketHistory, error) {
+func (r *MarketHistoryRepository) GetByExecutedAt(ctx context.Context, marketId string, executedAt time.Time) ([]entity.MarketHistory, error) {
 	query := `SELECT * FROM market_history WHERE market_id = ? AND executed_at >= ? ORDER BY executed_at ASC LIMIT 50000`
 
 	var results []entity.MarketHistory
-	err := r.db.Select(&results, query, marketId, executedAt)
+	err := r.db.SelectContext(ctx, &results, query, marketId, executedAt)
 	if err == nil {
 		return results, nil
 	}
@@ -99,11 +99,11 @@ func (r *MarketHistoryRepository) GetByExecutedAt(marketId string, executedAt ti
 	return nil, err
 }
 
-func (r *MarketHistoryRepository) GetOldestNotAddedToInterval(marketId string) (*entity.MarketHistory, error) {
+func (r *MarketHistoryRepository) GetOldestNotAddedToInterval(ctx context.Context, marketId string) (*entity.MarketHistory, error) {
 	ent := entity.MarketHistory{}
 	query := `SELECT * FROM market_history WHERE market_id = ? AND i_added_to_interval = 0 ORDER BY executed_at ASC LIMIT 1`
 
-	err := r.db.Get(&ent, query, marketId)
+	err := r.db.GetContext(ctx, &ent, query, marketId)
 	if err == nil {
 		return &ent, nil
 	}
@@ -115,19 +115,19 @@ func (r *MarketHistoryRepository) GetOldestNotAddedToInterval(marketId string) (
 	return nil, err
 }
 
-func (r *MarketHistoryRepository) MarkAsAddedToInterval(ids []int) error {
+func (r *MarketHistoryRepository) MarkAsAddedToInterval(ctx context.Context, ids []int) error {
 	query := "UPDATE market_history SET i_added_to_interval = 1 WHERE id IN (?)"
 	query, args, err := sqlx.In(query, ids)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.db.Exec(query, args...)
+	_, err = r.db.ExecContext(ctx, query, args...)
 
 	return err
 }
 
-func (r *MarketHistoryRepository) GetHistoryBy(params request.HistoryParams) ([]entity.MarketHistory, error) {
+func (r *MarketHistoryRepository) GetHistoryBy(ctx context.Context, params request.HistoryParams) ([]entity.MarketHistory, error) {
 	query := "SELECT * FROM market_history WHERE 1 = 1"
 
 	var args []interface{}
@@ -158,7 +158,7 @@ func (r *MarketHistoryRepository) GetHistoryBy(params request.HistoryParams) ([]
 	}
 
 	var results []entity.MarketHistory
-	err := r.db.Select(&results, query, args...)
+	err := r.db.SelectContext(ctx, &results, query, args...)
 	if err == nil {
 		return results, nil
 	}
@@ -170,11 +170,11 @@ func (r *MarketHistoryRepository) GetHistoryBy(params request.HistoryParams) ([]
 	return nil, err
 }
 
-func (r *MarketHistoryRepository) GetFirstMarketOrderTime(marketId string) (time.Time, error) {
+func (r *MarketHistoryRepository) GetFirstMarketOrderTime(ctx context.Context, marketId string) (time.Time, error) {
 	ent := entity.MarketHistory{}
 	query := `SELECT * FROM market_history WHERE market_id = ? ORDER BY executed_at ASC LIMIT 1`
 
-	err := r.db.Get(&ent, query, marketId)
+	err := r.db.GetContext(ctx, &ent, query, marketId)
 	if err == nil {
 		return ent.ExecutedAt, nil
 	}
@@ -185,3 +185,55 @@ func (r *MarketHistoryRepository) GetFirstMarketOrderTime(marketId string) (time
 
 	return time.Time{}, err
 }
+
+// DeleteAddedToIntervalBefore deletes at most limit orders executed before the provided time that were already
+// added to the intervals. The order with keepId is never deleted: the sync resumes from the last order.
+func (r *MarketHistoryRepository) DeleteAddedToIntervalBefore(ctx context.Context, marketId string, before time.Time, keepId int, limit int) (int64, error) {
+	query := `
+		DELETE FROM market_history
+		WHERE market_id = ? AND executed_at < ? AND i_added_to_interval = 1 AND id <> ?
+		LIMIT ?
+	`
+
+	res, err := r.db.ExecContext(ctx, query, marketId, before, keepId, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// StreamByExecutedAt calls fn for every trade of the market executed in [from, to), oldest first. The rows are read
+// one by one, so the whole history of a market can be streamed. Zero from or to leave that end open.
+func (r *MarketHistoryRepository) StreamByExecutedAt(ctx context.Context, marketId string, from, to time.Time, fn func(*entity.MarketHistory) error) error {
+	query := "SELECT * FROM market_history WHERE market_id = ?"
+	args := []interface{}{marketId}
+	if !from.IsZero() {
+		query = fmt.Sprintf("%s AND executed_at >= ?", query)
+		args = append(args, from)
+	}
+
+	if !to.IsZero() {
+		query = fmt.Sprintf("%s AND executed_at < ?", query)
+		args = append(args, to)
+	}
+
+	rows, err := r.db.QueryxContext(ctx, fmt.Sprintf("%s ORDER BY executed_at ASC, id ASC", query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e entity.MarketHistory
+		if err = rows.StructScan(&e); err != nil {
+			return err
+		}
+
+		if err = fn(&e); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
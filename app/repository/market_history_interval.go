@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -24,7 +25,7 @@ func NewMarketIntervalRepository(db internal.Database) (*MarketIntervalRepositor
 	return &MarketIntervalRepository{db: db}, nil
 }
 
-func (r *MarketIntervalRepository) Save(items []*entity.MarketHistoryInterval) error {
+func (r *MarketIntervalRepository) Save(ctx context.Context, items []*entity.MarketHistoryInterval) error {
 	query := `
 	INSERT INTO market_history_interval (
 		market_id, length, start_at, end_at, 
@@ -46,7 +47,7 @@ func (r *MarketIntervalRepository) Save(items []*entity.MarketHistoryInterval) e
 		i_updated_at = NOW()
 	;`
 
-	_, err := r.db.NamedExec(query, items)
+	_, err := r.db.NamedExecContext(ctx, query, items)
 	if err != nil {
 		return err
 	}
@@ -54,7 +55,7 @@ func (r *MarketIntervalRepository) Save(items []*entity.MarketHistoryInterval) e
 	return nil
 }
 
-func (r *MarketIntervalRepository) GetIntervalsByExecutedAt(marketId string, executedAt time.Time, length int) ([]entity.MarketHistoryInterval, error) {
+func (r *MarketIntervalRepository) GetIntervalsByExecutedAt(ctx context.Context, marketId string, executedAt time.Time, length int) ([]entity.MarketHistoryInterval, error) {
 	q := `
 		SELECT * FROM market_history_interval mhi
 		WHERE mhi.market_id = ?
@@ -64,7 +65,7 @@ func (r *MarketIntervalRepository) GetIntervalsByExecutedAt(marketId string, exe
 	`
 
 	var results []entity.MarketHistoryInterval
-	err := r.db.Select(&results, q, marketId, length, executedAt)
+	err := r.db.SelectContext(ctx, &results, q, marketId, length, executedAt)
 	if err == nil {
 		return results, nil
 	}
@@ -76,8 +77,8 @@ func (r *MarketIntervalRepository) GetIntervalsByExecutedAt(marketId string, exe
 	return nil, err
 }
 
-func (r *MarketIntervalRepository) GetIntervalsBy(params *query.IntervalsParams) (query.IntervalsMap, error) {
-	rows, err := r.intervalsByRows(params, []string{"*"})
+func (r *MarketIntervalRepository) GetIntervalsBy(ctx context.Context, params *query.IntervalsParams) (query.IntervalsMap, error) {
+	rows, err := r.intervalsByRows(ctx, params, []string{"*"})
 	if err != nil {
 		return nil, err
 	}
@@ -100,8 +101,8 @@ func (r *MarketIntervalRepository) GetIntervalsBy(params *query.IntervalsParams)
 	return res, nil
 }
 
-func (r *MarketIntervalRepository) GetTradingViewIntervalsBy(params *query.IntervalsParams) (query.TradingIntervalsMap, error) {
-	rows, err := r.intervalsByRows(params, []string{"start_at", "lowest_price", "open_price", "highest_price", "close_price", "base_volume"})
+func (r *MarketIntervalRepository) GetTradingViewIntervalsBy(ctx context.Context, params *query.IntervalsParams) (query.TradingIntervalsMap, error) {
+	rows, err := r.intervalsByRows(ctx, params, []string{"start_at", "lowest_price", "open_price", "highest_price", "close_price", "base_volume"})
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +125,7 @@ func (r *MarketIntervalRepository) GetTradingViewIntervalsBy(params *query.Inter
 	return res, nil
 }
 
-func (r *MarketIntervalRepository) intervalsByRows(params *query.IntervalsParams, selectFields []string) (*sqlx.Rows, error) {
+func (r *MarketIntervalRepository) intervalsByRows(ctx context.Context, params *query.IntervalsParams, selectFields []string) (*sqlx.Rows, error) {
 	if params.MarketId == "" {
 		return nil, fmt.Errorf("can not get intervals without market_id")
 	}
@@ -145,15 +146,90 @@ func (r *MarketIntervalRepository) intervalsByRows(params *query.IntervalsParams
 		args = append(args, params.StartAt)
 	}
 
+	if !params.EndAt.Equal(time.Time{}) {
+		q = fmt.Sprintf("%s AND mhi.start_at < ?", q)
+		args = append(args, params.EndAt)
+	}
+
 	q = fmt.Sprintf("%s ORDER BY start_at DESC", q)
 	if params.Limit > 0 {
 		q = fmt.Sprintf("%s LIMIT %d", q, params.Limit)
 	}
 
-	rows, err := r.db.Queryx(q, args...)
+	rows, err := r.db.QueryxContext(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	return rows, nil
 }
+
+// CountUncovered counts the intervals of the given length starting before the provided time that are not
+// covered by an interval of the coarser length
+func (r *MarketIntervalRepository) CountUncovered(ctx context.Context, marketId string, length, coarserLength int, before time.Time) (int, error) {
+	q := `
+		SELECT COUNT(*) FROM market_history_interval f
+		WHERE f.market_id = ?
+		AND f.length = ?
+		AND f.start_at < ?
+		AND NOT EXISTS (
+			SELECT 1 FROM market_history_interval c
+			WHERE c.market_id = f.market_id
+			AND c.length = ?
+			AND c.start_at <= f.start_at
+			AND c.end_at >= f.end_at
+		)
+	`
+
+	var count int
+	err := r.db.GetContext(ctx, &count, q, marketId, length, before, coarserLength)
+
+	return count, err
+}
+
+// DeleteStartedBefore deletes at most limit intervals of the given length starting before the provided time
+func (r *MarketIntervalRepository) DeleteStartedBefore(ctx context.Context, marketId string, length int, before time.Time, limit int) (int64, error) {
+	q := `DELETE FROM market_history_interval WHERE market_id = ? AND length = ? AND start_at < ? LIMIT ?`
+
+	res, err := r.db.ExecContext(ctx, q, marketId, length, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// StreamIntervals calls fn for every interval matching params, oldest first, reading the rows one by one.
+// params.Limit is ignored.
+func (r *MarketIntervalRepository) StreamIntervals(ctx context.Context, params *query.IntervalsParams, fn func(*entity.MarketHistoryInterval) error) error {
+	q := "SELECT * FROM market_history_interval WHERE market_id = ? AND length = ?"
+	args := []interface{}{params.MarketId, params.Length}
+	if !params.StartAt.IsZero() {
+		q = fmt.Sprintf("%s AND start_at >= ?", q)
+		args = append(args, params.StartAt)
+	}
+
+	if !params.EndAt.IsZero() {
+		q = fmt.Sprintf("%s AND start_at < ?", q)
+		args = append(args, params.EndAt)
+	}
+
+	rows, err := r.db.QueryxContext(ctx, fmt.Sprintf("%s ORDER BY start_at ASC", q), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e entity.MarketHistoryInterval
+		if err = rows.StructScan(&e); err != nil {
+			return err
+		}
+
+		if err = fn(&e); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -8,7 +9,6 @@ import (
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/jmoiron/sqlx"
-	"log"
 	"slices"
 )
 
@@ -24,7 +24,7 @@ func NewMarketOrderRepository(db internal.Database) (*MarketOrderRepository, err
 	return &MarketOrderRepository{db: db}, nil
 }
 
-func (r *MarketOrderRepository) GetMarketOrdersWithDepth(marketId, orderType string, limit int) ([]entity.MarketOrder, error) {
+func (r *MarketOrderRepository) GetMarketOrdersWithDepth(ctx context.Context, marketId, orderType string, limit int) ([]entity.MarketOrder, error) {
 	sort := "ASC"
 	if orderType == types.OrderTypeBuy {
 		sort = "DESC"
@@ -37,7 +37,7 @@ func (r *MarketOrderRepository) GetMarketOrdersWithDepth(marketId, orderType str
 	}
 
 	var results []entity.MarketOrder
-	err := r.db.Select(&results, query, marketId, orderType)
+	err := r.db.SelectContext(ctx, &results, query, marketId, orderType)
 	if err == nil {
 		if orderType == types.OrderTypeBuy {
 			slices.Reverse(results)
@@ -55,10 +55,10 @@ func (r *MarketOrderRepository) GetMarketOrdersWithDepth(marketId, orderType str
 
 // Upsert deletes all orders for the provided marketIds and inserts the newly retrieved list.
 // in case of failure it rolls back the sql transaction
-func (r *MarketOrderRepository) Upsert(list []*entity.MarketOrder, marketIds []string) error {
-	tx, err := r.db.Beginx()
+func (r *MarketOrderRepository) Upsert(ctx context.Context, list []*entity.MarketOrder, marketIds []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		log.Fatalln(err)
+		return err
 	}
 	defer tx.Rollback()
 
@@ -68,11 +68,16 @@ func (r *MarketOrderRepository) Upsert(list []*entity.MarketOrder, marketIds []s
 		return err
 	}
 	deleteQ = tx.Rebind(deleteQ)
-	_, err = tx.Exec(deleteQ, deleteArgs...)
+	_, err = tx.ExecContext(ctx, deleteQ, deleteArgs...)
 	if err != nil {
 		return err
 	}
 
+	//the orders of the markets are only deleted when they have no active orders
+	if len(list) == 0 {
+		return tx.Commit()
+	}
+
 	query := `
 	INSERT INTO market_order (
 		market_id, order_type, amount, price, price_dec, i_quote_amount, i_created_at
@@ -80,7 +85,7 @@ func (r *MarketOrderRepository) Upsert(list []*entity.MarketOrder, marketIds []s
 		:market_id, :order_type, :amount, :price, :price_dec, :i_quote_amount, NOW()
 	)`
 
-	_, err = tx.NamedExec(query, list)
+	_, err = tx.NamedExecContext(ctx, query, list)
 	if err != nil {
 		return err
 	}
@@ -93,13 +98,13 @@ func (r *MarketOrderRepository) Upsert(list []*entity.MarketOrder, marketIds []s
 	return nil
 }
 
-func (r *MarketOrderRepository) GetHighestBuy(marketId string) (*entity.MarketOrder, error) {
+func (r *MarketOrderRepository) GetHighestBuy(ctx context.Context, marketId string) (*entity.MarketOrder, error) {
 	query := `
 		SELECT * FROM market_order WHERE market_id = ? AND order_type = ?  ORDER BY price_dec DESC LIMIT 1;
 	`
 
 	ent := &entity.MarketOrder{}
-	err := r.db.Get(ent, query, marketId, entity.OrderTypeBuy)
+	err := r.db.GetContext(ctx, ent, query, marketId, entity.OrderTypeBuy)
 	if err == nil {
 		return ent, nil
 	}
@@ -111,13 +116,13 @@ func (r *MarketOrderRepository) GetHighestBuy(marketId string) (*entity.MarketOr
 	return nil, err
 }
 
-func (r *MarketOrderRepository) GetLowestSell(marketId string) (*entity.MarketOrder, error) {
+func (r *MarketOrderRepository) GetLowestSell(ctx context.Context, marketId string) (*entity.MarketOrder, error) {
 	query := `
 		SELECT * FROM market_order WHERE market_id = ? AND order_type = ?  ORDER BY price_dec ASC LIMIT 1;
 	`
 
 	ent := &entity.MarketOrder{}
-	err := r.db.Get(ent, query, marketId, entity.OrderTypeSell)
+	err := r.db.GetContext(ctx, ent, query, marketId, entity.OrderTypeSell)
 	if err == nil {
 		return ent, nil
 	}
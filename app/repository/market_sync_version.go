@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+type MarketSyncVersionRepository struct {
+	db internal.Database
+}
+
+func NewMarketSyncVersionRepository(db internal.Database) (*MarketSyncVersionRepository, error) {
+	if db == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewMarketSyncVersionRepository")
+	}
+
+	return &MarketSyncVersionRepository{db: db}, nil
+}
+
+// Bump increases the version of the market, creating it if needed
+func (r *MarketSyncVersionRepository) Bump(ctx context.Context, marketId string) error {
+	query := `
+	INSERT INTO market_sync_version (market_id, version, updated_at)
+	VALUES (?, 1, ?)
+	ON DUPLICATE KEY UPDATE
+		version = version + 1,
+		updated_at = VALUES(updated_at);`
+
+	_, err := r.db.ExecContext(ctx, query, marketId, time.Now().UTC())
+
+	return err
+}
+
+func (r *MarketSyncVersionRepository) GetVersions(ctx context.Context) ([]entity.MarketSyncVersion, error) {
+	query := `SELECT market_id, version, updated_at FROM market_sync_version;`
+
+	var results []entity.MarketSyncVersion
+	err := r.db.SelectContext(ctx, &results, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
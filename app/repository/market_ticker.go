@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+type MarketTickerRepository struct {
+	db internal.Database
+}
+
+func NewMarketTickerRepository(db internal.Database) (*MarketTickerRepository, error) {
+	if db == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewMarketTickerRepository")
+	}
+
+	return &MarketTickerRepository{db: db}, nil
+}
+
+// SaveOrderBook saves the best bid and ask of the market, leaving its stats untouched
+func (r *MarketTickerRepository) SaveOrderBook(ctx context.Context, marketId string, bid, ask sql.NullString) error {
+	query := `
+	INSERT INTO market_ticker (market_id, bid, ask, updated_at)
+	VALUES (?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		bid = VALUES(bid),
+		ask = VALUES(ask),
+		updated_at = VALUES(updated_at);`
+
+	_, err := r.db.ExecContext(ctx, query, marketId, bid, ask, time.Now().UTC())
+
+	return err
+}
+
+// SaveStats saves the 24h stats of the market, leaving its bid and ask untouched
+func (r *MarketTickerRepository) SaveStats(ctx context.Context, stats *entity.TickerStats) error {
+	query := `
+	INSERT INTO market_ticker (
+		market_id, last_price, open_price, high, low, base_volume, quote_volume, updated_at
+	) VALUES (
+		:market_id, :last_price, :open_price, :high, :low, :base_volume, :quote_volume, :updated_at
+	)
+	ON DUPLICATE KEY UPDATE
+		last_price = VALUES(last_price),
+		open_price = VALUES(open_price),
+		high = VALUES(high),
+		low = VALUES(low),
+		base_volume = VALUES(base_volume),
+		quote_volume = VALUES(quote_volume),
+		updated_at = VALUES(updated_at);`
+
+	_, err := r.db.NamedExecContext(ctx, query, map[string]any{
+		"market_id":    stats.MarketID,
+		"last_price":   stats.LastPrice,
+		"open_price":   stats.OpenPrice,
+		"high":         stats.High,
+		"low":          stats.Low,
+		"base_volume":  stats.BaseVolume,
+		"quote_volume": stats.QuoteVolume,
+		"updated_at":   stats.UpdatedAt.UTC(),
+	})
+
+	return err
+}
+
+// GetMarketsWithTicker returns all the markets together with their ticker in a single read
+func (r *MarketTickerRepository) GetMarketsWithTicker(ctx context.Context) ([]entity.MarketWithTicker, error) {
+	query := `
+		SELECT
+			m.market_id AS market_id,
+			m.base AS base,
+			m.quote AS quote,
+			t.last_price AS last_price,
+			t.bid AS bid,
+			t.ask AS ask,
+			t.open_price AS open_price,
+			t.high AS high,
+			t.low AS low,
+			t.base_volume AS base_volume,
+			t.quote_volume AS quote_volume,
+			t.updated_at AS updated_at
+		FROM market m
+		LEFT JOIN market_ticker t ON t.market_id = m.market_id
+		ORDER BY m.id ASC;
+	`
+
+	var results []entity.MarketWithTicker
+	err := r.db.SelectContext(ctx, &results, query)
+	if err == nil {
+		return results, nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return results, nil
+	}
+
+	return nil, err
+}
+
+// GetTicker returns the ticker of the market, nil when the market was not synced yet
+func (r *MarketTickerRepository) GetTicker(ctx context.Context, marketId string) (*entity.MarketTicker, error) {
+	var result entity.MarketTicker
+	err := r.db.GetContext(ctx, &result, "SELECT * FROM market_ticker WHERE market_id = ?", marketId)
+	if err == nil {
+		return &result, nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return nil, err
+}
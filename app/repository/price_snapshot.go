@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+type PriceSnapshotRepository struct {
+	db internal.Database
+}
+
+func NewPriceSnapshotRepository(db internal.Database) (*PriceSnapshotRepository, error) {
+	if db == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewPriceSnapshotRepository")
+	}
+
+	return &PriceSnapshotRepository{db: db}, nil
+}
+
+// Save inserts the snapshots, replacing the ones of the same denom and currency taken at the same time
+func (r *PriceSnapshotRepository) Save(ctx context.Context, items []*entity.PriceSnapshot) error {
+	query := `
+	INSERT INTO price_snapshot (denom, currency, price, source, taken_at)
+	VALUES (:denom, :currency, :price, :source, :taken_at)
+	ON DUPLICATE KEY UPDATE
+		price = VALUES(price),
+		source = VALUES(source);`
+
+	_, err := r.db.NamedExecContext(ctx, query, items)
+
+	return err
+}
+
+// GetBetween returns the snapshots of the denom in the currency taken in [from, to), oldest first
+func (r *PriceSnapshotRepository) GetBetween(ctx context.Context, denom, currency string, from, to time.Time) ([]entity.PriceSnapshot, error) {
+	query := `
+	SELECT * FROM price_snapshot
+	WHERE denom = ? AND currency = ? AND taken_at >= ? AND taken_at < ?
+	ORDER BY taken_at ASC;`
+
+	var results []entity.PriceSnapshot
+	err := r.db.SelectContext(ctx, &results, query, denom, currency, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+type SupplySnapshotRepository struct {
+	db internal.Database
+}
+
+func NewSupplySnapshotRepository(db internal.Database) (*SupplySnapshotRepository, error) {
+	if db == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewSupplySnapshotRepository")
+	}
+
+	return &SupplySnapshotRepository{db: db}, nil
+}
+
+// Save inserts the snapshot, replacing the one of the same denom taken at the same time
+func (r *SupplySnapshotRepository) Save(ctx context.Context, s *entity.SupplySnapshot) error {
+	query := `
+	INSERT INTO supply_snapshot (denom, total, community_pool, circulating, taken_at)
+	VALUES (:denom, :total, :community_pool, :circulating, :taken_at)
+	ON DUPLICATE KEY UPDATE
+		total = VALUES(total),
+		community_pool = VALUES(community_pool),
+		circulating = VALUES(circulating);`
+
+	_, err := r.db.NamedExecContext(ctx, query, s)
+
+	return err
+}
+
+// GetBetween returns the snapshots of the denom taken in [from, to), oldest first
+func (r *SupplySnapshotRepository) GetBetween(ctx context.Context, denom string, from, to time.Time) ([]entity.SupplySnapshot, error) {
+	query := `
+	SELECT * FROM supply_snapshot
+	WHERE denom = ? AND taken_at >= ? AND taken_at < ?
+	ORDER BY taken_at ASC;`
+
+	var results []entity.SupplySnapshot
+	err := r.db.SelectContext(ctx, &results, query, denom, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,75 @@
+// Package alertingtest provides an HTTP test double for the alerting sinks, so they can be used without network
+package alertingtest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Request is a request received by the Recorder
+type Request struct {
+	Method string
+	Url    string
+	Header http.Header
+	Body   []byte
+}
+
+// Recorder implements alerting.HTTPDoer. It saves the requests and answers them with the configured status code.
+type Recorder struct {
+	mx       sync.Mutex
+	status   int
+	err      error
+	requests []Request
+}
+
+// NewRecorder returns a Recorder answering with the status code
+func NewRecorder(status int) *Recorder {
+	return &Recorder{status: status}
+}
+
+// NewFailingRecorder returns a Recorder failing every request with err, like an unreachable host
+func NewFailingRecorder(err error) *Recorder {
+	return &Recorder{err: err}
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.requests = append(r.requests, Request{Method: req.Method, Url: req.URL.String(), Header: req.Header.Clone(), Body: body})
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return &http.Response{
+		StatusCode: r.status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+// Requests returns a copy of the requests received so far
+func (r *Recorder) Requests() []Request {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	return append([]Request(nil), r.requests...)
+}
+
+// Reset forgets the requests received so far
+func (r *Recorder) Reset() {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.requests = nil
+}
@@ -0,0 +1,97 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+)
+
+// Result is the outcome of a single run of a check, Message explains why it is unhealthy
+type Result struct {
+	Healthy bool
+	Message string
+}
+
+// Check is a health check run on a schedule, identified by its name in the events and the alerts
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) Result
+}
+
+type marketHealth interface {
+	GetMarketHealth(ctx context.Context, marketId string, minutesAgo int) dto.MarketHealth
+	GetAggregatorHealth(ctx context.Context, minutesAgo int) dto.AggregatorHealth
+	GetNodesHealth(ctx context.Context) dto.NodesHealth
+}
+
+type balanceHealth interface {
+	CheckBalances(ctx context.Context, params *request.BalanceHealthParams) []dto.AddressHealthCheck
+}
+
+// NewMarketCheck fails when the market had no trades in the last minutes
+func NewMarketCheck(health marketHealth, marketId string, minutes int) Check {
+	return Check{
+		Name: fmt.Sprintf("market:%s", marketId),
+		Run: func(ctx context.Context) Result {
+			mh := health.GetMarketHealth(ctx, marketId, minutes)
+			if mh.IsHealthy {
+				return Result{Healthy: true}
+			}
+
+			if mh.LastTrade.IsZero() {
+				return Result{Message: "no trades found"}
+			}
+
+			return Result{Message: fmt.Sprintf("no trades in the last %d minutes, last trade at %s", minutes, mh.LastTrade.UTC().Format(time.RFC3339))}
+		},
+	}
+}
+
+// NewAggregatorCheck fails when the aggregator synced no trades in the last minutes
+func NewAggregatorCheck(health marketHealth, minutes int) Check {
+	return Check{
+		Name: "aggregator",
+		Run: func(ctx context.Context) Result {
+			if health.GetAggregatorHealth(ctx, minutes).IsHealthy {
+				return Result{Healthy: true}
+			}
+
+			return Result{Message: fmt.Sprintf("no trades synced in the last %d minutes", minutes)}
+		},
+	}
+}
+
+// NewNodesCheck fails when a health node does not respond or is behind the others
+func NewNodesCheck(health marketHealth) Check {
+	return Check{
+		Name: "nodes",
+		Run: func(ctx context.Context) Result {
+			nh := health.GetNodesHealth(ctx)
+
+			return Result{Healthy: nh.IsHealthy, Message: nh.Errors}
+		},
+	}
+}
+
+// NewBalanceCheck fails when the address holds less than minAmount of the denom, see health.BalancesHealth for the units
+func NewBalanceCheck(health balanceHealth, address, denom, minAmount string) Check {
+	params := &request.BalanceHealthParams{
+		Addresses: []request.AddressBalanceParams{{Address: address, Denom: denom, MinAmount: json.Number(minAmount)}},
+	}
+
+	return Check{
+		Name: fmt.Sprintf("balance:%s:%s", address, denom),
+		Run: func(ctx context.Context) Result {
+			checks := health.CheckBalances(ctx, params)
+			if len(checks) == 0 {
+				return Result{Message: "balance not checked"}
+			}
+
+			return Result{Healthy: checks[0].IsHealthy, Message: checks[0].Error}
+		},
+	}
+}
@@ -0,0 +1,196 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+// the statuses of the health events
+const (
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+	StatusRecovered = "recovered"
+)
+
+type eventStorage interface {
+	Save(ctx context.Context, e *entity.HealthEvent) error
+	GetCurrent(ctx context.Context) ([]entity.HealthEvent, error)
+}
+
+// checkState is what the evaluator remembers about a check between two runs
+type checkState struct {
+	unhealthy bool
+	failures  int
+	//when the last unhealthy alert was sent
+	notifiedAt time.Time
+	//an alert was sent since the check became unhealthy, so its recovery is notified too
+	notified bool
+}
+
+// Evaluator runs the checks, saves their state changes (healthy -> unhealthy -> recovered) and notifies the sinks.
+// A check is unhealthy after threshold consecutive failures. While it stays unhealthy the alert is repeated at most
+// once per cooldown, and a check failing again within the cooldown of its last alert is saved but not notified.
+type Evaluator struct {
+	logger    logrus.FieldLogger
+	storage   eventStorage
+	checks    []Check
+	sinks     []Sink
+	threshold int
+	cooldown  time.Duration
+
+	//the state is loaded from the storage on the first run, so a restart does not notify again
+	loaded bool
+	states map[string]*checkState
+	mx     sync.Mutex
+}
+
+func NewEvaluator(logger logrus.FieldLogger, storage eventStorage, checks []Check, sinks []Sink, threshold int, cooldown time.Duration) (*Evaluator, error) {
+	if logger == nil || storage == nil || threshold <= 0 {
+		return nil, internal.NewInvalidDependenciesErr("NewEvaluator")
+	}
+
+	return &Evaluator{
+		logger:    logger.WithField("service", "Alerting.Evaluator"),
+		storage:   storage,
+		checks:    checks,
+		sinks:     sinks,
+		threshold: threshold,
+		cooldown:  cooldown,
+		states:    make(map[string]*checkState),
+	}, nil
+}
+
+// Evaluate runs all the checks once, in parallel, and handles their results
+func (e *Evaluator) Evaluate(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("health_alerts", start, err) }()
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	if !e.loaded {
+		if err = e.loadStates(ctx); err != nil {
+			return fmt.Errorf("could not load the health checks state: %w", err)
+		}
+	}
+
+	results := make([]Result, len(e.checks))
+	var wg sync.WaitGroup
+	for i, check := range e.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = check.Run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for i, check := range e.checks {
+		if err := e.handleResult(ctx, check.Name, results[i], time.Now().UTC()); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", check.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (e *Evaluator) loadStates(ctx context.Context) error {
+	events, err := e.storage.GetCurrent(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		state := &checkState{}
+		if event.Status == StatusUnhealthy {
+			state.unhealthy = true
+			state.failures = e.threshold
+			state.notifiedAt = event.CreatedAt
+			state.notified = true
+		}
+		e.states[event.CheckName] = state
+	}
+	e.loaded = true
+
+	return nil
+}
+
+func (e *Evaluator) handleResult(ctx context.Context, name string, result Result, now time.Time) error {
+	l := e.logger.WithField("check", name)
+	state, known := e.states[name]
+	if !known {
+		state = &checkState{}
+		e.states[name] = state
+	}
+
+	if result.Healthy {
+		state.failures = 0
+		switch {
+		case !known:
+			return e.save(ctx, name, StatusHealthy, "", now)
+		case state.unhealthy:
+			l.Info("check recovered")
+			state.unhealthy = false
+			err := e.save(ctx, name, StatusRecovered, "", now)
+			if state.notified {
+				state.notified = false
+				err = errors.Join(err, e.notify(ctx, Alert{Check: name, Status: StatusRecovered, Time: now}))
+			}
+
+			return err
+		}
+
+		return nil
+	}
+
+	state.failures++
+	if state.failures < e.threshold {
+		l.WithField("failures", state.failures).Info("check failed, below the threshold")
+
+		return nil
+	}
+
+	var err error
+	repeated := state.unhealthy
+	if !state.unhealthy {
+		l.WithField("message", result.Message).Warn("check is unhealthy")
+		state.unhealthy = true
+		err = e.save(ctx, name, StatusUnhealthy, result.Message, now)
+	}
+
+	//also keeps quiet a check failing again soon after its recovery
+	if !state.notifiedAt.IsZero() && now.Sub(state.notifiedAt) < e.cooldown {
+		return err
+	}
+
+	state.notifiedAt = now
+	state.notified = true
+
+	return errors.Join(err, e.notify(ctx, Alert{Check: name, Status: StatusUnhealthy, Message: result.Message, Time: now, Repeated: repeated}))
+}
+
+func (e *Evaluator) save(ctx context.Context, name, status, message string, now time.Time) error {
+	return e.storage.Save(ctx, &entity.HealthEvent{CheckName: name, Status: status, Message: message, CreatedAt: now})
+}
+
+// notify sends the alert to every sink, a failing sink does not stop the others
+func (e *Evaluator) notify(ctx context.Context, alert Alert) error {
+	var errs []error
+	for _, sink := range e.sinks {
+		if err := sink.Send(ctx, alert); err != nil {
+			e.logger.WithError(err).WithField("sink", sink.Name()).Error("could not send the alert")
+			errs = append(errs, fmt.Errorf("sink %s: %w", sink.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
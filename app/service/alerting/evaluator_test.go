@@ -0,0 +1,265 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/alerting/alertingtest"
+	"github.com/sirupsen/logrus"
+)
+
+const testCheck = "aggregator"
+
+// memoryEvents keeps the saved events, GetCurrent returns the last event of every check
+type memoryEvents struct {
+	events []entity.HealthEvent
+}
+
+func (m *memoryEvents) Save(_ context.Context, e *entity.HealthEvent) error {
+	m.events = append(m.events, *e)
+
+	return nil
+}
+
+func (m *memoryEvents) GetCurrent(_ context.Context) ([]entity.HealthEvent, error) {
+	last := make(map[string]entity.HealthEvent)
+	for _, e := range m.events {
+		last[e.CheckName] = e
+	}
+
+	var result []entity.HealthEvent
+	for _, e := range last {
+		result = append(result, e)
+	}
+
+	return result, nil
+}
+
+func (m *memoryEvents) statuses() []string {
+	var result []string
+	for _, e := range m.events {
+		result = append(result, e.Status)
+	}
+
+	return result
+}
+
+// sentAlerts decodes the alerts posted by a webhook sink
+func sentAlerts(t *testing.T, rec *alertingtest.Recorder) []Alert {
+	t.Helper()
+
+	var result []Alert
+	for _, req := range rec.Requests() {
+		var alert Alert
+		if err := json.Unmarshal(req.Body, &alert); err != nil {
+			t.Fatalf("invalid alert %q: %v", req.Body, err)
+		}
+		result = append(result, alert)
+	}
+
+	return result
+}
+
+func newTestEvaluator(t *testing.T, storage eventStorage, threshold int, cooldown time.Duration) (*Evaluator, *alertingtest.Recorder) {
+	t.Helper()
+
+	rec := alertingtest.NewRecorder(http.StatusOK)
+	sink, err := NewWebhookSink("https://hooks.example.com/alerts", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	e, err := NewEvaluator(logger, storage, nil, []Sink{sink}, threshold, cooldown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = e.loadStates(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return e, rec
+}
+
+// run handles the results of a check, a minute apart starting at start
+func run(t *testing.T, e *Evaluator, start time.Time, results ...bool) time.Time {
+	t.Helper()
+
+	now := start
+	for _, healthy := range results {
+		if err := e.handleResult(context.Background(), testCheck, Result{Healthy: healthy, Message: "down"}, now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	return now
+}
+
+func assertStatuses(t *testing.T, storage *memoryEvents, expected ...string) {
+	t.Helper()
+
+	got := storage.statuses()
+	if len(got) != len(expected) {
+		t.Fatalf("expected the events %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected the events %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestEvaluatorWaitsForTheThreshold(t *testing.T) {
+	storage := &memoryEvents{}
+	e, rec := newTestEvaluator(t, storage, 3, time.Hour)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	now := run(t, e, start, true, false, false)
+	assertStatuses(t, storage, StatusHealthy)
+	if len(rec.Requests()) != 0 {
+		t.Fatalf("expected no alert below the threshold, got %d", len(rec.Requests()))
+	}
+
+	//a success resets the failures count
+	now = run(t, e, now, true, false, false)
+	assertStatuses(t, storage, StatusHealthy)
+
+	run(t, e, now, false)
+	assertStatuses(t, storage, StatusHealthy, StatusUnhealthy)
+	alerts := sentAlerts(t, rec)
+	if len(alerts) != 1 || alerts[0].Status != StatusUnhealthy || alerts[0].Message != "down" || alerts[0].Repeated {
+		t.Fatalf("expected a single unhealthy alert, got %+v", alerts)
+	}
+}
+
+func TestEvaluatorSavesTheStateChanges(t *testing.T) {
+	storage := &memoryEvents{}
+	e, rec := newTestEvaluator(t, storage, 1, time.Hour)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	//only the changes are saved, not every run
+	run(t, e, start, true, true, false, false, true, true)
+	assertStatuses(t, storage, StatusHealthy, StatusUnhealthy, StatusRecovered)
+
+	alerts := sentAlerts(t, rec)
+	if len(alerts) != 2 || alerts[0].Status != StatusUnhealthy || alerts[1].Status != StatusRecovered {
+		t.Fatalf("expected the unhealthy and recovered alerts, got %+v", alerts)
+	}
+	for _, event := range storage.events {
+		if event.CheckName != testCheck {
+			t.Errorf("unexpected check name %s", event.CheckName)
+		}
+	}
+}
+
+func TestEvaluatorCooldown(t *testing.T) {
+	storage := &memoryEvents{}
+	e, rec := newTestEvaluator(t, storage, 1, 10*time.Minute)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	//still unhealthy: a single alert within the cooldown, then a reminder
+	now := run(t, e, start, false, false, false, false, false, false, false, false, false, false)
+	if alerts := sentAlerts(t, rec); len(alerts) != 1 {
+		t.Fatalf("expected 1 alert within the cooldown, got %d", len(alerts))
+	}
+
+	run(t, e, now, false)
+	alerts := sentAlerts(t, rec)
+	if len(alerts) != 2 || !alerts[1].Repeated {
+		t.Fatalf("expected a repeated alert after the cooldown, got %+v", alerts)
+	}
+
+	//flapping: the recovery is notified but failing again within the cooldown is only saved
+	rec.Reset()
+	now = run(t, e, now.Add(time.Minute), true, false)
+	assertStatuses(t, storage, StatusUnhealthy, StatusRecovered, StatusUnhealthy)
+	alerts = sentAlerts(t, rec)
+	if len(alerts) != 1 || alerts[0].Status != StatusRecovered {
+		t.Fatalf("expected only the recovered alert, got %+v", alerts)
+	}
+
+	//and its recovery is not notified since no alert was sent for it
+	run(t, e, now, true)
+	assertStatuses(t, storage, StatusUnhealthy, StatusRecovered, StatusUnhealthy, StatusRecovered)
+	if alerts = sentAlerts(t, rec); len(alerts) != 1 {
+		t.Fatalf("expected no alert for the unnotified recovery, got %+v", alerts)
+	}
+}
+
+func TestEvaluatorDoesNotNotifyAgainAfterRestart(t *testing.T) {
+	now := time.Now().UTC()
+	storage := &memoryEvents{events: []entity.HealthEvent{
+		{CheckName: testCheck, Status: StatusHealthy, CreatedAt: now.Add(-time.Hour)},
+		{CheckName: testCheck, Status: StatusUnhealthy, Message: "down", CreatedAt: now.Add(-time.Minute)},
+	}}
+	e, rec := newTestEvaluator(t, storage, 3, time.Hour)
+
+	//the check was notified before the restart: no new event and no alert while in cooldown
+	next := run(t, e, now, false)
+	assertStatuses(t, storage, StatusHealthy, StatusUnhealthy)
+	if len(rec.Requests()) != 0 {
+		t.Fatalf("expected no alert after the restart, got %+v", sentAlerts(t, rec))
+	}
+
+	//the recovery of the alert sent before the restart is notified
+	run(t, e, next, true)
+	assertStatuses(t, storage, StatusHealthy, StatusUnhealthy, StatusRecovered)
+	alerts := sentAlerts(t, rec)
+	if len(alerts) != 1 || alerts[0].Status != StatusRecovered {
+		t.Fatalf("expected the recovered alert, got %+v", alerts)
+	}
+}
+
+func TestEvaluatorDoesNotNotifyRecoveryWithoutAlert(t *testing.T) {
+	storage := &memoryEvents{}
+	e, rec := newTestEvaluator(t, storage, 1, time.Hour)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	//the first alert is sent, the second outage starts within its cooldown
+	now := run(t, e, start, false, true)
+	rec.Reset()
+	run(t, e, now, false, true)
+
+	assertStatuses(t, storage, StatusUnhealthy, StatusRecovered, StatusUnhealthy, StatusRecovered)
+	if len(rec.Requests()) != 0 {
+		t.Fatalf("expected no alert, got %+v", sentAlerts(t, rec))
+	}
+}
+
+func TestEvaluateRunsTheChecks(t *testing.T) {
+	storage := &memoryEvents{}
+	rec := alertingtest.NewRecorder(http.StatusOK)
+	sink, _ := NewWebhookSink("https://hooks.example.com/alerts", rec)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	checks := []Check{
+		{Name: "up", Run: func(context.Context) Result { return Result{Healthy: true} }},
+		{Name: "down", Run: func(context.Context) Result { return Result{Message: "down"} }},
+	}
+	e, err := NewEvaluator(logger, storage, checks, []Sink{sink}, 1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = e.Evaluate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	current, _ := storage.GetCurrent(context.Background())
+	if len(current) != 2 {
+		t.Fatalf("expected an event per check, got %+v", current)
+	}
+	alerts := sentAlerts(t, rec)
+	if len(alerts) != 1 || alerts[0].Check != "down" {
+		t.Fatalf("expected an alert for the failing check, got %+v", alerts)
+	}
+}
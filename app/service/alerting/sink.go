@@ -0,0 +1,159 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+// Alert is a notification about a check
+type Alert struct {
+	Check   string    `json:"check"`
+	Status  string    `json:"status"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+	//true when the check was already unhealthy and the alert is a reminder
+	Repeated bool `json:"repeated"`
+}
+
+// Text returns the alert as a single line message
+func (a Alert) Text() string {
+	text := fmt.Sprintf("[bze-aggregator] %s is %s", a.Check, a.Status)
+	if a.Repeated {
+		text = fmt.Sprintf("[bze-aggregator] %s is still %s", a.Check, a.Status)
+	}
+
+	if a.Message != "" {
+		text = fmt.Sprintf("%s: %s", text, a.Message)
+	}
+
+	return text
+}
+
+// Sink delivers the alerts somewhere
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, alert Alert) error
+}
+
+// HTTPDoer sends the requests of the sinks, *http.Client in production and alertingtest.Recorder in tests
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// WebhookSink posts the alert as JSON
+type WebhookSink struct {
+	url    string
+	client HTTPDoer
+}
+
+func NewWebhookSink(webhookUrl string, client HTTPDoer) (*WebhookSink, error) {
+	if webhookUrl == "" || client == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewWebhookSink")
+	}
+
+	return &WebhookSink{url: webhookUrl, client: client}, nil
+}
+
+func (w *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (w *WebhookSink) Send(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, w.client, w.url, alert)
+}
+
+// SlackSink posts the alert text to a slack compatible incoming webhook (slack, mattermost, discord /slack)
+type SlackSink struct {
+	url    string
+	client HTTPDoer
+}
+
+func NewSlackSink(webhookUrl string, client HTTPDoer) (*SlackSink, error) {
+	if webhookUrl == "" || client == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewSlackSink")
+	}
+
+	return &SlackSink{url: webhookUrl, client: client}, nil
+}
+
+func (s *SlackSink) Name() string {
+	return "slack"
+}
+
+func (s *SlackSink) Send(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, s.client, s.url, map[string]string{"text": alert.Text()})
+}
+
+// TelegramSink sends the alert text to a chat through the telegram bot API
+type TelegramSink struct {
+	url    string
+	chatId string
+	client HTTPDoer
+}
+
+func NewTelegramSink(apiUrl, botToken, chatId string, client HTTPDoer) (*TelegramSink, error) {
+	if apiUrl == "" || botToken == "" || chatId == "" || client == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewTelegramSink")
+	}
+
+	return &TelegramSink{
+		url:    fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimSuffix(apiUrl, "/"), botToken),
+		chatId: chatId,
+		client: client,
+	}, nil
+}
+
+func (t *TelegramSink) Name() string {
+	return "telegram"
+}
+
+func (t *TelegramSink) Send(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, t.client, t.url, map[string]string{"chat_id": t.chatId, "text": alert.Text()})
+}
+
+func postJSON(ctx context.Context, client HTTPDoer, target string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshalling alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		//the url may hold a secret (e.g. the telegram token), it is left out of the error
+		return fmt.Errorf("error building alert request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending alert: %w", unwrapUrlErr(err))
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("received non-OK status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// unwrapUrlErr drops the url from the errors of the http client
+func unwrapUrlErr(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+
+	return err
+}
@@ -0,0 +1,191 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/alerting/alertingtest"
+)
+
+const testBotToken = "123456:secret-token"
+
+var testAlert = Alert{
+	Check:   "market:ubze/uusdc",
+	Status:  StatusUnhealthy,
+	Message: "no trades found",
+	Time:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+}
+
+// sentJSON returns the single request received by the recorder and its decoded body
+func sentJSON(t *testing.T, rec *alertingtest.Recorder) (alertingtest.Request, map[string]any) {
+	t.Helper()
+
+	requests := rec.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	req := requests[0]
+	if req.Method != http.MethodPost {
+		t.Errorf("expected method POST, got %s", req.Method)
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected content type application/json, got %q", ct)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		t.Fatalf("invalid JSON body %q: %v", req.Body, err)
+	}
+
+	return req, body
+}
+
+func TestWebhookSinkSendsTheAlert(t *testing.T) {
+	rec := alertingtest.NewRecorder(http.StatusNoContent)
+	sink, err := NewWebhookSink("https://hooks.example.com/alerts", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Send(context.Background(), testAlert); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req, body := sentJSON(t, rec)
+	if req.Url != "https://hooks.example.com/alerts" {
+		t.Errorf("unexpected url %s", req.Url)
+	}
+
+	expected := map[string]any{
+		"check":    testAlert.Check,
+		"status":   testAlert.Status,
+		"message":  testAlert.Message,
+		"time":     "2024-05-01T10:00:00Z",
+		"repeated": false,
+	}
+	for key, value := range expected {
+		if body[key] != value {
+			t.Errorf("expected %s %v, got %v", key, value, body[key])
+		}
+	}
+	if len(body) != len(expected) {
+		t.Errorf("unexpected payload %v", body)
+	}
+}
+
+func TestSlackSinkSendsTheText(t *testing.T) {
+	rec := alertingtest.NewRecorder(http.StatusOK)
+	sink, err := NewSlackSink("https://hooks.slack.com/services/T/B/X", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repeated := testAlert
+	repeated.Repeated = true
+	if err := sink.Send(context.Background(), repeated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req, body := sentJSON(t, rec)
+	if req.Url != "https://hooks.slack.com/services/T/B/X" {
+		t.Errorf("unexpected url %s", req.Url)
+	}
+
+	text := "[bze-aggregator] market:ubze/uusdc is still unhealthy: no trades found"
+	if len(body) != 1 || body["text"] != text {
+		t.Errorf("expected only the text %q, got %v", text, body)
+	}
+}
+
+func TestTelegramSinkSendsTheTextToTheChat(t *testing.T) {
+	rec := alertingtest.NewRecorder(http.StatusOK)
+	sink, err := NewTelegramSink("https://api.telegram.org/", testBotToken, "-100200", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Send(context.Background(), testAlert); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req, body := sentJSON(t, rec)
+	if req.Url != "https://api.telegram.org/bot"+testBotToken+"/sendMessage" {
+		t.Errorf("unexpected url %s", req.Url)
+	}
+
+	text := "[bze-aggregator] market:ubze/uusdc is unhealthy: no trades found"
+	if len(body) != 2 || body["chat_id"] != "-100200" || body["text"] != text {
+		t.Errorf("unexpected payload %v", body)
+	}
+}
+
+func TestTelegramSinkKeepsTheTokenOutOfErrors(t *testing.T) {
+	//the http client wraps its errors in a *url.Error holding the url, and so the token
+	failing := alertingtest.NewFailingRecorder(&url.Error{
+		Op:  "Post",
+		URL: "https://api.telegram.org/bot" + testBotToken + "/sendMessage",
+		Err: errors.New("connection refused"),
+	})
+	cases := map[string]HTTPDoer{
+		"unreachable": failing,
+		"non-2xx":     alertingtest.NewRecorder(http.StatusUnauthorized),
+	}
+
+	for name, client := range cases {
+		t.Run(name, func(t *testing.T) {
+			sink, err := NewTelegramSink("https://api.telegram.org", testBotToken, "-100200", client)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = sink.Send(context.Background(), testAlert)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if strings.Contains(err.Error(), testBotToken) {
+				t.Errorf("the error leaks the bot token: %v", err)
+			}
+		})
+	}
+
+	//a url that can not be parsed must not leak the token either
+	sink, err := NewTelegramSink("https://api.telegram.org\x7f", testBotToken, "-100200", alertingtest.NewRecorder(http.StatusOK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sink.Send(context.Background(), testAlert); err == nil || strings.Contains(err.Error(), testBotToken) {
+		t.Errorf("expected an error without the bot token, got %v", err)
+	}
+}
+
+func TestSinksFailOnNon2xx(t *testing.T) {
+	for _, status := range []int{http.StatusMovedPermanently, http.StatusBadRequest, http.StatusTooManyRequests, http.StatusInternalServerError} {
+		rec := alertingtest.NewRecorder(status)
+		webhook, _ := NewWebhookSink("https://hooks.example.com/alerts", rec)
+		slack, _ := NewSlackSink("https://hooks.slack.com/services/T/B/X", rec)
+		telegram, _ := NewTelegramSink("https://api.telegram.org", testBotToken, "-100200", rec)
+
+		for _, sink := range []Sink{webhook, slack, telegram} {
+			err := sink.Send(context.Background(), testAlert)
+			if err == nil {
+				t.Errorf("%s: expected an error for status %d", sink.Name(), status)
+			}
+		}
+	}
+}
+
+func TestSinksFailWhenUnreachable(t *testing.T) {
+	rec := alertingtest.NewFailingRecorder(errors.New("connection refused"))
+	sink, _ := NewWebhookSink("https://hooks.example.com/alerts", rec)
+
+	if err := sink.Send(context.Background(), testAlert); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected the connection error, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package alerting
+
+import (
+	"context"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+type eventReader interface {
+	GetCurrent(ctx context.Context) ([]entity.HealthEvent, error)
+	GetLatest(ctx context.Context, checkName string, limit int) ([]entity.HealthEvent, error)
+}
+
+// Status reads the state of the checks saved by the Evaluator
+type Status struct {
+	storage eventReader
+}
+
+func NewStatus(storage eventReader) (*Status, error) {
+	if storage == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewStatus")
+	}
+
+	return &Status{storage: storage}, nil
+}
+
+// GetStatus returns the current status of every check and the latest limit events, of the check when it is not empty
+func (s *Status) GetStatus(ctx context.Context, checkName string, limit int) (*response.HealthStatus, error) {
+	current, err := s.storage.GetCurrent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.storage.GetLatest(ctx, checkName, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &response.HealthStatus{
+		IsHealthy: true,
+		Checks:    []response.HealthCheckStatus{},
+		History:   []response.HealthEvent{},
+	}
+
+	for _, event := range current {
+		status := StatusHealthy
+		if event.Status == StatusUnhealthy {
+			status = StatusUnhealthy
+			result.IsHealthy = false
+		}
+
+		result.Checks = append(result.Checks, response.HealthCheckStatus{
+			Check:   event.CheckName,
+			Status:  status,
+			Message: event.Message,
+			Since:   event.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	for _, event := range history {
+		result.History = append(result.History, response.HealthEvent{
+			Check:   event.CheckName,
+			Status:  event.Status,
+			Message: event.Message,
+			Time:    event.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return result, nil
+}
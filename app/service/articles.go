@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/mmcdole/gofeed"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	articleContentLimit = 150
+
+	articlesCacheKey = "articles:list"
+)
+
+var imgRegex = regexp.MustCompile(`<img[^>]+src="([^">]+)"`)
+
+type articleStorage interface {
+	Save(ctx context.Context, items []*entity.Article) error
+	Find(ctx context.Context, source, tag string, limit, offset int) ([]entity.Article, error)
+}
+
+// Articles reads the articles of the configured feeds and saves them, so they are still served when a feed is down
+type Articles struct {
+	logger  logrus.FieldLogger
+	cache   Cache
+	storage articleStorage
+
+	htmlPolicy *bluemonday.Policy
+	httpClient *http.Client
+	feeds      []config.ArticleFeed
+	cacheTtl   time.Duration
+}
+
+func NewArticlesService(logger logrus.FieldLogger, cache Cache, storage articleStorage, feeds []config.ArticleFeed, cacheTtl, httpTimeout time.Duration) (*Articles, error) {
+	if logger == nil || cache == nil || storage == nil || len(feeds) == 0 {
+		return nil, internal.NewInvalidDependenciesErr("NewArticlesService")
+	}
+
+	return &Articles{
+		logger:     logger.WithField("service", "Service.Articles"),
+		cache:      cache,
+		storage:    storage,
+		htmlPolicy: bluemonday.StrictPolicy(),
+		httpClient: tracing.NewHTTPClient(httpTimeout),
+		feeds:      feeds,
+		cacheTtl:   cacheTtl,
+	}, nil
+}
+
+// GetArticles returns a page of the saved articles, latest first, optionally only the ones of a source or with a tag.
+// The feeds are read by Refresh, out of the requests.
+func (a *Articles) GetArticles(ctx context.Context, source, tag string, page, limit int) ([]dto.Article, error) {
+	if source != "" && !slices.ContainsFunc(a.feeds, func(f config.ArticleFeed) bool { return f.Name == source }) {
+		return nil, internal.NewValidationErr(fmt.Sprintf("unknown source %s. expected one of: %s", source, strings.Join(a.sourceNames(), ", ")))
+	}
+
+	cacheKey := fmt.Sprintf("%s:%s:%s:%d:%d", articlesCacheKey, source, tag, page, limit)
+	cacheValue, err := a.cache.Get(cacheKey)
+	if err != nil {
+		a.logger.Errorf("failed to get articles from cache: %v", err)
+	}
+
+	if cacheValue != nil {
+		var articles []dto.Article
+		if err = json.Unmarshal(cacheValue, &articles); err == nil {
+			return articles, nil
+		}
+		a.logger.Errorf("failed to unmarshal articles from cache: %v", err)
+	}
+
+	items, err := a.storage.Find(ctx, source, tag, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+
+	articles := make([]dto.Article, 0, len(items))
+	for _, item := range items {
+		articles = append(articles, toArticleDto(item))
+	}
+
+	encoded, err := json.Marshal(articles)
+	if err == nil {
+		err = a.cache.Set(cacheKey, encoded, a.cacheTtl)
+	}
+	if err != nil {
+		a.logger.Errorf("failed to cache articles: %v", err)
+	}
+
+	return articles, nil
+}
+
+// Refresh reads all the feeds and saves their articles. A failing feed does not stop the others, it fails only
+// when no feed could be read.
+func (a *Articles) Refresh(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("articles", start, err) }()
+
+	fetchedAt := start.UTC()
+	seen := make(map[string]bool)
+	var items []*entity.Article
+	failed := 0
+	for _, feed := range a.feeds {
+		fetched, err := a.fetchFeed(ctx, feed, fetchedAt)
+		if err != nil {
+			a.logger.WithField("source", feed.Name).Errorf("failed to fetch articles from %s: %v", feed.Url, err)
+			failed++
+
+			continue
+		}
+
+		for _, item := range fetched {
+			if seen[item.UrlKey] {
+				continue
+			}
+			seen[item.UrlKey] = true
+			items = append(items, item)
+		}
+	}
+
+	if failed == len(a.feeds) {
+		return fmt.Errorf("could not read any of the %d feeds", failed)
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	return a.storage.Save(ctx, items)
+}
+
+func (a *Articles) fetchFeed(ctx context.Context, feed config.ArticleFeed, fetchedAt time.Time) ([]*entity.Article, error) {
+	fp := gofeed.NewParser()
+	fp.Client = a.httpClient
+	parsed, err := fp.ParseURLWithContext(feed.Url, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*entity.Article
+	for _, item := range parsed.Items {
+		key := articleUrlKey(item.Link)
+		if key == "" {
+			continue
+		}
+
+		content := item.Content
+		if content == "" {
+			content = item.Description
+		}
+
+		authorName := ""
+		if len(item.Authors) > 0 {
+			authorName = item.Authors[0].Name
+		}
+
+		picture := extractFirstImageUrl(content)
+		if picture == "" && item.Image != nil {
+			picture = item.Image.URL
+		}
+
+		result = append(result, &entity.Article{
+			UrlKey:      key,
+			Source:      feed.Name,
+			Title:       item.Title,
+			Url:         item.Link,
+			PictureUrl:  picture,
+			Description: summarize(a.htmlPolicy.Sanitize(content), articleContentLimit),
+			AuthorName:  authorName,
+			Tags:        joinTags(item.Categories),
+			PublishedAt: publishDate(item, fetchedAt),
+			FetchedAt:   fetchedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (a *Articles) sourceNames() []string {
+	var result []string
+	for _, feed := range a.feeds {
+		result = append(result, feed.Name)
+	}
+
+	return result
+}
+
+func toArticleDto(item entity.Article) dto.Article {
+	tags := []string{}
+	for _, tag := range strings.Split(item.Tags, ",") {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return dto.Article{
+		Title:       item.Title,
+		URL:         item.Url,
+		PictureURL:  item.PictureUrl,
+		Description: item.Description,
+		PublishDate: item.PublishedAt,
+		AuthorName:  item.AuthorName,
+		Source:      item.Source,
+		Tags:        tags,
+	}
+}
+
+// articleUrlKey returns the link without query and fragment (e.g. medium adds ?source=rss), empty for invalid links
+func articleUrlKey(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	return strings.ToLower(u.Host) + strings.TrimSuffix(u.Path, "/")
+}
+
+// summarize collapses the whitespace of the plain text and cuts it to limit characters
+func summarize(text string, limit int) string {
+	text = strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+
+	return strings.TrimSpace(string([]rune(text)[:limit])) + "..."
+}
+
+// joinTags returns the unique lower case tags wrapped in commas, see entity.Article
+func joinTags(categories []string) string {
+	var tags []string
+	for _, category := range categories {
+		tag := strings.ToLower(strings.TrimSpace(strings.ReplaceAll(category, ",", " ")))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	if len(tags) == 0 {
+		return ""
+	}
+
+	return "," + strings.Join(tags, ",") + ","
+}
+
+func publishDate(item *gofeed.Item, fallback time.Time) time.Time {
+	if item.PublishedParsed != nil {
+		return item.PublishedParsed.UTC()
+	}
+
+	if item.UpdatedParsed != nil {
+		return item.UpdatedParsed.UTC()
+	}
+
+	return fallback
+}
+
+// extractFirstImageUrl returns the first CDN image of the content, other images are usually tracking pixels
+func extractFirstImageUrl(content string) string {
+	var foundUrl string
+	matches := imgRegex.FindStringSubmatch(content)
+	if len(matches) > 1 {
+		// The second element in the matches slice is the captured src URL
+		foundUrl = matches[1]
+	}
+
+	if strings.Contains(foundUrl, "https://cdn") {
+		return foundUrl
+	}
+
+	return ""
+}
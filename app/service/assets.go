@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/chain_registry"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type assetsRegistry interface {
+	GetAssets(ctx context.Context) ([]chain_registry.ChainRegistryAsset, error)
+	GetAssetDetailsOrDefault(ctx context.Context, denom string) (*chain_registry.ChainRegistryAsset, error)
+}
+
+type assetsMarketStorage interface {
+	GetMarkets(ctx context.Context) ([]entity.Market, error)
+}
+
+// Assets exposes the metadata the aggregator uses for the chain registry assets and the denoms of the markets
+type Assets struct {
+	logger   logrus.FieldLogger
+	registry assetsRegistry
+	markets  assetsMarketStorage
+}
+
+func NewAssetsService(logger logrus.FieldLogger, registry assetsRegistry, markets assetsMarketStorage) (*Assets, error) {
+	if logger == nil || registry == nil || markets == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewAssetsService")
+	}
+
+	return &Assets{
+		logger:   logger.WithField("service", "Service.Assets"),
+		registry: registry,
+		markets:  markets,
+	}, nil
+}
+
+// GetAssets returns the chain registry assets followed by the denoms of the markets missing from it, resolved
+// through their IBC trace or given the default exponent
+func (a *Assets) GetAssets(ctx context.Context) ([]response.Asset, error) {
+	assets, err := a.registry.GetAssets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	markets, err := a.markets.GetMarkets(ctx)
+	if err != nil {
+		return nil, internal.NewUnavailableErr("could not get the markets", err)
+	}
+
+	known := make(map[string]bool, len(assets))
+	result := make([]response.Asset, 0, len(assets))
+	for _, asset := range assets {
+		known[asset.Base] = true
+		result = append(result, newAssetResponse(&asset))
+	}
+
+	var missing []string
+	for _, m := range markets {
+		for _, denom := range []string{m.Base, m.Quote} {
+			if !known[denom] {
+				known[denom] = true
+				missing = append(missing, denom)
+			}
+		}
+	}
+	slices.Sort(missing)
+
+	for _, denom := range missing {
+		asset, err := a.registry.GetAssetDetailsOrDefault(ctx, denom)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve %s: %w", denom, err)
+		}
+
+		result = append(result, newAssetResponse(asset))
+	}
+
+	return result, nil
+}
+
+func newAssetResponse(asset *chain_registry.ChainRegistryAsset) response.Asset {
+	result := response.Asset{
+		Denom:   asset.Base,
+		Name:    asset.Name,
+		Symbol:  asset.Symbol,
+		Display: asset.Display,
+		Source:  asset.Source,
+	}
+
+	if display := asset.GetDisplayDenomUnit(); display != nil {
+		result.Exponent = display.Exponent
+	}
+
+	for _, trace := range asset.Traces {
+		if trace.Type == chain_registry.TraceTypeIbc {
+			result.Ibc = &response.AssetIbcTrace{
+				Path:      trace.Chain.Path,
+				BaseDenom: trace.Counterparty.BaseDenom,
+				ChainName: trace.Counterparty.ChainName,
+			}
+		}
+	}
+
+	return result
+}
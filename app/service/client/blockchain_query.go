@@ -1,13 +1,19 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
+	"cosmossdk.io/math"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/nodepool"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	cmtjson "github.com/cometbft/cometbft/libs/json"
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
@@ -15,161 +21,302 @@ import (
 
 const (
 	supplyPath        = "/cosmos/bank/v1beta1/supply"
+	supplyByDenomPath = "/cosmos/bank/v1beta1/supply/by_denom?denom=%s"
+	supplyPageLimit   = 1000
 	communityPoolPath = "/cosmos/distribution/v1beta1/community_pool"
 	marketHistoryPath = "/bze/tradebin/market_history"
 	latestBlockPath   = "/cosmos/base/tendermint/v1beta1/blocks/latest"
+	balancePath       = "/cosmos/bank/v1beta1/balances/%s/by_denom?denom=%s"
+	moduleAccountPath = "/cosmos/auth/v1beta1/module_accounts/%s"
+	denomTracePath    = "/ibc/apps/transfer/v1/denom_traces/%s"
 )
 
 type supplyResponse struct {
-	Amount []dto.Coin `json:"supply"`
+	Amount     []dto.Coin `json:"supply"`
+	Pagination struct {
+		NextKey string `json:"next_key"`
+	} `json:"pagination"`
+}
+
+type supplyByDenomResponse struct {
+	Amount dto.Coin `json:"amount"`
 }
 
 type communityPoolResponse struct {
 	Pool []dto.Coin `json:"pool"`
 }
 
+type balanceResponse struct {
+	Balance dto.Coin `json:"balance"`
+}
+
+type moduleAccountResponse struct {
+	Account struct {
+		BaseAccount struct {
+			Address string `json:"address"`
+		} `json:"base_account"`
+	} `json:"account"`
+}
+
+type denomTraceResponse struct {
+	DenomTrace dto.DenomTrace `json:"denom_trace"`
+}
+
+type latestBlockResponse struct {
+	Block struct {
+		Header struct {
+			Height string `json:"height"`
+		} `json:"header"`
+	} `json:"block"`
+}
+
 type BlockchainQueryClient struct {
-	Host string
+	nodes      *nodepool.Pool
+	httpClient *http.Client
 }
 
-func NewBlockchainQueryClient(host string) (*BlockchainQueryClient, error) {
-	if len(host) == 0 {
+func NewBlockchainQueryClient(nodes *nodepool.Pool, timeout time.Duration) (*BlockchainQueryClient, error) {
+	if nodes == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewBlockchainQueryClient")
 	}
 
-	return &BlockchainQueryClient{host}, nil
+	return &BlockchainQueryClient{nodes: nodes, httpClient: tracing.NewHTTPClient(timeout)}, nil
+}
+
+// NewRestProber returns a nodepool.Prober reading the latest block height of a REST node
+func NewRestProber(timeout time.Duration) nodepool.Prober {
+	httpClient := tracing.NewHTTPClient(timeout)
+
+	return func(ctx context.Context, host string) (int64, error) {
+		body, err := getBody(ctx, httpClient, fmt.Sprintf("%s%s", host, latestBlockPath))
+		if err != nil {
+			return 0, err
+		}
+
+		var data latestBlockResponse
+		err = json.Unmarshal(body, &data)
+		if err != nil {
+			return 0, fmt.Errorf("error unmarshalling latest block: %w", err)
+		}
+
+		return strconv.ParseInt(data.Block.Header.Height, 10, 64)
+	}
+}
+
+// get calls the path on the best REST node, moving to the next node when the request or the node fails
+func (c *BlockchainQueryClient) get(ctx context.Context, path string) (body []byte, err error) {
+	err = c.nodes.Do(ctx, func(ctx context.Context, host string) error {
+		body, err = getBody(ctx, c.httpClient, fmt.Sprintf("%s%s", host, path))
+
+		return err
+	})
+
+	return body, err
 }
 
-func (c *BlockchainQueryClient) GetTotalSupply(denom string) (int64, error) {
-	url := fmt.Sprintf("%s%s", c.Host, supplyPath)
-	resp, err := http.Get(url)
+// getBody returns the body of a successful response. Client errors (4xx except 429) are marked as permanent
+// since asking another node would not change the result, the others mean the node is unavailable.
+func getBody(ctx context.Context, httpClient *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
+		return nil, nodepool.Permanent(err)
+	}
 
-		return 0, fmt.Errorf("error making request to Cosmos SDK: %w", err)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, internal.NewUnavailableErr("blockchain node unavailable", fmt.Errorf("error making request to Cosmos SDK: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("received non-OK status code: %d", resp.StatusCode)
+		if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, nodepool.Permanent(err)
+		}
 
-		return 0, fmt.Errorf("received non-OK status code: %d", resp.StatusCode)
+		return nil, internal.NewUnavailableErr("blockchain node unavailable", err)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
+		return nil, internal.NewUnavailableErr("blockchain node unavailable", fmt.Errorf("error reading response body: %w", err))
+	}
+
+	return body, nil
+}
 
-		return 0, fmt.Errorf("error reading response body: %w", err)
+// GetTotalSupply returns the total supply of the denom, 0 when the chain does not know it
+func (c *BlockchainQueryClient) GetTotalSupply(ctx context.Context, denom string) (math.Int, error) {
+	body, err := c.get(ctx, fmt.Sprintf(supplyByDenomPath, url.QueryEscape(denom)))
+	if err != nil {
+		return math.Int{}, err
 	}
 
-	var data supplyResponse
+	var data supplyByDenomResponse
 	err = json.Unmarshal(body, &data)
 	if err != nil {
-
-		return 0, fmt.Errorf("error unmarshalling response data: %w", err)
+		return math.Int{}, fmt.Errorf("error unmarshalling response data: %w", err)
 	}
 
-	for _, amt := range data.Amount {
-		if amt.Denom == denom {
-			totalSupply, err := strconv.ParseInt(amt.Amount, 10, 64)
-			if err != nil {
+	return parseInt(data.Amount.Amount, "total supply")
+}
 
-				return 0, fmt.Errorf("error parsing total supply: %w", err)
-			}
+// GetAllSupply returns the total supply of every denom, reading all the pages
+func (c *BlockchainQueryClient) GetAllSupply(ctx context.Context) (map[string]math.Int, error) {
+	result := make(map[string]math.Int)
+	nextKey := ""
+	for {
+		path := fmt.Sprintf("%s?pagination.limit=%d", supplyPath, supplyPageLimit)
+		if nextKey != "" {
+			path = fmt.Sprintf("%s&pagination.key=%s", path, url.QueryEscape(nextKey))
+		}
 
-			return totalSupply, nil
+		body, err := c.get(ctx, path)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	return 0, fmt.Errorf("denom %s not found", denom)
-}
+		var data supplyResponse
+		err = json.Unmarshal(body, &data)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling response data: %w", err)
+		}
 
-func (c *BlockchainQueryClient) GetCommunityPoolTotal(denom string) (float64, error) {
-	url := fmt.Sprintf("%s%s", c.Host, communityPoolPath)
-	resp, err := http.Get(url)
-	if err != nil {
-		return 0, fmt.Errorf("error making request to Cosmos SDK: %w", err)
-	}
-	defer resp.Body.Close()
+		for _, amt := range data.Amount {
+			result[amt.Denom], err = parseInt(amt.Amount, "total supply")
+			if err != nil {
+				return nil, err
+			}
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("received non-OK status code: %d", resp.StatusCode)
+		nextKey = data.Pagination.NextKey
+		if nextKey == "" {
+			return result, nil
+		}
 	}
+}
 
-	body, err := io.ReadAll(resp.Body)
+// GetCommunityPoolTotal returns the community pool funds of the denom, 0 when the pool has none
+func (c *BlockchainQueryClient) GetCommunityPoolTotal(ctx context.Context, denom string) (math.LegacyDec, error) {
+	body, err := c.get(ctx, communityPoolPath)
 	if err != nil {
-		return 0, fmt.Errorf("error reading response body: %w", err)
+		return math.LegacyDec{}, err
 	}
 
 	var data communityPoolResponse
 	err = json.Unmarshal(body, &data)
 	if err != nil {
-		return 0, fmt.Errorf("error unmarshalling response data: %w", err)
+		return math.LegacyDec{}, fmt.Errorf("error unmarshalling response data: %w", err)
 	}
 
 	for _, amt := range data.Pool {
 		if amt.Denom == denom {
-			totalAmount, err := strconv.ParseFloat(amt.Amount, 64)
+			totalAmount, err := math.LegacyNewDecFromStr(amt.Amount)
 			if err != nil {
-				return 0, fmt.Errorf("error parsing community pool total: %w", err)
+				return math.LegacyDec{}, fmt.Errorf("error parsing community pool total: %w", err)
 			}
+
 			return totalAmount, nil
 		}
 	}
 
-	return 0, fmt.Errorf("denom %s not found in community pool", denom)
+	return math.LegacyZeroDec(), nil
 }
 
-func (c *BlockchainQueryClient) GetMarketHistory(marketId string, limit int) ([]dto.HistoryOrder, error) {
-	url := c.getMarketHistoryUrl(marketId, limit)
-	resp, err := http.Get(url)
+// GetBalance returns the amount of the denom held by the address
+func (c *BlockchainQueryClient) GetBalance(ctx context.Context, address, denom string) (math.Int, error) {
+	body, err := c.get(ctx, fmt.Sprintf(balancePath, url.PathEscape(address), url.QueryEscape(denom)))
 	if err != nil {
-		return nil, fmt.Errorf("error making request to the blockchain: %w", err)
+		return math.Int{}, err
 	}
 
-	defer resp.Body.Close()
+	var data balanceResponse
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return math.Int{}, fmt.Errorf("error unmarshalling response data: %w", err)
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received non-OK status code: %d", resp.StatusCode)
+	return parseInt(data.Balance.Amount, "balance")
+}
+
+// parseInt parses an amount returned by the node, an empty amount is 0
+func parseInt(amount, name string) (math.Int, error) {
+	if amount == "" {
+		return math.ZeroInt(), nil
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	result, ok := math.NewIntFromString(amount)
+	if !ok {
+		return math.Int{}, fmt.Errorf("error parsing %s: invalid amount %q", name, amount)
+	}
+
+	return result, nil
+}
+
+// GetModuleAccountAddress returns the address of the module account with the given name (e.g. distribution)
+func (c *BlockchainQueryClient) GetModuleAccountAddress(ctx context.Context, name string) (string, error) {
+	body, err := c.get(ctx, fmt.Sprintf(moduleAccountPath, url.PathEscape(name)))
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return "", err
 	}
 
-	var data dto.HistoryResponse
+	var data moduleAccountResponse
 	err = json.Unmarshal(body, &data)
 	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling response data: %w", err)
+		return "", fmt.Errorf("error unmarshalling response data: %w", err)
 	}
 
-	return data.List, nil
-}
+	if data.Account.BaseAccount.Address == "" {
+		return "", fmt.Errorf("module account %s not found", name)
+	}
 
-func (c *BlockchainQueryClient) getMarketHistoryUrl(marketId string, limit int) string {
-	return fmt.Sprintf("%s%s?market=%s&pagination.limit=%d&pagination.reverse=true", c.Host, marketHistoryPath, marketId, limit)
+	return data.Account.BaseAccount.Address, nil
 }
 
-func (c *BlockchainQueryClient) GetLatestBlock() (*coretypes.ResultBlock, error) {
-	url := fmt.Sprintf("%s%s", c.Host, latestBlockPath)
-	resp, err := http.Get(url)
+// GetDenomTrace returns the IBC path and the base denom of the ibc/{hash} denom
+func (c *BlockchainQueryClient) GetDenomTrace(ctx context.Context, hash string) (*dto.DenomTrace, error) {
+	body, err := c.get(ctx, fmt.Sprintf(denomTracePath, url.PathEscape(hash)))
 	if err != nil {
-
-		return nil, fmt.Errorf("error making request to Cosmos SDK: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	var data denomTraceResponse
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling response data: %w", err)
+	}
 
-		return nil, fmt.Errorf("received non-OK status code: %d", resp.StatusCode)
+	if data.DenomTrace.BaseDenom == "" {
+		return nil, fmt.Errorf("denom trace %s not found", hash)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	return &data.DenomTrace, nil
+}
+
+func (c *BlockchainQueryClient) GetMarketHistory(ctx context.Context, marketId string, limit int) ([]dto.HistoryOrder, error) {
+	body, err := c.get(ctx, c.getMarketHistoryPath(marketId, limit))
 	if err != nil {
+		return nil, err
+	}
 
-		return nil, fmt.Errorf("error reading response body: %w", err)
+	var data dto.HistoryResponse
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling response data: %w", err)
 	}
 
-	fmt.Println(string(body))
+	return data.List, nil
+}
+
+func (c *BlockchainQueryClient) getMarketHistoryPath(marketId string, limit int) string {
+	return fmt.Sprintf("%s?market=%s&pagination.limit=%d&pagination.reverse=true", marketHistoryPath, marketId, limit)
+}
+
+func (c *BlockchainQueryClient) GetLatestBlock(ctx context.Context) (*coretypes.ResultBlock, error) {
+	body, err := c.get(ctx, latestBlockPath)
+	if err != nil {
+		return nil, err
+	}
 
 	var data coretypes.ResultBlock
 	err = cmtjson.Unmarshal(body, &data)
@@ -1,42 +1,52 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
-	"io/ioutil"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 const (
-	defaultPricesDenomination = "bzedge"
-	againstCurrency           = "usd"
-
-	pricesPath = "/api/v3/simple/price?ids=%s&vs_currencies=%s"
+	lastUpdatedAtKey = "last_updated_at"
+	pricesPath       = "/api/v3/simple/price?ids=%s&vs_currencies=%s&include_last_updated_at=true"
 )
 
 type Coingecko struct {
-	ids  string
 	host string
+
+	httpClient *http.Client
 }
 
-func NewCoingeckoClient(host, ids string) (*Coingecko, error) {
+func NewCoingeckoClient(host string, timeout time.Duration) (*Coingecko, error) {
 	if len(host) == 0 {
 		return nil, internal.NewInvalidDependenciesErr("NewCoingeckoClient")
 	}
 
-	//use default value if none provided
-	if len(ids) == 0 {
-		ids = defaultPricesDenomination
-	}
+	return &Coingecko{host: host, httpClient: tracing.NewHTTPClient(timeout)}, nil
+}
 
-	return &Coingecko{ids: ids, host: host}, nil
+func (c *Coingecko) Name() string {
+	return config.PriceSourceCoingecko
 }
 
-func (c *Coingecko) GetDenominationsPrices() ([]dto.CoinPrice, error) {
-	url := fmt.Sprintf("%s%s", c.host, fmt.Sprintf(pricesPath, c.ids, againstCurrency))
-	resp, err := http.Get(url)
+// GetPrices returns the prices of the coingecko ids in the given currencies
+func (c *Coingecko) GetPrices(ctx context.Context, ids, currencies []string) ([]dto.CoinPrice, error) {
+	path := fmt.Sprintf(pricesPath, url.QueryEscape(strings.Join(ids, ",")), url.QueryEscape(strings.Join(currencies, ",")))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.host, path), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error building coingecko request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error making request to coingecko: %w", err)
 	}
@@ -46,12 +56,12 @@ func (c *Coingecko) GetDenominationsPrices() ([]dto.CoinPrice, error) {
 		return nil, fmt.Errorf("received non-OK status code from coingeko: %d", resp.StatusCode)
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("error reading response body from coingecko: %w", err)
 	}
 
-	//do not use a structure because we might want to use Eur or other "against" currencies in the future
+	//id => currency => price, the last update time is one of the keys
 	data := make(map[string]map[string]float64)
 	err = json.Unmarshal(body, &data)
 	if err != nil {
@@ -60,18 +70,25 @@ func (c *Coingecko) GetDenominationsPrices() ([]dto.CoinPrice, error) {
 
 	var prices []dto.CoinPrice
 	for id, priceData := range data {
-		price, ok := priceData[againstCurrency]
-		if !ok {
-			continue
+		updatedAt := time.Now()
+		if unix, ok := priceData[lastUpdatedAtKey]; ok {
+			updatedAt = time.Unix(int64(unix), 0)
 		}
 
-		cp := dto.CoinPrice{
-			Denom:      id,
-			Price:      price,
-			PriceDenom: againstCurrency,
-		}
+		for _, currency := range currencies {
+			price, ok := priceData[currency]
+			if !ok {
+				continue
+			}
 
-		prices = append(prices, cp)
+			prices = append(prices, dto.CoinPrice{
+				Denom:      id,
+				Price:      price,
+				PriceDenom: currency,
+				Source:     config.PriceSourceCoingecko,
+				UpdatedAt:  updatedAt.UTC().Format(time.RFC3339),
+			})
+		}
 	}
 
 	return prices, nil
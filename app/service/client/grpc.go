@@ -1,19 +1,33 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"strconv"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/nodepool"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
 	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	cointrunkTypes "github.com/bze-alphateam/bze/x/cointrunk/types"
 	tradebinTypes "github.com/bze-alphateam/bze/x/tradebin/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"time"
 )
 
 const (
 	lockName = "grpc:client:connection"
+
+	// blockHeightHeader is set by the cosmos-sdk gRPC server on every query response
+	blockHeightHeader = "x-cosmos-block-height"
 )
 
 type ConnectionLocker interface {
@@ -21,16 +35,20 @@ type ConnectionLocker interface {
 	Unlock(key string)
 }
 
+// GrpcClient keeps a connection to every gRPC node and sends each call to the best node of its pool,
+// retrying on another node when the call fails because of the node.
 type GrpcClient struct {
-	host   string
+	nodes  *nodepool.Pool
 	locker ConnectionLocker
-	conn   *grpc.ClientConn
+	conns  map[string]*grpc.ClientConn
 	logger logrus.FieldLogger
 	useTLS bool
+
+	timeout time.Duration
 }
 
 func NewGrpcClient(cfg *config.AppConfig, locker ConnectionLocker, logger logrus.FieldLogger) (*GrpcClient, error) {
-	if cfg.Blockchain.GrpcHost == "" {
+	if len(cfg.Blockchain.GrpcHosts()) == 0 {
 		return nil, fmt.Errorf("grpc host is required")
 	}
 
@@ -42,12 +60,36 @@ func NewGrpcClient(cfg *config.AppConfig, locker ConnectionLocker, logger logrus
 		return nil, fmt.Errorf("grpc client requires logger")
 	}
 
-	return &GrpcClient{
-		host:   cfg.Blockchain.GrpcHost,
+	c := &GrpcClient{
 		locker: locker,
+		conns:  make(map[string]*grpc.ClientConn),
 		logger: logger,
 		useTLS: cfg.Blockchain.UseGrpcTls,
-	}, nil
+
+		timeout: config.Seconds(cfg.Timeouts.GrpcSeconds),
+	}
+
+	nodes, err := nodepool.NewPool("grpc", cfg.Blockchain.GrpcHosts(), c.probe, cfg.NodePool, logger)
+	if err != nil {
+		return nil, err
+	}
+	c.nodes = nodes
+
+	return c, nil
+}
+
+// Nodes returns the pool of gRPC nodes used by the client
+func (c *GrpcClient) Nodes() *nodepool.Pool {
+	return c.nodes
+}
+
+// timeoutInterceptor bounds every unary call to the configured timeout. A shorter deadline already set
+// on the call context (e.g. by the API request) still wins.
+func (c *GrpcClient) timeoutInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return invoker(ctx, method, req, reply, cc, opts...)
 }
 
 // LoadTLSCredentials loads TLS credentials with ALPN support for HTTP/2
@@ -69,23 +111,21 @@ func (c *GrpcClient) loadTLSCredentials() (credentials.TransportCredentials, err
 	return credentials.NewTLS(tlsConfig), nil
 }
 
-func (c *GrpcClient) getConnection() (*grpc.ClientConn, error) {
+func (c *GrpcClient) getConnection(host string) (*grpc.ClientConn, error) {
 	//make it thread safe
 	c.locker.Lock(lockName)
 	defer c.locker.Unlock(lockName)
-	if c.conn != nil && c.conn.GetState() == connectivity.Ready {
-		c.logger.Debug("grpc client connection ready")
-
-		return c.conn, nil
-	}
+	l := c.logger.WithField("host", host)
+	conn := c.conns[host]
+	if conn != nil && conn.GetState() != connectivity.Shutdown {
+		l.Debug("grpc client connection ready")
 
-	if c.conn != nil {
-		c.logger.Info("grpc client connection exists with status:", c.conn.GetState().String())
+		return conn, nil
 	}
 
-	c.logger.Info("connecting to grpc host:", c.host)
+	l.Info("connecting to grpc host")
 
-	var dialOptions []grpc.DialOption
+	dialOptions := []grpc.DialOption{tracing.GrpcDialOption(), grpc.WithUnaryInterceptor(c.timeoutInterceptor)}
 	if c.useTLS {
 		cred, err := c.loadTLSCredentials()
 		if err != nil {
@@ -98,7 +138,7 @@ func (c *GrpcClient) getConnection() (*grpc.ClientConn, error) {
 	}
 
 	grpcConn, err := grpc.Dial(
-		c.host,
+		host,
 		dialOptions...,
 	)
 
@@ -106,24 +146,102 @@ func (c *GrpcClient) getConnection() (*grpc.ClientConn, error) {
 		return nil, err
 	}
 
-	c.conn = grpcConn
+	c.conns[host] = grpcConn
 
 	return grpcConn, nil
 }
 
-func (c *GrpcClient) GetTradebinQueryClient() (tradebinTypes.QueryClient, error) {
-	grpcConn, err := c.getConnection()
+// Invoke sends the unary call to the best node, retrying on the next one when the node is unavailable.
+// Implementing the connection interface lets the generated query clients fail over transparently.
+func (c *GrpcClient) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	return c.nodes.Do(ctx, func(ctx context.Context, host string) error {
+		conn, err := c.getConnection(host)
+		if err != nil {
+			return err
+		}
+
+		var header metadata.MD
+		err = conn.Invoke(ctx, method, args, reply, append(opts, grpc.Header(&header))...)
+		if err != nil {
+			if isNodeFailure(err) {
+				return err
+			}
+
+			return nodepool.Permanent(err)
+		}
+
+		if height, ok := headerHeight(header); ok {
+			c.nodes.SetHeight(host, height)
+		}
+
+		return nil
+	})
+}
+
+// NewStream opens the stream on the best node. Streams are not retried.
+func (c *GrpcClient) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	conn, err := c.getConnection(c.nodes.Hosts()[0])
 	if err != nil {
 		return nil, err
 	}
 
-	queryClient := tradebinTypes.NewQueryClient(grpcConn)
+	return conn.NewStream(ctx, desc, method, opts...)
+}
+
+func (c *GrpcClient) GetTradebinQueryClient() (tradebinTypes.QueryClient, error) {
+	return tradebinTypes.NewQueryClient(c), nil
+}
+
+func (c *GrpcClient) GetCointrunkQueryClient() (cointrunkTypes.QueryClient, error) {
+	return cointrunkTypes.NewQueryClient(c), nil
+}
+
+// probe reads the height of the node from the headers of a cheap tradebin query
+func (c *GrpcClient) probe(ctx context.Context, host string) (int64, error) {
+	conn, err := c.getConnection(host)
+	if err != nil {
+		return 0, err
+	}
+
+	var header metadata.MD
+	params := &tradebinTypes.QueryAllMarketsRequest{Pagination: &query.PageRequest{Limit: 1}}
+	_, err = tradebinTypes.NewQueryClient(conn).AllMarkets(ctx, params, grpc.Header(&header))
+	if err != nil {
+		return 0, err
+	}
+
+	height, _ := headerHeight(header)
 
-	return queryClient, nil
+	return height, nil
 }
 
 func (c *GrpcClient) CloseConnection() {
-	if c.conn != nil {
-		_ = c.conn.Close()
+	c.locker.Lock(lockName)
+	defer c.locker.Unlock(lockName)
+
+	for host, conn := range c.conns {
+		_ = conn.Close()
+		delete(c.conns, host)
+	}
+}
+
+func headerHeight(header metadata.MD) (int64, bool) {
+	values := header.Get(blockHeightHeader)
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	height, err := strconv.ParseInt(values[0], 10, 64)
+
+	return height, err == nil
+}
+
+// isNodeFailure tells if the call may succeed on another node
+func isNodeFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
 	}
 }
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+)
+
+const spotPricePath = "/osmosis/poolmanager/v1beta1/pools/%s/prices?base_asset_denom=%s&quote_asset_denom=%s"
+
+type spotPriceResponse struct {
+	SpotPrice string `json:"spot_price"`
+}
+
+// Osmosis reads the prices from the spot price of osmosis pools. The spot price is the ratio of the base units,
+// so the base and the quote of a pool must have the same exponent.
+type Osmosis struct {
+	host  string
+	pools []config.PriceSourceEntry
+
+	httpClient *http.Client
+}
+
+func NewOsmosisClient(host string, pools []config.PriceSourceEntry, timeout time.Duration) (*Osmosis, error) {
+	if len(host) == 0 {
+		return nil, internal.NewInvalidDependenciesErr("NewOsmosisClient")
+	}
+
+	return &Osmosis{host: host, pools: pools, httpClient: tracing.NewHTTPClient(timeout)}, nil
+}
+
+func (o *Osmosis) Name() string {
+	return config.PriceSourceOsmosis
+}
+
+// GetPrices returns the prices of the configured pools matching the ids and currencies. A failing pool does not
+// stop the others, its error is returned together with the prices found.
+func (o *Osmosis) GetPrices(ctx context.Context, ids, currencies []string) ([]dto.CoinPrice, error) {
+	var prices []dto.CoinPrice
+	var errs []error
+	for _, pool := range o.pools {
+		if !slices.Contains(ids, pool.Id) || !slices.Contains(currencies, pool.Currency) {
+			continue
+		}
+
+		price, err := o.getSpotPrice(ctx, pool.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%s: %w", pool.Id, pool.Currency, err))
+
+			continue
+		}
+
+		prices = append(prices, dto.CoinPrice{
+			Denom:      pool.Id,
+			Price:      price,
+			PriceDenom: pool.Currency,
+			Source:     config.PriceSourceOsmosis,
+			UpdatedAt:  time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+
+	return prices, errors.Join(errs...)
+}
+
+// getSpotPrice reads the price of a pool configured as pool_id:base_denom:quote_denom
+func (o *Osmosis) getSpotPrice(ctx context.Context, pool string) (float64, error) {
+	parts := strings.Split(pool, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid pool %q", pool)
+	}
+
+	path := fmt.Sprintf(spotPricePath, url.PathEscape(parts[0]), url.QueryEscape(parts[1]), url.QueryEscape(parts[2]))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%s", o.host, path), nil)
+	if err != nil {
+		return 0, fmt.Errorf("error building osmosis request: %w", err)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error making request to osmosis: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("received non-OK status code from osmosis: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error reading response body from osmosis: %w", err)
+	}
+
+	var data spotPriceResponse
+	if err = json.Unmarshal(body, &data); err != nil {
+		return 0, fmt.Errorf("error unmarshalling response data from osmosis: %w", err)
+	}
+
+	return strconv.ParseFloat(data.SpotPrice, 64)
+}
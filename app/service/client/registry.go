@@ -1,47 +1,124 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/chain_registry"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"io"
 	"net/http"
+	"os"
+	"time"
 )
 
-const (
-	assetListUrl = "https://raw.githubusercontent.com/faneaatiku/chain-registry/refs/heads/master/beezee/assetlist.json"
-)
-
+// ChainRegistry reads an assetlist.json from an url, a local file or both. When both are used the local assets
+// override the remote assets with the same base denom.
 type ChainRegistry struct {
+	httpClient    *http.Client
+	assetListUrl  string
+	assetListFile string
 }
 
-func NewChainRegistry() (*ChainRegistry, error) {
-	return &ChainRegistry{}, nil
+func NewChainRegistry(assetListUrl, assetListFile string, timeout time.Duration) (*ChainRegistry, error) {
+	if assetListUrl == "" && assetListFile == "" {
+		return nil, internal.NewInvalidDependenciesErr("NewChainRegistry")
+	}
+
+	return &ChainRegistry{httpClient: tracing.NewHTTPClient(timeout), assetListUrl: assetListUrl, assetListFile: assetListFile}, nil
 }
 
-func (r ChainRegistry) GetAssetList() (*chain_registry.ChainRegistryAssetList, error) {
-	resp, err := http.Get(assetListUrl)
+func (r ChainRegistry) GetAssetList(ctx context.Context) (*chain_registry.ChainRegistryAssetList, error) {
+	if r.assetListUrl == "" {
+		return r.getLocalAssetList()
+	}
+
+	remote, err := r.getRemoteAssetList(ctx)
+	if err != nil || r.assetListFile == "" {
+		return remote, err
+	}
+
+	local, err := r.getLocalAssetList()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch JSON: %w", err)
+		return nil, err
+	}
+
+	return mergeAssetLists(remote, local), nil
+}
+
+func (r ChainRegistry) getRemoteAssetList(ctx context.Context) (*chain_registry.ChainRegistryAssetList, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.assetListUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, internal.NewUnavailableErr("chain registry unavailable", fmt.Errorf("failed to fetch JSON: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch JSON: received status %d", resp.StatusCode)
+		return nil, internal.NewUnavailableErr("chain registry unavailable", fmt.Errorf("failed to fetch JSON: received status %d", resp.StatusCode))
 	}
 
 	// Read the response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, internal.NewUnavailableErr("chain registry unavailable", fmt.Errorf("failed to read response body: %w", err))
+	}
+
+	return decodeAssetList(body)
+}
+
+func (r ChainRegistry) getLocalAssetList() (*chain_registry.ChainRegistryAssetList, error) {
+	body, err := os.ReadFile(r.assetListFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read asset list file: %w", err)
 	}
 
+	return decodeAssetList(body)
+}
+
+func decodeAssetList(body []byte) (*chain_registry.ChainRegistryAssetList, error) {
 	// Decode the JSON into the AssetList structure
 	var assetList chain_registry.ChainRegistryAssetList
-	err = json.Unmarshal(body, &assetList)
+	err := json.Unmarshal(body, &assetList)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode JSON: %w", err)
 	}
 
 	return &assetList, nil
 }
+
+// mergeAssetLists replaces the remote assets having the base denom of a local asset and appends the other local assets
+func mergeAssetLists(remote, local *chain_registry.ChainRegistryAssetList) *chain_registry.ChainRegistryAssetList {
+	result := &chain_registry.ChainRegistryAssetList{ChainName: remote.ChainName}
+	if local.ChainName != "" {
+		result.ChainName = local.ChainName
+	}
+
+	overrides := make(map[string]chain_registry.ChainRegistryAsset, len(local.Assets))
+	for _, a := range local.Assets {
+		overrides[a.Base] = a
+	}
+
+	for _, a := range remote.Assets {
+		if override, ok := overrides[a.Base]; ok {
+			a = override
+			delete(overrides, a.Base)
+		}
+
+		result.Assets = append(result.Assets, a)
+	}
+
+	//keep the order of the local file for the new assets
+	for _, a := range local.Assets {
+		if _, ok := overrides[a.Base]; ok {
+			result.Assets = append(result.Assets, a)
+		}
+	}
+
+	return result
+}
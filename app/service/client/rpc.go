@@ -4,6 +4,7 @@ import (
 	"github.com/cometbft/cometbft/rpc/client/http"
 )
 
-func GetRpcClient(host string) (client *http.HTTP, err error) {
-	return http.New(host, endpoint)
+// GetRpcClient returns a client whose requests are aborted after timeoutSeconds
+func GetRpcClient(host string, timeoutSeconds uint) (client *http.HTTP, err error) {
+	return http.NewWithTimeout(host, endpoint, timeoutSeconds)
 }
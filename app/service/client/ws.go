@@ -2,48 +2,20 @@ package client
 
 import (
 	"fmt"
+
 	"github.com/cometbft/cometbft/rpc/client/http"
-	"github.com/joho/godotenv"
 )
 
 const (
 	endpoint = "/websocket"
 )
 
-var wsClient *http.HTTP
-
-func GetWsClient() (*http.HTTP, error) {
-	if wsClient == nil {
-		wsEnv, err := getHost()
-		if err != nil {
-			return nil, err
-		}
-
-		client, err := http.New(wsEnv, endpoint)
-		if err != nil {
-			return nil, err
-		}
-
-		wsClient = client
-	}
-
-	return wsClient, nil
-}
-
-func getHost() (string, error) {
-	envFile, err := godotenv.Read(".env")
-	if err != nil {
-		return "", err
-	}
-
-	wsEnv, ok := envFile["BLOCKCHAIN_WS_HOST"]
-	if !ok {
-		return "", fmt.Errorf("BLOCKCHAIN_WS_HOST not found in .env")
-	}
-
-	if wsEnv == "" {
-		return "", fmt.Errorf("BLOCKCHAIN_WS_HOST is empty")
+// NewWsClient returns a new client for the websocket of the provided RPC node.
+// Each subscription needs its own client, since the listener stops it when the subscription ends.
+func NewWsClient(host string) (*http.HTTP, error) {
+	if host == "" {
+		return nil, fmt.Errorf("BLOCKCHAIN_WS_HOST is required by the listener")
 	}
 
-	return wsEnv, nil
+	return http.New(host, endpoint)
 }
@@ -3,6 +3,7 @@ package converter
 import (
 	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	cointrunkTypes "github.com/bze-alphateam/bze/x/cointrunk/types"
 	tradebinTypes "github.com/bze-alphateam/bze/x/tradebin/types"
 	"time"
 )
@@ -35,3 +36,26 @@ func NewMarketHistoryEntity(source *tradebinTypes.HistoryOrder) (*entity.MarketH
 		Taker:      source.GetTaker(),
 	}, nil
 }
+
+func NewCointrunkArticleEntity(source *cointrunkTypes.Article) *entity.CointrunkArticle {
+	return &entity.CointrunkArticle{
+		ID:        source.GetId(),
+		Title:     source.GetTitle(),
+		Url:       source.GetUrl(),
+		Picture:   source.GetPicture(),
+		Publisher: source.GetPublisher(),
+		Paid:      source.GetPaid(),
+		CreatedAt: time.Unix(source.GetCreatedAt(), 0).UTC(),
+	}
+}
+
+func NewCointrunkPublisherEntity(source *cointrunkTypes.Publisher) *entity.CointrunkPublisher {
+	return &entity.CointrunkPublisher{
+		Address:       source.GetAddress(),
+		Name:          source.GetName(),
+		Active:        source.GetActive(),
+		ArticlesCount: source.GetArticlesCount(),
+		Respect:       source.GetRespect(),
+		CreatedAt:     time.Unix(source.GetCreatedAt(), 0).UTC(),
+	}
+}
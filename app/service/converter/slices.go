@@ -26,3 +26,16 @@ func SplitIntervalsSlice(data []*entity.MarketHistoryInterval, batchSize int) []
 	}
 	return batches
 }
+
+// SplitSlice splits a slice into batches of a specified size
+func SplitSlice[T any](data []T, batchSize int) [][]T {
+	var batches [][]T
+	for i := 0; i < len(data); i += batchSize {
+		end := i + batchSize
+		if end > len(data) {
+			end = len(data)
+		}
+		batches = append(batches, data[i:end])
+	}
+	return batches
+}
@@ -1,6 +1,7 @@
 package converter
 
 import (
+	"context"
 	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/chain_registry"
 	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
@@ -8,7 +9,7 @@ import (
 )
 
 type assetProvider interface {
-	GetAssetDetails(denom string) (*chain_registry.ChainRegistryAsset, error)
+	GetAssetDetailsOrDefault(ctx context.Context, denom string) (*chain_registry.ChainRegistryAsset, error)
 }
 
 type TypesConverter struct {
@@ -16,8 +17,8 @@ type TypesConverter struct {
 	quote *chain_registry.ChainRegistryAsset
 }
 
-func NewTypesConverter(provider assetProvider, market *types.Market) (*TypesConverter, error) {
-	bAsset, err := provider.GetAssetDetails(market.GetBase())
+func NewTypesConverter(ctx context.Context, provider assetProvider, market *types.Market) (*TypesConverter, error) {
+	bAsset, err := provider.GetAssetDetailsOrDefault(ctx, market.GetBase())
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +27,7 @@ func NewTypesConverter(provider assetProvider, market *types.Market) (*TypesConv
 		return nil, fmt.Errorf("base asset not found")
 	}
 
-	qAsset, err := provider.GetAssetDetails(market.GetQuote())
+	qAsset, err := provider.GetAssetDetailsOrDefault(ctx, market.GetQuote())
 	if err != nil {
 		return nil, err
 	}
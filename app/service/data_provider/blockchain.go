@@ -20,6 +20,6 @@ func NewBlockchainProvider(client *http.HTTP) (*BlockchainProvider, error) {
 	return &BlockchainProvider{client: client}, nil
 }
 
-func (b BlockchainProvider) GetStatus() (*coretypes.ResultStatus, error) {
-	return b.client.Status(context.Background())
+func (b BlockchainProvider) GetStatus(ctx context.Context) (*coretypes.ResultStatus, error) {
+	return b.client.Status(ctx)
 }
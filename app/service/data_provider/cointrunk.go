@@ -0,0 +1,140 @@
+package data_provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze/x/cointrunk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cointrunkPageLimit = 1000
+	//the new articles are usually few, they are read in small pages
+	cointrunkLatestPageLimit = 50
+)
+
+type cointrunkClientProvider interface {
+	GetCointrunkQueryClient() (types.QueryClient, error)
+}
+
+// Cointrunk reads the articles published on chain and their publishers
+type Cointrunk struct {
+	provider cointrunkClientProvider
+	logger   logrus.FieldLogger
+}
+
+func NewCointrunkProvider(logger logrus.FieldLogger, provider cointrunkClientProvider) (*Cointrunk, error) {
+	if provider == nil || logger == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewCointrunkProvider")
+	}
+
+	return &Cointrunk{
+		provider: provider,
+		logger:   logger.WithField("service", "DataProvider.Cointrunk"),
+	}, nil
+}
+
+// GetAllArticles returns all the articles published on chain, reading all the pages
+func (c *Cointrunk) GetAllArticles(ctx context.Context) ([]types.Article, error) {
+	qc, err := c.provider.GetCointrunkQueryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.Article
+	var key []byte
+	for {
+		start := time.Now()
+		res, err := qc.AllArticles(ctx, &types.QueryAllArticlesRequest{Pagination: getPageRequest(key)})
+		metrics.ObserveGrpcCall("AllArticles", start, err)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, res.GetArticle()...)
+		key = getNextKey(res.GetPagination())
+		if len(key) == 0 {
+			c.logger.WithField("count", len(result)).Info("articles fetched")
+
+			return result, nil
+		}
+	}
+}
+
+// GetArticlesAfter returns the articles with an id greater than lastId, latest first. The articles are stored by id on
+// chain, so the pages are read backwards from the latest article and only the new articles are downloaded.
+func (c *Cointrunk) GetArticlesAfter(ctx context.Context, lastId uint64) ([]types.Article, error) {
+	qc, err := c.provider.GetCointrunkQueryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.Article
+	var key []byte
+	for {
+		start := time.Now()
+		res, err := qc.AllArticles(ctx, &types.QueryAllArticlesRequest{
+			Pagination: &query.PageRequest{Key: key, Limit: cointrunkLatestPageLimit, Reverse: true},
+		})
+		metrics.ObserveGrpcCall("AllArticles", start, err)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range res.GetArticle() {
+			if a.GetId() <= lastId {
+				return result, nil
+			}
+
+			result = append(result, a)
+		}
+
+		key = getNextKey(res.GetPagination())
+		if len(key) == 0 {
+			return result, nil
+		}
+	}
+}
+
+// GetAllPublishers returns all the publishers accepted by the governance, reading all the pages
+func (c *Cointrunk) GetAllPublishers(ctx context.Context) ([]types.Publisher, error) {
+	qc, err := c.provider.GetCointrunkQueryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.Publisher
+	var key []byte
+	for {
+		start := time.Now()
+		res, err := qc.Publisher(ctx, &types.QueryPublisherRequest{Pagination: getPageRequest(key)})
+		metrics.ObserveGrpcCall("Publisher", start, err)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, res.GetPublisher()...)
+		key = getNextKey(res.GetPagination())
+		if len(key) == 0 {
+			c.logger.WithField("count", len(result)).Info("publishers fetched")
+
+			return result, nil
+		}
+	}
+}
+
+func getPageRequest(key []byte) *query.PageRequest {
+	return &query.PageRequest{Key: key, Limit: cointrunkPageLimit}
+}
+
+func getNextKey(pagination *query.PageResponse) []byte {
+	if pagination == nil {
+		return nil
+	}
+
+	return pagination.NextKey
+}
@@ -2,6 +2,7 @@ package data_provider
 
 import (
 	"context"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/cosmos/cosmos-sdk/types/query"
@@ -25,7 +26,7 @@ func NewHistoryDataProvider(logger logrus.FieldLogger, provider clientProvider)
 	}, nil
 }
 
-func (o *History) GetMarketHistory(marketId string, limit uint64, key string) ([]types.HistoryOrder, string, error) {
+func (o *History) GetMarketHistory(ctx context.Context, marketId string, limit uint64, key string) ([]types.HistoryOrder, string, error) {
 	qc, err := o.provider.GetTradebinQueryClient()
 	if err != nil {
 		return nil, "", err
@@ -35,7 +36,9 @@ func (o *History) GetMarketHistory(marketId string, limit uint64, key string) ([
 	o.logger.Info("fetching history orders from blockchain")
 	o.logger.WithField("params", params).Info("using params to get market history")
 
-	res, err := qc.MarketHistory(context.Background(), params)
+	start := time.Now()
+	res, err := qc.MarketHistory(ctx, params)
+	metrics.ObserveGrpcCall("MarketHistory", start, err)
 	if err != nil {
 		return nil, "", err
 	}
@@ -60,7 +63,7 @@ func (o *History) getHistoryQueryParams(marketId string, limit uint64, key strin
 	return &res
 }
 
-func (o *History) GetFirstMarketOrderTime(marketId string) (time.Time, error) {
+func (o *History) GetFirstMarketOrderTime(ctx context.Context, marketId string) (time.Time, error) {
 	qc, err := o.provider.GetTradebinQueryClient()
 	if err != nil {
 		return time.Time{}, err
@@ -70,7 +73,9 @@ func (o *History) GetFirstMarketOrderTime(marketId string) (time.Time, error) {
 	o.logger.Info("fetching first market order from blockchain")
 	o.logger.WithField("params", params).Info("using params to get market history")
 
-	res, err := qc.MarketHistory(context.Background(), params)
+	start := time.Now()
+	res, err := qc.MarketHistory(ctx, params)
+	metrics.ObserveGrpcCall("MarketHistory", start, err)
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -3,6 +3,9 @@ package data_provider
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
 
 	tradebinTypes "github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/cosmos/cosmos-sdk/types/query"
@@ -29,7 +32,7 @@ func NewMarketProvider(cl clientProvider, logger logrus.FieldLogger) (*Market, e
 	}, nil
 }
 
-func (m *Market) GetAllMarkets() ([]tradebinTypes.Market, error) {
+func (m *Market) GetAllMarkets(ctx context.Context) ([]tradebinTypes.Market, error) {
 	m.logger.Info("getting tradebin query client")
 	qc, err := m.provider.GetTradebinQueryClient()
 	if err != nil {
@@ -39,7 +42,9 @@ func (m *Market) GetAllMarkets() ([]tradebinTypes.Market, error) {
 	params := m.getMarketsParams()
 	m.logger.Info("fetching markets from blockchain")
 
-	res, err := qc.AllMarkets(context.Background(), params)
+	start := time.Now()
+	res, err := qc.AllMarkets(ctx, params)
+	metrics.ObserveGrpcCall("AllMarkets", start, err)
 	if err != nil {
 		return nil, err
 	}
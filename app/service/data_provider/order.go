@@ -2,10 +2,12 @@ package data_provider
 
 import (
 	"context"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/cosmos/cosmos-sdk/types/query"
 	"github.com/sirupsen/logrus"
+	"time"
 )
 
 const (
@@ -29,15 +31,15 @@ func NewOrderDataProvider(logger logrus.FieldLogger, provider clientProvider) (*
 	}, nil
 }
 
-func (o *Order) GetActiveBuyOrders(marketId string) ([]types.AggregatedOrder, error) {
-	return o.getAggregatedOrders(marketId, buy)
+func (o *Order) GetActiveBuyOrders(ctx context.Context, marketId string) ([]types.AggregatedOrder, error) {
+	return o.getAggregatedOrders(ctx, marketId, buy)
 }
 
-func (o *Order) GetActiveSellOrders(marketId string) ([]types.AggregatedOrder, error) {
-	return o.getAggregatedOrders(marketId, sell)
+func (o *Order) GetActiveSellOrders(ctx context.Context, marketId string) ([]types.AggregatedOrder, error) {
+	return o.getAggregatedOrders(ctx, marketId, sell)
 }
 
-func (o *Order) getAggregatedOrders(marketId, orderType string) ([]types.AggregatedOrder, error) {
+func (o *Order) getAggregatedOrders(ctx context.Context, marketId, orderType string) ([]types.AggregatedOrder, error) {
 	o.logger.Info("getting tradebin query client")
 	qc, err := o.provider.GetTradebinQueryClient()
 	if err != nil {
@@ -47,7 +49,9 @@ func (o *Order) getAggregatedOrders(marketId, orderType string) ([]types.Aggrega
 	params := o.getAggregatedOrdersQueryParams(marketId, orderType)
 	o.logger.Info("fetching aggregated orders from blockchain")
 
-	res, err := qc.MarketAggregatedOrders(context.Background(), params)
+	start := time.Now()
+	res, err := qc.MarketAggregatedOrders(ctx, params)
+	metrics.ObserveGrpcCall("MarketAggregatedOrders", start, err)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,119 @@
+package data_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+)
+
+type tickerStorage interface {
+	GetTicker(ctx context.Context, marketId string) (*entity.MarketTicker, error)
+}
+
+// DexPrice reads the prices from the last price of internal DEX markets quoted in a coin worth one unit of the currency
+type DexPrice struct {
+	storage tickerStorage
+	markets []config.PriceSourceEntry
+}
+
+func NewDexPrice(storage tickerStorage, markets []config.PriceSourceEntry) (*DexPrice, error) {
+	if storage == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewDexPrice")
+	}
+
+	return &DexPrice{storage: storage, markets: markets}, nil
+}
+
+func (d *DexPrice) Name() string {
+	return config.PriceSourceDex
+}
+
+// GetPrices returns the last price of the configured markets matching the ids and currencies. The markets without
+// trades in the last 24 hours have no price.
+func (d *DexPrice) GetPrices(ctx context.Context, ids, currencies []string) ([]dto.CoinPrice, error) {
+	var prices []dto.CoinPrice
+	var errs []error
+	for _, market := range d.markets {
+		if !slices.Contains(ids, market.Id) || !slices.Contains(currencies, market.Currency) {
+			continue
+		}
+
+		ticker, err := d.storage.GetTicker(ctx, market.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", market.Value, err))
+
+			continue
+		}
+
+		if ticker == nil || !ticker.LastPrice.Valid {
+			continue
+		}
+
+		price, err := strconv.ParseFloat(ticker.LastPrice.String, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", market.Value, err))
+
+			continue
+		}
+
+		updatedAt := time.Now()
+		if ticker.UpdatedAt.Valid {
+			updatedAt = ticker.UpdatedAt.Time
+		}
+
+		prices = append(prices, dto.CoinPrice{
+			Denom:      market.Id,
+			Price:      price,
+			PriceDenom: market.Currency,
+			Source:     config.PriceSourceDex,
+			UpdatedAt:  updatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return prices, errors.Join(errs...)
+}
+
+// StaticPrice returns the prices set in the config. They are never stale.
+type StaticPrice struct {
+	prices []config.PriceSourceEntry
+}
+
+func NewStaticPrice(prices []config.PriceSourceEntry) *StaticPrice {
+	return &StaticPrice{prices: prices}
+}
+
+func (s *StaticPrice) Name() string {
+	return config.PriceSourceStatic
+}
+
+func (s *StaticPrice) GetPrices(_ context.Context, ids, currencies []string) ([]dto.CoinPrice, error) {
+	var prices []dto.CoinPrice
+	for _, entry := range s.prices {
+		if !slices.Contains(ids, entry.Id) || !slices.Contains(currencies, entry.Currency) {
+			continue
+		}
+
+		price, err := strconv.ParseFloat(entry.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid static price of %s:%s: %w", entry.Id, entry.Currency, err)
+		}
+
+		prices = append(prices, dto.CoinPrice{
+			Denom:      entry.Id,
+			Price:      price,
+			PriceDenom: entry.Currency,
+			Source:     config.PriceSourceStatic,
+			UpdatedAt:  time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+
+	return prices, nil
+}
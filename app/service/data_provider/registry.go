@@ -1,98 +1,376 @@
 package data_provider
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/chain_registry"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const (
-	cacheTtl = 30 * time.Minute
+	ibcDenomPrefix = "ibc/"
+
+	//name of the registry in the cache metrics
+	registryCacheName = "chain_registry"
+	//single-flight key of the asset list download
+	assetListFlightKey = "asset_list"
+	//wait before retrying a failed refresh, the last good snapshot is served meanwhile
+	registryRetryInterval = time.Minute
 )
 
-type registryCache interface {
-	Get(key string) ([]byte, error)
-	Set(key string, data []byte, expiration time.Duration) error
+// RegistryStore returns an assetlist.json of the chain registry
+type RegistryStore interface {
+	GetAssetList(ctx context.Context) (*chain_registry.ChainRegistryAssetList, error)
+}
+
+type denomTraceProvider interface {
+	GetDenomTrace(ctx context.Context, hash string) (*dto.DenomTrace, error)
 }
 
-type registryStore interface {
-	GetAssetList() (*chain_registry.ChainRegistryAssetList, error)
+// registrySnapshot is the asset list loaded at once. It is replaced, never modified, on refresh.
+type registrySnapshot struct {
+	assets []chain_registry.ChainRegistryAsset
+	byBase map[string]*chain_registry.ChainRegistryAsset
 }
 
+// resolvedAsset is a denom missing from the snapshot: an IBC denom resolved through its trace or a negative
+// given the default exponent
+type resolvedAsset struct {
+	asset     *chain_registry.ChainRegistryAsset
+	expiresAt time.Time
+}
+
+// ChainRegistry serves the assets from a snapshot of the asset list. A stale snapshot is served while a single
+// download refreshes it in background, and is kept when the refresh fails.
 type ChainRegistry struct {
-	cache  registryCache
-	store  registryStore
+	store  RegistryStore
+	traces denomTraceProvider
 	logger logrus.FieldLogger
+
+	//the asset lists of the chains at the other end of the IBC channels, by channel id
+	counterparties map[string]RegistryStore
+
+	cacheTtl        time.Duration
+	defaultExponent int
+
+	flight   singleflight.Group
+	snapshot atomic.Pointer[registrySnapshot]
+	//unix nanoseconds after which the snapshot is refreshed in background, moved forward by a failed refresh
+	//without replacing the snapshot
+	refreshAt atomic.Int64
+
+	mx       sync.RWMutex
+	resolved map[string]resolvedAsset
 }
 
-func NewChainRegistry(logger logrus.FieldLogger, cache registryCache, store registryStore) (*ChainRegistry, error) {
-	if cache == nil || store == nil || logger == nil {
+func NewChainRegistry(logger logrus.FieldLogger, store RegistryStore, traces denomTraceProvider, counterparties map[string]RegistryStore, cacheTtl time.Duration, defaultExponent int) (*ChainRegistry, error) {
+	if store == nil || traces == nil || logger == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewChainRegistry")
 	}
 
 	return &ChainRegistry{
-		cache:  cache,
-		store:  store,
-		logger: logger.WithField("service", "DataProvider.ChainRegistry"),
+		store:          store,
+		traces:         traces,
+		logger:         logger.WithField("service", "DataProvider.ChainRegistry"),
+		counterparties: counterparties,
+
+		cacheTtl:        cacheTtl,
+		defaultExponent: defaultExponent,
+
+		resolved: make(map[string]resolvedAsset),
 	}, nil
 }
 
-func (r *ChainRegistry) GetAssetDetails(denom string) (*chain_registry.ChainRegistryAsset, error) {
-	l := r.logger.WithField("denom", denom)
-	cached, err := r.getAssetDetailsFromCache(denom)
+// GetAssetDetails returns the asset of the chain registry, the unknown IBC denoms are resolved through their trace
+func (r *ChainRegistry) GetAssetDetails(ctx context.Context, denom string) (*chain_registry.ChainRegistryAsset, error) {
+	asset, err := r.resolveAsset(ctx, denom)
 	if err != nil {
-		l.Errorf("Error getting asset details from cache: %v", err)
+		return nil, err
 	}
 
-	if cached != nil {
-		return cached, nil
+	if asset.Source == chain_registry.AssetSourceDefault {
+		return nil, internal.NewNotFoundErr(fmt.Sprintf("denom %s not found in registry", denom))
 	}
 
-	assetsList, err := r.store.GetAssetList()
+	return asset, nil
+}
+
+// GetAssetDetailsOrDefault is GetAssetDetails returning an asset with the default exponent when the denom can not be
+// resolved, so an unknown asset does not stop the markets sync
+func (r *ChainRegistry) GetAssetDetailsOrDefault(ctx context.Context, denom string) (*chain_registry.ChainRegistryAsset, error) {
+	return r.resolveAsset(ctx, denom)
+}
+
+// GetAssets returns every asset of the chain registry
+func (r *ChainRegistry) GetAssets(ctx context.Context) ([]chain_registry.ChainRegistryAsset, error) {
+	snapshot, err := r.getSnapshot(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if assetsList == nil {
-		return nil, fmt.Errorf("no chain registry assets found")
+	return slices.Clone(snapshot.assets), nil
+}
+
+func (r *ChainRegistry) resolveAsset(ctx context.Context, denom string) (*chain_registry.ChainRegistryAsset, error) {
+	snapshot, err := r.getSnapshot(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, a := range assetsList.Assets {
-		data, err := json.Marshal(a)
-		if err != nil {
-			l.WithError(err).Error("error marshalling asset to cache")
-			continue
+	if asset, ok := snapshot.byBase[denom]; ok {
+		metrics.CacheHit(registryCacheName)
+		result := *asset
+
+		return &result, nil
+	}
+
+	if asset := r.getResolved(denom); asset != nil {
+		metrics.CacheHit(registryCacheName)
+
+		return asset, nil
+	}
+
+	metrics.CacheMiss(registryCacheName)
+	//concurrent syncs of the markets of the same denom wait for one resolution, which must not be canceled when the
+	//caller that started it goes away
+	ch := r.flight.DoChan("denom:"+denom, func() (any, error) {
+		return r.resolveMissingAsset(context.WithoutCancel(ctx), snapshot, denom)
+	})
+	result, err := waitFlight(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := *result.(*chain_registry.ChainRegistryAsset)
+
+	return &asset, nil
+}
+
+// resolveMissingAsset resolves a denom missing from the snapshot and keeps the result until it expires, including
+// the negatives so an unknown denom does not trigger a resolution on every call. The result is not kept when the
+// snapshot was replaced meanwhile, the denom may be part of the new one.
+func (r *ChainRegistry) resolveMissingAsset(ctx context.Context, snapshot *registrySnapshot, denom string) (*chain_registry.ChainRegistryAsset, error) {
+	l := r.logger.WithField("denom", denom)
+	asset, err := r.resolveIbcAsset(ctx, denom)
+	if err != nil {
+		//the sync is retried later instead of saving amounts with a wrong exponent
+		if internal.IsErrorCode(err, internal.ErrCodeUnavailable) {
+			return nil, err
 		}
 
-		err = r.cache.Set(a.Base, data, cacheTtl)
+		l.WithError(err).Warnf("could not resolve the denom, using the default exponent %d", r.defaultExponent)
+		metrics.ChainRegistryUnresolved(denom)
+		asset = r.defaultAsset(denom)
+	}
+
+	//refresh stores the new snapshot before clearing the resolved denoms under the same lock
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.snapshot.Load() != snapshot {
+		l.Debug("the chain registry was refreshed while resolving the denom, not keeping it")
+
+		return asset, nil
+	}
+	r.resolved[denom] = resolvedAsset{asset: asset, expiresAt: time.Now().Add(r.cacheTtl)}
+
+	return asset, nil
+}
+
+func (r *ChainRegistry) getResolved(denom string) *chain_registry.ChainRegistryAsset {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	resolved, ok := r.resolved[denom]
+	if !ok || time.Now().After(resolved.expiresAt) {
+		return nil
+	}
+
+	asset := *resolved.asset
+
+	return &asset
+}
+
+// getSnapshot returns the current snapshot, loading it on first use. A stale snapshot is returned as is while it
+// is refreshed in background.
+func (r *ChainRegistry) getSnapshot(ctx context.Context) (*registrySnapshot, error) {
+	snapshot := r.snapshot.Load()
+	if snapshot == nil {
+		//the first load is shared by the concurrent callers, it must not fail for all of them when one goes away
+		ch := r.flight.DoChan(assetListFlightKey, func() (any, error) {
+			return r.refresh(context.WithoutCancel(ctx))
+		})
+		result, err := waitFlight(ctx, ch)
 		if err != nil {
-			l.WithError(err).Error("error caching asset")
-			continue
+			return nil, err
 		}
+
+		return result.(*registrySnapshot), nil
 	}
 
-	return r.getAssetDetailsFromCache(denom)
+	//the first caller past the deadline moves it forward and starts the only background refresh
+	refreshAt := r.refreshAt.Load()
+	now := time.Now()
+	if now.UnixNano() > refreshAt && r.refreshAt.CompareAndSwap(refreshAt, now.Add(r.retryInterval()).UnixNano()) {
+		//the refresh must not be canceled when the request that triggered it ends
+		refreshCtx := context.WithoutCancel(ctx)
+		go func() {
+			_, err, _ := r.flight.Do(assetListFlightKey, func() (any, error) {
+				return r.refresh(refreshCtx)
+			})
+			if err != nil {
+				r.logger.WithError(err).Warn("could not refresh the chain registry, keeping the last snapshot")
+			}
+		}()
+	}
+
+	return snapshot, nil
 }
 
-func (r *ChainRegistry) getAssetDetailsFromCache(denom string) (*chain_registry.ChainRegistryAsset, error) {
-	l := r.logger.WithField("denom", denom)
-	cache, err := r.cache.Get(denom)
+func (r *ChainRegistry) retryInterval() time.Duration {
+	return min(registryRetryInterval, r.cacheTtl)
+}
+
+// waitFlight returns the result of a single-flight call, or the context error when the caller goes away first
+func waitFlight(ctx context.Context, ch <-chan singleflight.Result) (any, error) {
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// refresh downloads the asset list and publishes the new snapshot. On failure the current snapshot, if any, is
+// kept and refreshed again after registryRetryInterval.
+func (r *ChainRegistry) refresh(ctx context.Context) (*registrySnapshot, error) {
+	assetsList, err := r.store.GetAssetList(ctx)
+	if err == nil && assetsList == nil {
+		err = fmt.Errorf("no chain registry assets found")
+	}
+
 	if err != nil {
-		l.WithError(err).Warn("error when trying to get chain registry asset details from cache")
+		r.refreshAt.Store(time.Now().Add(r.retryInterval()).UnixNano())
+
+		return nil, err
+	}
+
+	snapshot := &registrySnapshot{
+		assets: assetsList.Assets,
+		byBase: make(map[string]*chain_registry.ChainRegistryAsset, len(assetsList.Assets)),
+	}
+	for i := range snapshot.assets {
+		snapshot.assets[i].Source = chain_registry.AssetSourceRegistry
+		snapshot.byBase[snapshot.assets[i].Base] = &snapshot.assets[i]
+	}
+
+	r.snapshot.Store(snapshot)
+	r.refreshAt.Store(time.Now().Add(r.cacheTtl).UnixNano())
+
+	//the denoms resolved so far may be part of the new snapshot
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	clear(r.resolved)
+
+	return snapshot, nil
+}
+
+// resolveIbcAsset finds the asset sent through the first channel of the denom trace in the asset list of the chain
+// at the other end of that channel
+func (r *ChainRegistry) resolveIbcAsset(ctx context.Context, denom string) (*chain_registry.ChainRegistryAsset, error) {
+	hash, ok := strings.CutPrefix(denom, ibcDenomPrefix)
+	if !ok {
+		return nil, fmt.Errorf("denom %s not found in registry", denom)
 	}
 
-	if len(cache) > 0 {
-		resp := &chain_registry.ChainRegistryAsset{}
-		err := json.Unmarshal(cache, resp)
-		if err == nil {
-			return resp, nil
+	trace, err := r.traces.GetDenomTrace(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the denom trace: %w", err)
+	}
+
+	//the path is made of port/channel pairs, the first one is the channel of this chain
+	hops := strings.Split(trace.Path, "/")
+	if len(hops) < 2 {
+		return nil, fmt.Errorf("invalid denom trace path %q", trace.Path)
+	}
+
+	channel := hops[1]
+	store, ok := r.counterparties[channel]
+	if !ok {
+		return nil, fmt.Errorf("no asset list configured for %s", channel)
+	}
+
+	list, err := store.GetAssetList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range list.Assets {
+		if a.Base != trace.BaseDenom {
+			continue
+		}
+
+		units := make([]chain_registry.ChainRegistryAssetDenom, 0, len(a.DenomUnits))
+		for _, unit := range a.DenomUnits {
+			if unit.Denom == trace.BaseDenom {
+				unit.Denom = denom
+				unit.Aliases = append(slices.Clone(unit.Aliases), trace.BaseDenom)
+			}
+			units = append(units, unit)
 		}
 
-		l.WithError(err).Warn("error when trying to unmarshal chain registry asset details from cache")
+		a.Base = denom
+		a.DenomUnits = units
+		a.Source = chain_registry.AssetSourceIbc
+		a.Traces = []chain_registry.ChainRegistryAssetTrace{{
+			Type:         chain_registry.TraceTypeIbc,
+			Counterparty: chain_registry.ChainRegistryAssetTraceEndpoint{ChainName: list.ChainName, BaseDenom: trace.BaseDenom},
+			Chain:        chain_registry.ChainRegistryAssetTraceEndpoint{ChannelId: channel, Path: trace.Path + "/" + trace.BaseDenom},
+		}}
+
+		return &a, nil
+	}
+
+	return nil, fmt.Errorf("%s not found in the asset list of %s", trace.BaseDenom, channel)
+}
+
+// defaultAsset follows the chain registry convention: a base unit with exponent 0 and a display unit with the
+// default exponent, named after the denom (e.g. ubze => bze, factory/bze1.../uabc => abc)
+func (r *ChainRegistry) defaultAsset(denom string) *chain_registry.ChainRegistryAsset {
+	display := defaultDisplayDenom(denom)
+
+	return &chain_registry.ChainRegistryAsset{
+		DenomUnits: []chain_registry.ChainRegistryAssetDenom{
+			{Denom: denom, Exponent: 0},
+			{Denom: display, Exponent: r.defaultExponent},
+		},
+		Base:    denom,
+		Name:    denom,
+		Display: display,
+		Symbol:  strings.ToUpper(display),
+		Source:  chain_registry.AssetSourceDefault,
+	}
+}
+
+// defaultDisplayDenom returns the last segment of the denom without the micro prefix, upper case when it would be
+// the base denom itself
+func defaultDisplayDenom(denom string) string {
+	display := strings.ToLower(denom[strings.LastIndex(denom, "/")+1:])
+	if len(display) > 1 && strings.HasPrefix(display, "u") {
+		display = display[1:]
+	}
+
+	if display == denom {
+		return strings.ToUpper(display)
 	}
 
-	return nil, nil
+	return display
 }
@@ -0,0 +1,274 @@
+package data_provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/chain_registry"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+// blockingStore returns its asset list once released, failing when the context it was called with is canceled
+type blockingStore struct {
+	list    *chain_registry.ChainRegistryAssetList
+	release chan struct{}
+}
+
+func (s *blockingStore) GetAssetList(ctx context.Context) (*chain_registry.ChainRegistryAssetList, error) {
+	if s.release != nil {
+		<-s.release
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.list, nil
+}
+
+// blockingTraces signals when a trace is requested and returns it once released
+type blockingTraces struct {
+	trace     *dto.DenomTrace
+	requested chan struct{}
+	release   chan struct{}
+}
+
+func (t *blockingTraces) GetDenomTrace(ctx context.Context, _ string) (*dto.DenomTrace, error) {
+	if t.requested != nil {
+		t.requested <- struct{}{}
+		<-t.release
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return t.trace, nil
+}
+
+func newTestRegistry(t *testing.T, store RegistryStore, traces denomTraceProvider) *ChainRegistry {
+	t.Helper()
+
+	counterparty := &blockingStore{list: &chain_registry.ChainRegistryAssetList{
+		ChainName: "osmosis",
+		Assets: []chain_registry.ChainRegistryAsset{{
+			Base:       "uosmo",
+			Display:    "osmo",
+			DenomUnits: []chain_registry.ChainRegistryAssetDenom{{Denom: "uosmo"}, {Denom: "osmo", Exponent: 6}},
+		}},
+	}}
+
+	r, err := NewChainRegistry(logrus.New(), store, traces, map[string]RegistryStore{"channel-0": counterparty}, time.Hour, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func bzeAssetList() *chain_registry.ChainRegistryAssetList {
+	return &chain_registry.ChainRegistryAssetList{
+		ChainName: "beezee",
+		Assets:    []chain_registry.ChainRegistryAsset{{Base: "ubze", Display: "bze"}},
+	}
+}
+
+func TestFirstLoadIsNotCanceledByTheCaller(t *testing.T) {
+	store := &blockingStore{list: bzeAssetList(), release: make(chan struct{})}
+	r := newTestRegistry(t, store, &blockingTraces{})
+
+	canceled, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := r.GetAssets(canceled)
+		first <- err
+	}()
+
+	//wait for the first caller to start the download before the second one joins it
+	time.Sleep(20 * time.Millisecond)
+	second := make(chan error)
+	go func() {
+		_, err := r.GetAssets(context.Background())
+		second <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the canceled caller to return its context error, got %v", err)
+	}
+
+	close(store.release)
+	if err := <-second; err != nil {
+		t.Fatalf("the load failed for the other caller: %v", err)
+	}
+	if r.snapshot.Load() == nil {
+		t.Fatal("the snapshot was not stored")
+	}
+}
+
+func TestResolutionIsNotCanceledByTheCaller(t *testing.T) {
+	traces := &blockingTraces{
+		trace:     &dto.DenomTrace{Path: "transfer/channel-0", BaseDenom: "uosmo"},
+		requested: make(chan struct{}),
+		release:   make(chan struct{}),
+	}
+	r := newTestRegistry(t, &blockingStore{list: bzeAssetList()}, traces)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := r.GetAssetDetails(canceled, "ibc/OSMO")
+		first <- err
+	}()
+
+	<-traces.requested
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the canceled caller to return its context error, got %v", err)
+	}
+
+	close(traces.release)
+	//the resolution still completes and is kept for the next callers
+	deadline := time.Now().Add(time.Second)
+	for r.getResolved("ibc/OSMO") == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("the denom was not resolved")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if asset := r.getResolved("ibc/OSMO"); asset.Source != chain_registry.AssetSourceIbc {
+		t.Fatalf("expected the IBC asset, got %+v", asset)
+	}
+}
+
+func TestResolutionIsDiscardedAfterRefresh(t *testing.T) {
+	traces := &blockingTraces{
+		trace:     &dto.DenomTrace{Path: "transfer/channel-0", BaseDenom: "uosmo"},
+		requested: make(chan struct{}),
+		release:   make(chan struct{}),
+	}
+	r := newTestRegistry(t, &blockingStore{list: bzeAssetList()}, traces)
+
+	result := make(chan error)
+	go func() {
+		_, err := r.GetAssetDetails(context.Background(), "ibc/OSMO")
+		result <- err
+	}()
+
+	//the asset list is refreshed while the denom is resolved
+	<-traces.requested
+	if _, err := r.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	close(traces.release)
+
+	if err := <-result; err != nil {
+		t.Fatalf("expected the caller to get the resolved asset, got %v", err)
+	}
+	if asset := r.getResolved("ibc/OSMO"); asset != nil {
+		t.Fatalf("the resolution started from the replaced snapshot was kept: %+v", asset)
+	}
+}
+
+// failingStore counts the downloads, failing all of them after the first one
+type failingStore struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *failingStore) GetAssetList(context.Context) (*chain_registry.ChainRegistryAssetList, error) {
+	if s.calls.Add(1) == 1 {
+		return bzeAssetList(), nil
+	}
+
+	<-s.release
+
+	return nil, errors.New("registry is down")
+}
+
+func TestFailedRefreshKeepsTheSnapshot(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	store := &failingStore{release: make(chan struct{})}
+	r, err := NewChainRegistry(logger, store, &blockingTraces{}, nil, time.Hour, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = r.GetAssets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := r.snapshot.Load()
+
+	//the snapshot is stale, the concurrent callers start a single refresh
+	r.refreshAt.Store(time.Now().Add(-time.Second).UnixNano())
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.GetAssets(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(store.release)
+
+	deadline := time.Now().Add(time.Second)
+	for len(hook.AllEntries()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the failed refresh was not logged")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if calls := store.calls.Load(); calls != 2 {
+		t.Fatalf("expected a single refresh, got %d downloads", calls-1)
+	}
+	if entries := len(hook.AllEntries()); entries != 1 {
+		t.Fatalf("expected the failure logged once, got %d entries", entries)
+	}
+	if r.snapshot.Load() != snapshot {
+		t.Fatal("the failed refresh replaced the snapshot")
+	}
+	if time.Unix(0, r.refreshAt.Load()).Before(time.Now()) {
+		t.Fatal("the failed refresh did not move the refresh deadline")
+	}
+}
+
+func TestDefaultAssetFollowsTheRegistryConvention(t *testing.T) {
+	//the IBC denom comes from a channel without asset list
+	traces := &blockingTraces{trace: &dto.DenomTrace{Path: "transfer/channel-9", BaseDenom: "uabc"}}
+	r := newTestRegistry(t, &blockingStore{list: bzeAssetList()}, traces)
+
+	cases := map[string]string{
+		"ulol":                 "lol",
+		"factory/bze1abc/uabc": "abc",
+		"ibc/ABCDEF":           "abcdef",
+		"lol":                  "LOL",
+	}
+	for denom, display := range cases {
+		asset, err := r.GetAssetDetailsOrDefault(context.Background(), denom)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if asset.Base != denom || asset.DenomUnits[0].Denom != denom || asset.DenomUnits[0].Exponent != 0 {
+			t.Errorf("%s: expected a base unit with exponent 0, got %+v", denom, asset.DenomUnits)
+		}
+
+		unit := asset.GetDisplayDenomUnit()
+		if asset.Display != display || unit == nil || unit.Exponent != 6 {
+			t.Errorf("%s: expected the display unit %s with the default exponent, got %s %+v", denom, display, asset.Display, unit)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package dex
+
+import (
+	"context"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/query"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/export"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type tradesStreamer interface {
+	StreamByExecutedAt(ctx context.Context, marketId string, from, to time.Time, fn func(*entity.MarketHistory) error) error
+}
+
+type candlesStreamer interface {
+	StreamIntervals(ctx context.Context, params *query.IntervalsParams, fn func(*entity.MarketHistoryInterval) error) error
+}
+
+type ExportService struct {
+	logger  logrus.FieldLogger
+	trades  tradesStreamer
+	candles candlesStreamer
+	mRepo   ordersMarketRepo
+}
+
+func NewExportService(logger logrus.FieldLogger, trades tradesStreamer, candles candlesStreamer, mRepo ordersMarketRepo) (*ExportService, error) {
+	if logger == nil || trades == nil || candles == nil || mRepo == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewExportService")
+	}
+
+	return &ExportService{
+		logger:  logger.WithField("service", "Dex.ExportService"),
+		trades:  trades,
+		candles: candles,
+		mRepo:   mRepo,
+	}, nil
+}
+
+// ExportTrades writes the trades of the market executed in [from, to), oldest first. Zero from or to leave that end
+// open. Nothing is written when the market does not exist.
+func (e *ExportService) ExportTrades(ctx context.Context, marketId string, from, to time.Time, w export.Writer) error {
+	if err := e.checkMarket(ctx, marketId); err != nil {
+		return err
+	}
+
+	return e.trades.StreamByExecutedAt(ctx, marketId, from, to, func(t *entity.MarketHistory) error {
+		return w.Write(&response.ExportTrade{
+			OrderId:     t.ID,
+			MarketId:    t.MarketID,
+			OrderType:   t.OrderType,
+			Price:       t.Price,
+			BaseVolume:  t.Amount,
+			QuoteVolume: t.QuoteAmount,
+			ExecutedAt:  exportTime(t.ExecutedAt),
+			Maker:       t.Maker,
+			Taker:       t.Taker,
+		})
+	})
+}
+
+// ExportCandles writes the intervals of the given length started in [from, to), oldest first. Zero from or to leave
+// that end open. Nothing is written when the market does not exist.
+func (e *ExportService) ExportCandles(ctx context.Context, marketId string, length int, from, to time.Time, w export.Writer) error {
+	if err := e.checkMarket(ctx, marketId); err != nil {
+		return err
+	}
+
+	params := &query.IntervalsParams{
+		MarketId: marketId,
+		Length:   length,
+		StartAt:  from,
+		EndAt:    to,
+	}
+
+	return e.candles.StreamIntervals(ctx, params, func(i *entity.MarketHistoryInterval) error {
+		return w.Write(&response.ExportCandle{
+			MarketId:    i.MarketID,
+			Minutes:     i.Length,
+			StartAt:     exportTime(i.StartAt),
+			EndAt:       exportTime(i.EndAt),
+			Open:        i.OpenPrice,
+			High:        i.HighestPrice,
+			Low:         i.LowestPrice,
+			Close:       i.ClosePrice,
+			Average:     i.AveragePrice,
+			BaseVolume:  i.BaseVolume,
+			QuoteVolume: i.QuoteVolume,
+		})
+	})
+}
+
+func (e *ExportService) checkMarket(ctx context.Context, marketId string) error {
+	market, err := e.mRepo.GetMarket(ctx, marketId)
+	if err != nil {
+		return err
+	}
+
+	if market == nil {
+		return newMarketNotFoundErr(marketId)
+	}
+
+	return nil
+}
+
+func exportTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
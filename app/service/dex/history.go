@@ -1,6 +1,7 @@
 package dex
 
 import (
+	"context"
 	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
@@ -11,7 +12,7 @@ import (
 )
 
 type historyRepo interface {
-	GetHistoryBy(params request.HistoryParams) ([]entity.MarketHistory, error)
+	GetHistoryBy(ctx context.Context, params request.HistoryParams) ([]entity.MarketHistory, error)
 }
 
 type HistoryService struct {
@@ -30,8 +31,8 @@ func NewHistoryService(logger logrus.FieldLogger, historyRepo historyRepo) (*His
 	}, nil
 }
 
-func (h *HistoryService) GetHistory(params *request.HistoryParams) ([]response.HistoryTrade, error) {
-	hist, err := h.historyRepo.GetHistoryBy(*params)
+func (h *HistoryService) GetHistory(ctx context.Context, params *request.HistoryParams) ([]response.HistoryTrade, error) {
+	hist, err := h.historyRepo.GetHistoryBy(ctx, *params)
 	if err != nil {
 		return nil, err
 	}
@@ -55,8 +56,8 @@ func (h *HistoryService) GetHistory(params *request.HistoryParams) ([]response.H
 	return result, nil
 }
 
-func (h *HistoryService) GetCoingeckoHistory(params *request.HistoryParams) (*response.CoingeckoHistory, error) {
-	hist, err := h.historyRepo.GetHistoryBy(*params)
+func (h *HistoryService) GetCoingeckoHistory(ctx context.Context, params *request.HistoryParams) (*response.CoingeckoHistory, error) {
+	hist, err := h.historyRepo.GetHistoryBy(ctx, *params)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package dex
 
 import (
+	"context"
 	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/query"
 	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
@@ -12,50 +13,68 @@ import (
 )
 
 type intervalStore interface {
-	GetIntervalsBy(params *query.IntervalsParams) (query.IntervalsMap, error)
-	GetTradingViewIntervalsBy(params *query.IntervalsParams) (query.TradingIntervalsMap, error)
+	GetIntervalsBy(ctx context.Context, params *query.IntervalsParams) (query.IntervalsMap, error)
+	GetTradingViewIntervalsBy(ctx context.Context, params *query.IntervalsParams) (query.TradingIntervalsMap, error)
+}
+
+type retentionPolicy interface {
+	KeptSince(length int, now time.Time) time.Time
+	Coarser(length int) int
 }
 
 type Intervals struct {
-	iRepo  intervalStore
-	logger logrus.FieldLogger
-	mRepo  ordersMarketRepo
+	iRepo     intervalStore
+	logger    logrus.FieldLogger
+	mRepo     ordersMarketRepo
+	retention retentionPolicy
 }
 
-func NewIntervals(iRepo intervalStore, logger logrus.FieldLogger, mRepo ordersMarketRepo) (*Intervals, error) {
-	if iRepo == nil || logger == nil || mRepo == nil {
+func NewIntervals(iRepo intervalStore, logger logrus.FieldLogger, mRepo ordersMarketRepo, retention retentionPolicy) (*Intervals, error) {
+	if iRepo == nil || logger == nil || mRepo == nil || retention == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewIntervalsService")
 	}
 
 	return &Intervals{
-		iRepo:  iRepo,
-		logger: logger.WithField("service", "Dex.IntervalsService"),
-		mRepo:  mRepo,
+		iRepo:     iRepo,
+		logger:    logger.WithField("service", "Dex.IntervalsService"),
+		mRepo:     mRepo,
+		retention: retention,
 	}, nil
 }
 
-func (i *Intervals) GetIntervals(marketId string, length int, limit int) (result []entity.MarketHistoryInterval, err error) {
+func (i *Intervals) GetIntervals(ctx context.Context, marketId string, length int, limit int) (result []entity.MarketHistoryInterval, err error) {
 	l := i.logger.WithField("method", "GetIntervals")
-	market, err := i.mRepo.GetMarket(marketId)
+	market, err := i.mRepo.GetMarket(ctx, marketId)
 	if err != nil {
 		return nil, err
 	}
 
 	if market == nil {
-		return nil, fmt.Errorf("market not found: %s", marketId)
+		return nil, newMarketNotFoundErr(marketId)
 	}
 
 	queryParams := i.getQueryParams(market, length, limit)
-	entries, err := i.iRepo.GetIntervalsBy(queryParams)
+	//the pruned range is served from the coarser intervals
+	for _, params := range i.splitPrunedRange(queryParams) {
+		coarser, err := i.iRepo.GetIntervalsBy(ctx, params)
+		if err != nil {
+			l.WithError(err).Error("failed to get coarser intervals from repo")
+
+			return nil, fmt.Errorf("failed to get intervals: %w", err)
+		}
+		result = append(result, coarser.Elements()...)
+	}
+
+	entries, err := i.iRepo.GetIntervalsBy(ctx, queryParams)
 	if err != nil {
 		l.WithError(err).Error("failed to get intervals from repo")
 
-		return nil, fmt.Errorf("failed to get intervals")
+		return nil, fmt.Errorf("failed to get intervals: %w", err)
 	}
 
 	//if we found all required intervals then return them directly
 	if len(entries) == limit {
-		result = entries.Elements()
+		result = append(result, entries.Elements()...)
 		i.sortIntervals(result)
 
 		return result, nil
@@ -106,28 +125,39 @@ func (i *Intervals) GetIntervals(marketId string, length int, limit int) (result
 	return result, nil
 }
 
-func (i *Intervals) GetTradingViewIntervals(marketId string, length int, limit int) (result []entity.TradingViewInterval, err error) {
+func (i *Intervals) GetTradingViewIntervals(ctx context.Context, marketId string, length int, limit int) (result []entity.TradingViewInterval, err error) {
 	l := i.logger.WithField("method", "GetTradingViewIntervals")
-	market, err := i.mRepo.GetMarket(marketId)
+	market, err := i.mRepo.GetMarket(ctx, marketId)
 	if err != nil {
 		return nil, err
 	}
 
 	if market == nil {
-		return nil, fmt.Errorf("market not found: %s", marketId)
+		return nil, newMarketNotFoundErr(marketId)
 	}
 
 	queryParams := i.getQueryParams(market, length, limit)
-	entries, err := i.iRepo.GetTradingViewIntervalsBy(queryParams)
+	//the pruned range is served from the coarser intervals
+	for _, params := range i.splitPrunedRange(queryParams) {
+		coarser, err := i.iRepo.GetTradingViewIntervalsBy(ctx, params)
+		if err != nil {
+			l.WithError(err).Error("failed to get coarser intervals from repo")
+
+			return nil, fmt.Errorf("failed to get intervals: %w", err)
+		}
+		result = append(result, coarser.Elements()...)
+	}
+
+	entries, err := i.iRepo.GetTradingViewIntervalsBy(ctx, queryParams)
 	if err != nil {
 		l.WithError(err).Error("failed to get intervals from repo")
 
-		return nil, fmt.Errorf("failed to get intervals")
+		return nil, fmt.Errorf("failed to get intervals: %w", err)
 	}
 
 	//if we found all required intervals then return them directly
 	if len(entries) == limit {
-		result = entries.Elements()
+		result = append(result, entries.Elements()...)
 		i.sortTradingViewIntervals(result)
 
 		return result, nil
@@ -195,6 +225,37 @@ func (i *Intervals) getQueryParams(market *entity.Market, length int, limit int)
 	}
 }
 
+// splitPrunedRange moves the start of params after the range pruned by the retention policy and returns the params
+// to get that range from the coarser intervals that are still kept
+func (i *Intervals) splitPrunedRange(params *query.IntervalsParams) (coarser []*query.IntervalsParams) {
+	now := time.Now()
+	keptSince := i.retention.KeptSince(params.Length, now)
+	if keptSince.IsZero() || !params.StartAt.Before(keptSince) {
+		return nil
+	}
+
+	from, to := params.StartAt, keptSince
+	params.StartAt = keptSince
+	for length := i.retention.Coarser(params.Length); length > 0 && from.Before(to); length = i.retention.Coarser(length) {
+		start := from
+		if kept := i.retention.KeptSince(length, now); start.Before(kept) {
+			start = kept
+		}
+
+		if start.Before(to) {
+			coarser = append(coarser, &query.IntervalsParams{
+				MarketId: params.MarketId,
+				Length:   length,
+				StartAt:  start,
+				EndAt:    to,
+			})
+		}
+		to = start
+	}
+
+	return coarser
+}
+
 func (i *Intervals) getIntervalDuration(length int) time.Duration {
 	return time.Duration(length) * time.Minute
 }
@@ -0,0 +1,39 @@
+package dex
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+type Markets struct {
+	mRepo ordersMarketRepo
+}
+
+func NewMarketsService(mRepo ordersMarketRepo) (*Markets, error) {
+	if mRepo == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewMarketsService")
+	}
+
+	return &Markets{mRepo: mRepo}, nil
+}
+
+// GetMarket returns the market or an internal.ErrCodeNotFound error when it does not exist
+func (m *Markets) GetMarket(ctx context.Context, marketId string) (*entity.Market, error) {
+	market, err := m.mRepo.GetMarket(ctx, marketId)
+	if err != nil {
+		return nil, err
+	}
+
+	if market == nil {
+		return nil, newMarketNotFoundErr(marketId)
+	}
+
+	return market, nil
+}
+
+func newMarketNotFoundErr(marketId string) error {
+	return internal.NewNotFoundErr(fmt.Sprintf("market not found: %s", marketId))
+}
@@ -1,6 +1,7 @@
 package dex
 
 import (
+	"context"
 	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
 	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
@@ -17,11 +18,11 @@ type marketOrdersResult interface {
 }
 
 type ordersRepo interface {
-	GetMarketOrdersWithDepth(marketId, orderType string, limit int) ([]entity.MarketOrder, error)
+	GetMarketOrdersWithDepth(ctx context.Context, marketId, orderType string, limit int) ([]entity.MarketOrder, error)
 }
 
 type ordersMarketRepo interface {
-	GetMarket(marketId string) (*entity.Market, error)
+	GetMarket(ctx context.Context, marketId string) (*entity.Market, error)
 }
 
 type OrdersService struct {
@@ -42,17 +43,17 @@ func NewOrdersService(logger logrus.FieldLogger, oRepo ordersRepo, mRepo ordersM
 	}, nil
 }
 
-func (o *OrdersService) GetMarketOrders(marketId string, depth int) (*response.Orders, error) {
-	market, err := o.mRepo.GetMarket(marketId)
+func (o *OrdersService) GetMarketOrders(ctx context.Context, marketId string, depth int) (*response.Orders, error) {
+	market, err := o.mRepo.GetMarket(ctx, marketId)
 	if err != nil {
 		return nil, err
 	}
 
 	if market == nil {
-		return nil, fmt.Errorf("market not found")
+		return nil, newMarketNotFoundErr(marketId)
 	}
 
-	buys, sells, err := o.getMarketOrders(marketId, depth)
+	buys, sells, err := o.getMarketOrders(ctx, marketId, depth)
 	if err != nil {
 		return nil, err
 	}
@@ -67,17 +68,17 @@ func (o *OrdersService) GetMarketOrders(marketId string, depth int) (*response.O
 	return res, nil
 }
 
-func (o *OrdersService) GetCoingeckoMarketOrders(marketId string, depth int) (*response.CoingeckoOrders, error) {
-	market, err := o.mRepo.GetMarket(marketId)
+func (o *OrdersService) GetCoingeckoMarketOrders(ctx context.Context, marketId string, depth int) (*response.CoingeckoOrders, error) {
+	market, err := o.mRepo.GetMarket(ctx, marketId)
 	if err != nil {
 		return nil, err
 	}
 
 	if market == nil {
-		return nil, fmt.Errorf("market not found")
+		return nil, newMarketNotFoundErr(marketId)
 	}
 
-	buys, sells, err := o.getMarketOrders(marketId, depth)
+	buys, sells, err := o.getMarketOrders(ctx, marketId, depth)
 	if err != nil {
 		return nil, err
 	}
@@ -110,7 +111,7 @@ func (o *OrdersService) hydrateResponse(res marketOrdersResult, buys, sells []en
 
 }
 
-func (o *OrdersService) getMarketOrders(marketId string, depth int) (buys, sells []entity.MarketOrder, err error) {
+func (o *OrdersService) getMarketOrders(ctx context.Context, marketId string, depth int) (buys, sells []entity.MarketOrder, err error) {
 	limit := depth / 2
 
 	wg := sync.WaitGroup{}
@@ -119,7 +120,7 @@ func (o *OrdersService) getMarketOrders(marketId string, depth int) (buys, sells
 	go func() {
 		defer wg.Done()
 		var er error
-		buys, er = o.oRepo.GetMarketOrdersWithDepth(marketId, entity.OrderTypeBuy, limit)
+		buys, er = o.oRepo.GetMarketOrdersWithDepth(ctx, marketId, entity.OrderTypeBuy, limit)
 		if er != nil {
 			err = er
 		}
@@ -128,7 +129,7 @@ func (o *OrdersService) getMarketOrders(marketId string, depth int) (buys, sells
 	go func() {
 		defer wg.Done()
 		var er error
-		sells, er = o.oRepo.GetMarketOrdersWithDepth(marketId, entity.OrderTypeSell, limit)
+		sells, er = o.oRepo.GetMarketOrdersWithDepth(ctx, marketId, entity.OrderTypeSell, limit)
 		if er != nil {
 			err = er
 		}
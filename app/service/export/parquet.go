@@ -0,0 +1,322 @@
+package export
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/golang/snappy"
+)
+
+// the subset of the parquet format used by the writer, see https://github.com/apache/parquet-format
+const (
+	parquetMagic     = "PAR1"
+	parquetCreatedBy = "bze-aggregator-api"
+
+	parquetTypeByteArray  = 6
+	parquetRequired       = 0
+	parquetConvertedUtf8  = 0
+	parquetEncodingPlain  = 0
+	parquetEncodingRle    = 3
+	parquetCodecSnappy    = 1
+	parquetPageTypeData   = 0
+	parquetFormatVersion  = 1
+	parquetRowGroupLength = 50_000
+)
+
+// parquetWriter writes every column as a required UTF8 string, like the CSV, so the decimals keep their precision.
+// The rows are buffered and written as a row group every parquetRowGroupLength rows, with one snappy compressed page
+// per column. Flush writes the last row group and the footer, so nothing can be written after it.
+type parquetWriter struct {
+	out    io.Writer
+	offset int64
+
+	columns []string
+	//the values of the current row group, by column
+	values    [][][]byte
+	rows      int
+	rowGroups []parquetRowGroup
+	numRows   int64
+	closed    bool
+}
+
+type parquetRowGroup struct {
+	chunks   []parquetChunk
+	byteSize int64
+	numRows  int64
+}
+
+type parquetChunk struct {
+	offset           int64
+	numValues        int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+func (p *parquetWriter) Write(row response.ExportRow) error {
+	if p.closed {
+		return fmt.Errorf("parquet export already flushed")
+	}
+
+	if p.columns == nil {
+		p.columns = row.Columns()
+		p.values = make([][][]byte, len(p.columns))
+		if err := p.write([]byte(parquetMagic)); err != nil {
+			return err
+		}
+	}
+
+	values := row.Values()
+	if len(values) != len(p.columns) {
+		return fmt.Errorf("expected %d values, got %d", len(p.columns), len(values))
+	}
+
+	for i, v := range values {
+		p.values[i] = append(p.values[i], []byte(v))
+	}
+
+	p.rows++
+	if p.rows == parquetRowGroupLength {
+		return p.writeRowGroup()
+	}
+
+	return nil
+}
+
+func (p *parquetWriter) Flush() error {
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	//an empty export still is a valid file, without columns
+	if p.columns == nil {
+		if err := p.write([]byte(parquetMagic)); err != nil {
+			return err
+		}
+	}
+
+	if p.rows > 0 {
+		if err := p.writeRowGroup(); err != nil {
+			return err
+		}
+	}
+
+	footer := p.fileMetaData()
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	footer = append(footer, parquetMagic...)
+	if err := p.write(footer); err != nil {
+		return err
+	}
+
+	flushOut(p.out)
+
+	return nil
+}
+
+func (p *parquetWriter) writeRowGroup() error {
+	group := parquetRowGroup{numRows: int64(p.rows)}
+	for i, values := range p.values {
+		chunk, err := p.writeColumnChunk(values)
+		if err != nil {
+			return fmt.Errorf("could not write column %s: %w", p.columns[i], err)
+		}
+
+		group.chunks = append(group.chunks, chunk)
+		group.byteSize += chunk.uncompressedSize
+		p.values[i] = values[:0]
+	}
+
+	p.rowGroups = append(p.rowGroups, group)
+	p.numRows += group.numRows
+	p.rows = 0
+
+	return nil
+}
+
+// writeColumnChunk writes the values of a column as a single PLAIN encoded data page. The columns are required, so
+// the page has no repetition and definition levels.
+func (p *parquetWriter) writeColumnChunk(values [][]byte) (parquetChunk, error) {
+	var data []byte
+	for _, v := range values {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(v)))
+		data = append(data, v...)
+	}
+	compressed := snappy.Encode(nil, data)
+
+	t := &thriftWriter{}
+	t.i32(1, parquetPageTypeData)
+	t.i32(2, int32(len(data)))
+	t.i32(3, int32(len(compressed)))
+	t.beginStruct(5)
+	t.i32(1, int32(len(values)))
+	t.i32(2, parquetEncodingPlain)
+	t.i32(3, parquetEncodingRle)
+	t.i32(4, parquetEncodingRle)
+	t.endStruct()
+	header := t.end()
+
+	chunk := parquetChunk{
+		offset:           p.offset,
+		numValues:        int64(len(values)),
+		uncompressedSize: int64(len(header) + len(data)),
+		compressedSize:   int64(len(header) + len(compressed)),
+	}
+
+	if err := p.write(header); err != nil {
+		return chunk, err
+	}
+
+	return chunk, p.write(compressed)
+}
+
+func (p *parquetWriter) fileMetaData() []byte {
+	t := &thriftWriter{}
+	t.i32(1, parquetFormatVersion)
+
+	t.beginList(2, thriftStruct, len(p.columns)+1)
+	t.beginElement()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(p.columns)))
+	t.endStruct()
+	for _, name := range p.columns {
+		t.beginElement()
+		t.i32(1, parquetTypeByteArray)
+		t.i32(3, parquetRequired)
+		t.binary(4, name)
+		t.i32(6, parquetConvertedUtf8)
+		//logicalType: STRING
+		t.beginStruct(10)
+		t.beginStruct(1)
+		t.endStruct()
+		t.endStruct()
+		t.endStruct()
+	}
+
+	t.i64(3, p.numRows)
+
+	t.beginList(4, thriftStruct, len(p.rowGroups))
+	for _, group := range p.rowGroups {
+		t.beginElement()
+		t.beginList(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			t.beginElement()
+			t.i64(2, chunk.offset)
+			t.beginStruct(3)
+			t.i32(1, parquetTypeByteArray)
+			t.beginList(2, thriftI32, 1)
+			t.listI32(parquetEncodingPlain)
+			t.beginList(3, thriftBinary, 1)
+			t.listBinary(p.columns[i])
+			t.i32(4, parquetCodecSnappy)
+			t.i64(5, chunk.numValues)
+			t.i64(6, chunk.uncompressedSize)
+			t.i64(7, chunk.compressedSize)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64(2, group.byteSize)
+		t.i64(3, group.numRows)
+		t.endStruct()
+	}
+
+	t.binary(6, parquetCreatedBy)
+
+	return t.end()
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.out.Write(b)
+	p.offset += int64(n)
+
+	return err
+}
+
+// the types of the thrift compact protocol used by the parquet metadata
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the thrift compact protocol. The fields of a struct must be written in the
+// order of their ids.
+type thriftWriter struct {
+	buf []byte
+	//the id of the last field of the current struct and of the structs it is nested in
+	last  int16
+	stack []int16
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.buf = binary.AppendVarint(t.buf, int64(id))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.listI32(v)
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.buf = binary.AppendVarint(t.buf, v)
+}
+
+func (t *thriftWriter) binary(id int16, v string) {
+	t.field(id, thriftBinary)
+	t.listBinary(v)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.beginElement()
+}
+
+// beginElement starts a struct written as an element of a list
+func (t *thriftWriter) beginElement() {
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf = append(t.buf, 0)
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+func (t *thriftWriter) beginList(id int16, elemType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elemType)
+
+		return
+	}
+
+	t.buf = append(t.buf, 0xf0|elemType)
+	t.buf = binary.AppendUvarint(t.buf, uint64(size))
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	//binary.AppendVarint zigzag encodes the value like the compact protocol
+	t.buf = binary.AppendVarint(t.buf, int64(v))
+}
+
+func (t *thriftWriter) listBinary(v string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(v)))
+	t.buf = append(t.buf, v...)
+}
+
+// end closes the top level struct and returns the encoded bytes
+func (t *thriftWriter) end() []byte {
+	return append(t.buf, 0)
+}
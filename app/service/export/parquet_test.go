@@ -0,0 +1,278 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/golang/snappy"
+)
+
+// thriftReader decodes the thrift compact protocol into maps of field id to value, enough to check the files
+// written by parquetWriter without a parquet library
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.buf[r.pos]
+	r.pos++
+
+	return b
+}
+
+func (r *thriftReader) varint() int64 {
+	v, n := binary.Varint(r.buf[r.pos:])
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid varint at %d", r.pos))
+	}
+	r.pos += n
+
+	return v
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid uvarint at %d", r.pos))
+	}
+	r.pos += n
+
+	return v
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		size := int(r.uvarint())
+		v := string(r.buf[r.pos : r.pos+size])
+		r.pos += size
+
+		return v
+	case thriftList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+
+		return list
+	case thriftStruct:
+		return r.structValue()
+	}
+
+	panic(fmt.Sprintf("unexpected thrift type %d at %d", typ, r.pos))
+}
+
+func (r *thriftReader) structValue() map[int16]any {
+	result := make(map[int16]any)
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return result
+		}
+
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.varint())
+		}
+		result[id] = r.value(header & 0x0f)
+		last = id
+	}
+}
+
+type parquetFile struct {
+	data     []byte
+	metadata map[int16]any
+}
+
+func readParquet(t *testing.T, data []byte) *parquetFile {
+	t.Helper()
+
+	if len(data) < 12 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatalf("missing the parquet magic: %q", data)
+	}
+
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-8-size : len(data)-8]
+	r := &thriftReader{buf: footer}
+	metadata := r.structValue()
+	if r.pos != len(footer) {
+		t.Fatalf("footer has %d bytes, decoded %d", len(footer), r.pos)
+	}
+
+	return &parquetFile{data: data, metadata: metadata}
+}
+
+func (f *parquetFile) columns() []string {
+	var result []string
+	for _, e := range f.metadata[2].([]any)[1:] {
+		result = append(result, e.(map[int16]any)[4].(string))
+	}
+
+	return result
+}
+
+// rows returns the values of every row group, decoded from the pages
+func (f *parquetFile) rows(t *testing.T) [][]string {
+	t.Helper()
+
+	var result [][]string
+	for _, g := range f.metadata[4].([]any) {
+		group := g.(map[int16]any)
+		chunks := group[1].([]any)
+		groupRows := make([][]string, group[3].(int64))
+		for _, c := range chunks {
+			meta := c.(map[int16]any)[3].(map[int16]any)
+			r := &thriftReader{buf: f.data, pos: int(meta[9].(int64))}
+			header := r.structValue()
+			dataHeader := header[5].(map[int16]any)
+			if dataHeader[1].(int64) != int64(len(groupRows)) {
+				t.Fatalf("page has %d values, expected %d", dataHeader[1], len(groupRows))
+			}
+
+			compressed := f.data[r.pos : r.pos+int(header[3].(int64))]
+			page, err := snappy.Decode(nil, compressed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page) != int(header[2].(int64)) {
+				t.Fatalf("page has %d bytes, expected %d", len(page), header[2])
+			}
+			if size := int64(r.pos-int(meta[9].(int64))) + int64(len(compressed)); size != meta[7].(int64) {
+				t.Fatalf("column chunk has %d bytes, expected %d", size, meta[7])
+			}
+
+			for i := range groupRows {
+				size := int(binary.LittleEndian.Uint32(page))
+				groupRows[i] = append(groupRows[i], string(page[4:4+size]))
+				page = page[4+size:]
+			}
+		}
+		result = append(result, groupRows...)
+	}
+
+	return result
+}
+
+type wideRow struct {
+	id int
+}
+
+func (w wideRow) Columns() []string {
+	var result []string
+	for i := 0; i < 20; i++ {
+		result = append(result, fmt.Sprintf("column_%d", i))
+	}
+
+	return result
+}
+
+func (w wideRow) Values() []string {
+	var result []string
+	for i := 0; i < 20; i++ {
+		result = append(result, fmt.Sprintf("%d-%d", w.id, i))
+	}
+
+	return result
+}
+
+func TestParquetWriterWritesTheRows(t *testing.T) {
+	trades := []*response.ExportTrade{
+		{OrderId: 1, MarketId: "ubze/uusdc", OrderType: "buy", Price: "0.00123", BaseVolume: "100", QuoteVolume: "0.123", ExecutedAt: "2024-05-01T10:00:00Z", Maker: "bze1maker", Taker: "bze1taker"},
+		{OrderId: 2, MarketId: "ubze/uusdc", OrderType: "sell", Price: "0.0012", BaseVolume: "5.5", QuoteVolume: "0.0066", ExecutedAt: "2024-05-01T10:01:00Z"},
+	}
+
+	var out bytes.Buffer
+	w, err := NewWriter(FormatParquet, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trade := range trades {
+		if err = w.Write(trade); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	f := readParquet(t, out.Bytes())
+	if f.metadata[3].(int64) != 2 {
+		t.Fatalf("expected 2 rows, got %d", f.metadata[3])
+	}
+
+	columns := f.columns()
+	if fmt.Sprint(columns) != fmt.Sprint(trades[0].Columns()) {
+		t.Fatalf("expected the columns %v, got %v", trades[0].Columns(), columns)
+	}
+
+	rows := f.rows(t)
+	for i, trade := range trades {
+		if fmt.Sprint(rows[i]) != fmt.Sprint(trade.Values()) {
+			t.Errorf("row %d: expected %v, got %v", i, trade.Values(), rows[i])
+		}
+	}
+}
+
+func TestParquetWriterSplitsTheRowGroups(t *testing.T) {
+	var out bytes.Buffer
+	w, _ := NewWriter(FormatParquet, &out)
+
+	total := parquetRowGroupLength + 10
+	for i := 0; i < total; i++ {
+		if err := w.Write(wideRow{id: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	f := readParquet(t, out.Bytes())
+	if groups := len(f.metadata[4].([]any)); groups != 2 {
+		t.Fatalf("expected 2 row groups, got %d", groups)
+	}
+	if len(f.columns()) != 20 {
+		t.Fatalf("expected 20 columns, got %v", f.columns())
+	}
+
+	rows := f.rows(t)
+	if len(rows) != total {
+		t.Fatalf("expected %d rows, got %d", total, len(rows))
+	}
+	for _, i := range []int{0, parquetRowGroupLength - 1, parquetRowGroupLength, total - 1} {
+		if rows[i][19] != strconv.Itoa(i)+"-19" {
+			t.Errorf("row %d: unexpected values %v", i, rows[i])
+		}
+	}
+}
+
+func TestParquetWriterEmptyExport(t *testing.T) {
+	var out bytes.Buffer
+	w, _ := NewWriter(FormatParquet, &out)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	f := readParquet(t, out.Bytes())
+	if f.metadata[3].(int64) != 0 || len(f.columns()) != 0 {
+		t.Fatalf("expected an empty file, got %v", f.metadata)
+	}
+
+	if err := w.Write(wideRow{}); err == nil {
+		t.Fatal("expected an error writing after the footer")
+	}
+}
@@ -0,0 +1,128 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	//FormatParquet is only written by the CLI, the file can't be read before its footer is written at the end
+	FormatParquet = "parquet"
+
+	//rows buffered before they are sent to the client
+	flushEvery = 1000
+)
+
+// Formats returns the supported export formats
+func Formats() []string {
+	return []string{FormatCSV, FormatNDJSON, FormatParquet}
+}
+
+// ContentType returns the content type of the given format
+func ContentType(format string) string {
+	switch format {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+// Writer writes the exported rows in one format. Flush must be called after the last row.
+type Writer interface {
+	Write(row response.ExportRow) error
+	Flush() error
+}
+
+// NewWriter returns the writer of the given format. When out is an http.Flusher the rows are sent every flushEvery rows.
+func NewWriter(format string, out io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(out), out: out}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(out), out: out}, nil
+	case FormatParquet:
+		return &parquetWriter{out: out}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+type csvWriter struct {
+	w   *csv.Writer
+	out io.Writer
+
+	rows int
+}
+
+func (c *csvWriter) Write(row response.ExportRow) error {
+	if c.rows == 0 {
+		if err := c.w.Write(row.Columns()); err != nil {
+			return err
+		}
+	}
+
+	if err := c.w.Write(row.Values()); err != nil {
+		return err
+	}
+
+	c.rows++
+	if c.rows%flushEvery == 0 {
+		return c.Flush()
+	}
+
+	return nil
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+
+	flushOut(c.out)
+
+	return nil
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+	out io.Writer
+
+	rows int
+}
+
+func (n *ndjsonWriter) Write(row response.ExportRow) error {
+	//the encoder ends every value with a new line
+	if err := n.enc.Encode(row); err != nil {
+		return err
+	}
+
+	n.rows++
+	if n.rows%flushEvery == 0 {
+		return n.Flush()
+	}
+
+	return nil
+}
+
+func (n *ndjsonWriter) Flush() error {
+	flushOut(n.out)
+
+	return nil
+}
+
+func flushOut(out io.Writer) {
+	if f, ok := out.(http.Flusher); ok {
+		f.Flush()
+	}
+}
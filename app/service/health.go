@@ -1,37 +1,39 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
 	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/nodepool"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	marketHealthCacheKey   = "health:mh"
-	aggHealthCacheKey      = "health:agg"
-	marketHealthTtlMinutes = 10
-
-	nodesAllowedHeightDiff = 2
+	marketHealthCacheKey = "health:mh"
+	aggHealthCacheKey    = "health:agg"
 )
 
 type MarketHistoryProvider interface {
-	GetMarketHistory(marketId string, limit int) ([]dto.HistoryOrder, error)
+	GetMarketHistory(ctx context.Context, marketId string, limit int) ([]dto.HistoryOrder, error)
 }
 
 type NodeInfoClient interface {
-	GetStatus() (*coretypes.ResultStatus, error)
+	GetStatus(ctx context.Context) (*coretypes.ResultStatus, error)
 }
 
 type internalHistoryProvider interface {
-	GetHistoryBy(params request.HistoryParams) ([]entity.MarketHistory, error)
+	GetHistoryBy(ctx context.Context, params request.HistoryParams) ([]entity.MarketHistory, error)
 }
 
 type Health struct {
@@ -42,10 +44,15 @@ type Health struct {
 	internalHistoryProvider internalHistoryProvider
 
 	nodesPool map[string]NodeInfoClient
+	cacheTtl  time.Duration
+
+	//a node more blocks behind the highest one or answering slower (when > 0) is unhealthy
+	maxHeightDiff int64
+	maxLatency    time.Duration
 }
 
-func NewHealthService(logger logrus.FieldLogger, cache Cache, provider MarketHistoryProvider, internalHistoryProvider internalHistoryProvider, nodes map[string]NodeInfoClient) (*Health, error) {
-	if logger == nil || cache == nil || provider == nil || internalHistoryProvider == nil {
+func NewHealthService(logger logrus.FieldLogger, cache Cache, provider MarketHistoryProvider, internalHistoryProvider internalHistoryProvider, nodes map[string]NodeInfoClient, cacheTtl time.Duration, maxHeightDiff int64, maxLatency time.Duration) (*Health, error) {
+	if logger == nil || cache == nil || provider == nil || internalHistoryProvider == nil || maxHeightDiff < 0 {
 		return nil, internal.NewInvalidDependenciesErr("NewHealthService")
 	}
 
@@ -55,17 +62,20 @@ func NewHealthService(logger logrus.FieldLogger, cache Cache, provider MarketHis
 		provider:                provider,
 		internalHistoryProvider: internalHistoryProvider,
 		nodesPool:               nodes,
+		cacheTtl:                cacheTtl,
+		maxHeightDiff:           maxHeightDiff,
+		maxLatency:              maxLatency,
 	}, nil
 }
 
-func (h *Health) GetMarketHealth(marketId string, minutesAgo int) dto.MarketHealth {
+func (h *Health) GetMarketHealth(ctx context.Context, marketId string, minutesAgo int) dto.MarketHealth {
 	cached := h.getCachedMarketHealth(marketId, minutesAgo)
 	if cached != nil {
 		return *cached
 	}
 
 	var mh dto.MarketHealth
-	marketHist, err := h.provider.GetMarketHistory(marketId, 1)
+	marketHist, err := h.provider.GetMarketHistory(ctx, marketId, 1)
 	if err != nil {
 		h.logger.WithError(err).Error("error getting market history")
 
@@ -91,7 +101,7 @@ func (h *Health) GetMarketHealth(marketId string, minutesAgo int) dto.MarketHeal
 		return mh
 	}
 
-	err = h.cache.Set(h.getMarketHealthCacheKey(marketId), toCache, marketHealthTtlMinutes*time.Minute)
+	err = h.cache.Set(h.getMarketHealthCacheKey(marketId), toCache, h.cacheTtl)
 	if err != nil {
 		h.logger.WithError(err).Error("error caching market health")
 	}
@@ -139,7 +149,7 @@ func (h *Health) getAggregatorHealthCacheKey() string {
 	return aggHealthCacheKey
 }
 
-func (h *Health) GetAggregatorHealth(minutesAgo int) dto.AggregatorHealth {
+func (h *Health) GetAggregatorHealth(ctx context.Context, minutesAgo int) dto.AggregatorHealth {
 	cached := h.getCachedAggregatorHealth(minutesAgo)
 	if cached != nil {
 		return *cached
@@ -152,7 +162,7 @@ func (h *Health) GetAggregatorHealth(minutesAgo int) dto.AggregatorHealth {
 		StartTime: minDateNeeded.UnixMilli(),
 		EndTime:   currentTime.UnixMilli(),
 	}
-	marketHist, err := h.internalHistoryProvider.GetHistoryBy(histParams)
+	marketHist, err := h.internalHistoryProvider.GetHistoryBy(ctx, histParams)
 
 	var mh dto.AggregatorHealth
 	if err != nil {
@@ -176,7 +186,7 @@ func (h *Health) GetAggregatorHealth(minutesAgo int) dto.AggregatorHealth {
 		return mh
 	}
 
-	err = h.cache.Set(h.getAggregatorHealthCacheKey(), toCache, marketHealthTtlMinutes*time.Minute)
+	err = h.cache.Set(h.getAggregatorHealthCacheKey(), toCache, h.cacheTtl)
 	if err != nil {
 		h.logger.WithError(err).Error("error caching market health")
 	}
@@ -216,56 +226,90 @@ func (h *Health) getCachedAggregatorHealth(minutesAgo int) *dto.AggregatorHealth
 	return nil
 }
 
-func (h *Health) GetNodesHealth() dto.NodesHealth {
+// GetNodesHealth queries all the health nodes and compares each of them with the highest one
+func (h *Health) GetNodesHealth(ctx context.Context) dto.NodesHealth {
 	if len(h.nodesPool) == 0 {
-		return dto.NodesHealth{}
+		return dto.NodesHealth{Nodes: []dto.NodeHealth{}}
 	}
 	l := h.logger.WithField("func", "GetNodesHealth")
 
-	resp := make(map[string]*coretypes.ResultStatus, len(h.nodesPool))
+	nodes := make([]dto.NodeHealth, 0, len(h.nodesPool))
 	var wg sync.WaitGroup
 	var mx sync.Mutex
-	var errorsStr string
 	for name, infoClient := range h.nodesPool {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			info, err := infoClient.GetStatus()
-			mx.Lock()
-			defer mx.Unlock()
-			if err != nil {
-				errorsStr += fmt.Sprintf("failed to query: %s;", name)
+			start := time.Now()
+			info, err := infoClient.GetStatus(ctx)
+			node := dto.NodeHealth{Name: name, LatencyMs: time.Since(start).Milliseconds()}
+			switch {
+			case err != nil:
+				node.Error = fmt.Sprintf("failed to query: %s", err)
 				l.WithError(err).WithField("name", name).Error("failed to get latest block")
-
-				return
+			case info == nil:
+				node.Error = "no info found"
+			default:
+				node.Height = info.SyncInfo.LatestBlockHeight
+				node.CatchingUp = info.SyncInfo.CatchingUp
+				metrics.SetNodeHeight(name, node.Height)
 			}
 
-			resp[name] = info
+			mx.Lock()
+			defer mx.Unlock()
+			nodes = append(nodes, node)
 		}()
 	}
 
 	wg.Wait()
 
-	for name, info := range resp {
-		if info == nil {
-			errorsStr += fmt.Sprintf("no info found for: %s;", name)
-			continue
+	//the lag is computed like the node pools do, from the nodes that answered
+	heights := make(map[string]int64, len(nodes))
+	for _, node := range nodes {
+		if node.Error == "" {
+			heights[node.Name] = node.Height
 		}
+	}
 
-		for name2, info2 := range resp {
-			if info2 == nil || name == name2 {
-				continue
-			}
+	result := dto.NodesHealth{IsHealthy: true, Nodes: nodes}
+	lags := make(map[string]int64, len(heights))
+	for _, lag := range nodepool.NodesLag(heights) {
+		result.MaxHeight = lag.Expected
+		lags[lag.Node] = lag.Blocks()
+	}
 
-			diff := info.SyncInfo.LatestBlockHeight - info2.SyncInfo.LatestBlockHeight
-			if diff > nodesAllowedHeightDiff {
-				errorsStr += fmt.Sprintf("%s is behind %s. Expected height %d, found %d", name2, name, info.SyncInfo.LatestBlockHeight, info2.SyncInfo.LatestBlockHeight)
-			}
+	var errs []string
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Error == "" {
+			node.Lag = lags[node.Name]
+			node.Error = h.nodeError(node)
+		}
+
+		node.IsHealthy = node.Error == ""
+		if !node.IsHealthy {
+			result.IsHealthy = false
+			errs = append(errs, fmt.Sprintf("%s: %s", node.Name, node.Error))
 		}
 	}
 
-	return dto.NodesHealth{
-		IsHealthy: errorsStr == "",
-		Errors:    errorsStr,
+	slices.SortFunc(nodes, func(a, b dto.NodeHealth) int { return strings.Compare(a.Name, b.Name) })
+	slices.Sort(errs)
+	result.Errors = strings.Join(errs, ";")
+
+	return result
+}
+
+// nodeError returns why a node that answered is unhealthy, or an empty string
+func (h *Health) nodeError(node *dto.NodeHealth) string {
+	switch {
+	case node.CatchingUp:
+		return "catching up"
+	case node.Lag > h.maxHeightDiff:
+		return fmt.Sprintf("%d blocks behind the highest node", node.Lag)
+	case h.maxLatency > 0 && node.LatencyMs > h.maxLatency.Milliseconds():
+		return fmt.Sprintf("answered in %dms, more than %dms", node.LatencyMs, h.maxLatency.Milliseconds())
 	}
+
+	return ""
 }
@@ -0,0 +1,202 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+)
+
+// the sources of the balance groups
+const (
+	BalanceGroupSourceConfig = "config"
+	BalanceGroupSourceApi    = "api"
+)
+
+type balanceWatchStorage interface {
+	GetAll(ctx context.Context) ([]entity.BalanceWatch, error)
+	GetGroup(ctx context.Context, group string) ([]entity.BalanceWatch, error)
+	ReplaceGroup(ctx context.Context, group string, list []entity.BalanceWatch) error
+	DeleteGroup(ctx context.Context, group string) (bool, error)
+}
+
+type balancesChecker interface {
+	CheckBalances(ctx context.Context, params *request.BalanceHealthParams) []dto.AddressHealthCheck
+}
+
+// BalanceGroups manages the named lists of balances checked together. The groups of the config are read only,
+// the others are saved through the admin API.
+type BalanceGroups struct {
+	storage      balanceWatchStorage
+	checker      balancesChecker
+	configGroups map[string][]config.BalanceWatch
+}
+
+func NewBalanceGroups(storage balanceWatchStorage, checker balancesChecker, configGroups map[string][]config.BalanceWatch) (*BalanceGroups, error) {
+	if storage == nil || checker == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewBalanceGroups")
+	}
+
+	return &BalanceGroups{
+		storage:      storage,
+		checker:      checker,
+		configGroups: configGroups,
+	}, nil
+}
+
+// GetGroups returns all the groups sorted by name
+func (g *BalanceGroups) GetGroups(ctx context.Context) ([]response.BalanceGroup, error) {
+	stored, err := g.storage.GetAll(ctx)
+	if err != nil {
+		return nil, internal.NewUnavailableErr("could not get the balance groups", err)
+	}
+
+	byName := make(map[string]*response.BalanceGroup)
+	for _, w := range stored {
+		group, ok := byName[w.GroupName]
+		if !ok {
+			group = &response.BalanceGroup{Name: w.GroupName, Source: BalanceGroupSourceApi}
+			byName[w.GroupName] = group
+		}
+		group.Addresses = append(group.Addresses, groupAddressFromEntity(w))
+	}
+
+	result := make([]response.BalanceGroup, 0, len(byName)+len(g.configGroups))
+	for name := range g.configGroups {
+		result = append(result, g.configGroup(name))
+	}
+
+	for name, group := range byName {
+		//a group saved before being added to the config is hidden by it
+		if _, ok := g.configGroups[name]; !ok {
+			result = append(result, *group)
+		}
+	}
+	slices.SortFunc(result, func(a, b response.BalanceGroup) int { return strings.Compare(a.Name, b.Name) })
+
+	return result, nil
+}
+
+func (g *BalanceGroups) GetGroup(ctx context.Context, name string) (*response.BalanceGroup, error) {
+	if _, ok := g.configGroups[name]; ok {
+		group := g.configGroup(name)
+
+		return &group, nil
+	}
+
+	stored, err := g.storage.GetGroup(ctx, name)
+	if err != nil {
+		return nil, internal.NewUnavailableErr("could not get the balance group", err)
+	}
+
+	if len(stored) == 0 {
+		return nil, internal.NewNotFoundErr(fmt.Sprintf("balance group %s not found", name))
+	}
+
+	group := &response.BalanceGroup{Name: name, Source: BalanceGroupSourceApi}
+	for _, w := range stored {
+		group.Addresses = append(group.Addresses, groupAddressFromEntity(w))
+	}
+
+	return group, nil
+}
+
+// SaveGroup creates the group or replaces all its addresses
+func (g *BalanceGroups) SaveGroup(ctx context.Context, name string, params *request.BalanceHealthParams) (*response.BalanceGroup, error) {
+	if _, ok := g.configGroups[name]; ok {
+		return nil, internal.NewValidationErr(fmt.Sprintf("balance group %s is defined in the config and can not be changed", name))
+	}
+
+	if len(params.Addresses) == 0 {
+		return nil, internal.NewValidationErr("at least one address is required")
+	}
+
+	if err := params.Validate(); err != nil {
+		return nil, internal.NewValidationErr(err.Error())
+	}
+
+	now := time.Now().UTC()
+	seen := make(map[string]bool, len(params.Addresses))
+	list := make([]entity.BalanceWatch, 0, len(params.Addresses))
+	for _, a := range params.Addresses {
+		key := a.Address + ":" + a.Denom
+		if seen[key] {
+			return nil, internal.NewValidationErr(fmt.Sprintf("%s is listed more than once for %s", a.Address, a.Denom))
+		}
+		seen[key] = true
+
+		list = append(list, entity.BalanceWatch{
+			GroupName: name,
+			Address:   a.Address,
+			Denom:     a.Denom,
+			MinAmount: a.MinAmount.String(),
+			CreatedAt: now,
+		})
+	}
+
+	if err := g.storage.ReplaceGroup(ctx, name, list); err != nil {
+		return nil, internal.NewUnavailableErr("could not save the balance group", err)
+	}
+
+	return g.GetGroup(ctx, name)
+}
+
+func (g *BalanceGroups) DeleteGroup(ctx context.Context, name string) error {
+	if _, ok := g.configGroups[name]; ok {
+		return internal.NewValidationErr(fmt.Sprintf("balance group %s is defined in the config and can not be deleted", name))
+	}
+
+	deleted, err := g.storage.DeleteGroup(ctx, name)
+	if err != nil {
+		return internal.NewUnavailableErr("could not delete the balance group", err)
+	}
+
+	if !deleted {
+		return internal.NewNotFoundErr(fmt.Sprintf("balance group %s not found", name))
+	}
+
+	return nil
+}
+
+// CheckGroup checks the balances of all the addresses of the group
+func (g *BalanceGroups) CheckGroup(ctx context.Context, name string) (*response.BalanceHealthResponse, error) {
+	group, err := g.GetGroup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &request.BalanceHealthParams{Addresses: make([]request.AddressBalanceParams, 0, len(group.Addresses))}
+	for _, a := range group.Addresses {
+		params.Addresses = append(params.Addresses, request.AddressBalanceParams{
+			Address:   a.Address,
+			Denom:     a.Denom,
+			MinAmount: json.Number(a.MinAmount),
+		})
+	}
+
+	result := response.NewBalanceHealthResponse(g.checker.CheckBalances(ctx, params))
+
+	return &result, nil
+}
+
+func (g *BalanceGroups) configGroup(name string) response.BalanceGroup {
+	group := response.BalanceGroup{Name: name, Source: BalanceGroupSourceConfig}
+	for _, w := range g.configGroups[name] {
+		group.Addresses = append(group.Addresses, response.BalanceGroupAddress{Address: w.Address, Denom: w.Denom, MinAmount: w.MinAmount})
+	}
+
+	return group
+}
+
+func groupAddressFromEntity(w entity.BalanceWatch) response.BalanceGroupAddress {
+	return response.BalanceGroupAddress{Address: w.Address, Denom: w.Denom, MinAmount: w.MinAmount}
+}
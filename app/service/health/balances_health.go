@@ -1,71 +1,205 @@
 package health
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"cosmossdk.io/math"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/chain_registry"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/query"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/request"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze-aggregator-api/server/config"
 	cmtjson "github.com/cometbft/cometbft/libs/json"
+	"github.com/sirupsen/logrus"
 )
 
 const (
-	balancesRoute = "/cosmos/bank/v1beta1/balances"
+	balancesRoute    = "/cosmos/bank/v1beta1/balances"
+	balancesCacheKey = "balances:%s"
 )
 
+type balancesCache interface {
+	Get(key string) ([]byte, error)
+	Set(key string, data []byte, expiration time.Duration) error
+}
+
+type assetsRegistry interface {
+	GetAssets(ctx context.Context) ([]chain_registry.ChainRegistryAsset, error)
+}
+
 type BalancesHealth struct {
-	endpoints config.PrefixedEndpoints
+	logger     logrus.FieldLogger
+	cache      balancesCache
+	registry   assetsRegistry
+	endpoints  config.PrefixedEndpoints
+	httpClient *http.Client
+	cacheTtl   time.Duration
+
+	//the address prefixes of the endpoints, longest first so bze1 wins over bz
+	prefixes []string
+
+	//the last assets read from the registry, used while it fails
+	assetsMx   sync.RWMutex
+	lastAssets []chain_registry.ChainRegistryAsset
 }
 
-func NewBalancesHealth(endpoints config.PrefixedEndpoints) (*BalancesHealth, error) {
+func NewBalancesHealth(logger logrus.FieldLogger, cache balancesCache, registry assetsRegistry, endpoints config.PrefixedEndpoints, timeout, cacheTtl time.Duration) (*BalancesHealth, error) {
+	if logger == nil || cache == nil || registry == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewBalancesHealth")
+	}
+
+	prefixes := make([]string, 0, len(endpoints))
+	for prefix := range endpoints {
+		prefixes = append(prefixes, prefix)
+	}
+	slices.SortFunc(prefixes, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+
+		return strings.Compare(a, b)
+	})
+
 	return &BalancesHealth{
-		endpoints: endpoints,
+		logger:     logger.WithField("service", "Health.BalancesHealth"),
+		cache:      cache,
+		registry:   registry,
+		endpoints:  endpoints,
+		httpClient: tracing.NewHTTPClient(timeout),
+		cacheTtl:   cacheTtl,
+		prefixes:   prefixes,
 	}, nil
 }
 
-func (b *BalancesHealth) CheckBalances(params *request.BalanceHealthParams) []dto.AddressHealthCheck {
+// CheckBalances checks the addresses in parallel and returns their results in the order of the params
+func (b *BalancesHealth) CheckBalances(ctx context.Context, params *request.BalanceHealthParams) []dto.AddressHealthCheck {
 	wg := sync.WaitGroup{}
-	mx := sync.RWMutex{}
-	var response []dto.AddressHealthCheck
-	for _, p := range params.Addresses {
+	response := make([]dto.AddressHealthCheck, len(params.Addresses))
+	for i, p := range params.Addresses {
 		wg.Add(1)
-		go func(p request.AddressBalanceParams) {
+		go func() {
 			defer wg.Done()
-			result := dto.AddressHealthCheck{
-				Address:   p.Address,
-				IsHealthy: true,
-				Error:     "",
-			}
+			response[i] = b.checkBalance(ctx, p)
+		}()
+	}
 
-			minAmt := math.NewInt(p.MinAmount)
-			balance, err := b.getAddressDenomBalance(p.Address, p.Denom)
-			if err != nil {
-				result.IsHealthy = false
-				result.Error = err.Error()
-			} else if balance.LT(minAmt) {
+	wg.Wait()
+
+	return response
+}
+
+// checkBalance compares the balance with the min amount, both in the base denom
+func (b *BalancesHealth) checkBalance(ctx context.Context, p request.AddressBalanceParams) dto.AddressHealthCheck {
+	result := dto.AddressHealthCheck{
+		Address:   p.Address,
+		Denom:     p.Denom,
+		MinAmount: p.MinAmount.String(),
+		Status:    dto.BalanceStatusOk,
+		IsHealthy: true,
+	}
+
+	denom, minAmt, err := b.baseAmount(ctx, p.Denom, p.MinAmount.String())
+	if err == nil {
+		result.Denom = denom
+		result.MinAmount = minAmt.String()
+
+		var balance math.Int
+		balance, err = b.getAddressDenomBalance(ctx, p.Address, denom)
+		if err == nil {
+			result.Balance = balance.String()
+			if balance.LT(minAmt) {
+				result.Status = dto.BalanceStatusLow
 				result.IsHealthy = false
 				result.Error = fmt.Sprintf("balance [%s] is less than min amount [%s]", balance.String(), minAmt.String())
 			}
+		}
+	}
 
-			mx.Lock()
-			defer mx.Unlock()
-			response = append(response, result)
-		}(p)
+	if err != nil {
+		result.Status = dto.BalanceStatusError
+		result.IsHealthy = false
+		result.Error = err.Error()
 	}
 
-	wg.Wait()
+	return result
+}
 
-	return response
+// baseAmount converts an amount of a display denom of the chain registry (e.g. 1.5 bze) to its base denom
+// (1500000 ubze). Denoms missing from the registry are considered base denoms.
+// When the registry fails the last assets read are used, and without them the denom is considered a base denom:
+// only a decimal amount, which needs a display denom conversion, fails the check.
+func (b *BalancesHealth) baseAmount(ctx context.Context, denom, amount string) (string, math.Int, error) {
+	dec, err := math.LegacyNewDecFromStr(amount)
+	if err != nil || dec.IsNegative() {
+		return "", math.Int{}, fmt.Errorf("invalid min amount [%s]", amount)
+	}
+
+	assets, registryErr := b.getAssets(ctx)
+	if registryErr != nil {
+		b.logger.WithError(registryErr).WithField("denom", denom).Warn("could not get the registry assets, resolving the denom without them")
+	}
+
+	baseDenom, exponent := resolveDenom(assets, denom)
+	dec = dec.Mul(math.LegacyNewDec(10).Power(uint64(exponent)))
+	if !dec.IsInteger() {
+		if registryErr != nil && exponent == 0 {
+			return "", math.Int{}, fmt.Errorf("could not resolve denom [%s]: %w", denom, registryErr)
+		}
+
+		return "", math.Int{}, fmt.Errorf("min amount [%s] has more decimals than [%s] allows", amount, denom)
+	}
+
+	return baseDenom, dec.TruncateInt(), nil
 }
 
-func (b *BalancesHealth) getAddressDenomBalance(address, denom string) (math.Int, error) {
-	allBalances, err := b.getAddressBalance(address)
+// getAssets returns the registry assets, or the last ones read along with the error when the registry fails
+func (b *BalancesHealth) getAssets(ctx context.Context) ([]chain_registry.ChainRegistryAsset, error) {
+	assets, err := b.registry.GetAssets(ctx)
+	if err != nil {
+		b.assetsMx.RLock()
+		defer b.assetsMx.RUnlock()
+
+		return b.lastAssets, err
+	}
+
+	b.assetsMx.Lock()
+	defer b.assetsMx.Unlock()
+	b.lastAssets = assets
+
+	return assets, nil
+}
+
+// resolveDenom returns the base denom and the exponent of a denom unit (or alias) of the chain registry
+func resolveDenom(assets []chain_registry.ChainRegistryAsset, denom string) (string, int) {
+	for _, a := range assets {
+		if a.Base == denom {
+			return denom, 0
+		}
+	}
+
+	for _, a := range assets {
+		for _, unit := range a.DenomUnits {
+			matches := func(d string) bool { return strings.EqualFold(d, denom) }
+			if matches(unit.Denom) || slices.ContainsFunc(unit.Aliases, matches) {
+				return a.Base, unit.Exponent
+			}
+		}
+	}
+
+	return denom, 0
+}
+
+func (b *BalancesHealth) getAddressDenomBalance(ctx context.Context, address, denom string) (math.Int, error) {
+	allBalances, err := b.getAddressBalance(ctx, address)
 	zero := math.ZeroInt()
 	if err != nil {
 		return zero, err
@@ -85,13 +219,59 @@ func (b *BalancesHealth) getAddressDenomBalance(address, denom string) (math.Int
 	return zero, nil
 }
 
-func (b *BalancesHealth) getAddressBalance(address string) (*query.BalancesResponse, error) {
+// getAddressBalance returns all the balances of the address, cached to spare the REST nodes when many checks
+// (API requests, alerting) watch the same address
+func (b *BalancesHealth) getAddressBalance(ctx context.Context, address string) (*query.BalancesResponse, error) {
+	cacheKey := fmt.Sprintf(balancesCacheKey, address)
+	cached, err := b.cache.Get(cacheKey)
+	if err != nil {
+		b.logger.WithError(err).Warn("error getting cached balances")
+	}
+
+	if len(cached) > 0 {
+		if data, err := parseBalances(cached); err == nil {
+			return data, nil
+		}
+	}
+
+	body, err := b.fetchAddressBalance(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := parseBalances(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = b.cache.Set(cacheKey, body, b.cacheTtl); err != nil {
+		b.logger.WithError(err).Warn("error caching balances")
+	}
+
+	return data, nil
+}
+
+func parseBalances(body []byte) (*query.BalancesResponse, error) {
+	var data query.BalancesResponse
+	if err := cmtjson.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("error unmarshalling response data: %w", err)
+	}
+
+	return &data, nil
+}
+
+func (b *BalancesHealth) fetchAddressBalance(ctx context.Context, address string) ([]byte, error) {
 	url := b.getAddressEndpoint(address)
 	if url == "" {
 		return nil, fmt.Errorf("no endpoint found for provided address")
 	}
 
-	resp, err := http.Get(fmt.Sprintf("%s/%s/%s", url, balancesRoute, address))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s/%s", url, balancesRoute, address), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error building balances request: %w", err)
+	}
+
+	resp, err := b.httpClient.Do(req)
 	if err != nil {
 
 		return nil, fmt.Errorf("error making request to Cosmos SDK: %w", err)
@@ -108,19 +288,13 @@ func (b *BalancesHealth) getAddressBalance(address string) (*query.BalancesRespo
 		return nil, fmt.Errorf("error reading response body: %w", err)
 	}
 
-	var data query.BalancesResponse
-	err = cmtjson.Unmarshal(body, &data)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling response data: %w", err)
-	}
-
-	return &data, nil
+	return body, nil
 }
 
 func (b *BalancesHealth) getAddressEndpoint(address string) string {
-	for k, v := range b.endpoints {
-		if strings.HasPrefix(address, k) {
-			return v
+	for _, prefix := range b.prefixes {
+		if strings.HasPrefix(address, prefix) {
+			return b.endpoints[prefix]
 		}
 	}
 
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/chain_registry"
+	"github.com/sirupsen/logrus"
+)
+
+type noCache struct{}
+
+func (noCache) Get(string) ([]byte, error)              { return nil, nil }
+func (noCache) Set(string, []byte, time.Duration) error { return nil }
+
+type stubRegistry struct {
+	assets []chain_registry.ChainRegistryAsset
+	err    error
+}
+
+func (r *stubRegistry) GetAssets(context.Context) ([]chain_registry.ChainRegistryAsset, error) {
+	return r.assets, r.err
+}
+
+func newTestBalancesHealth(t *testing.T, registry assetsRegistry) *BalancesHealth {
+	t.Helper()
+
+	b, err := NewBalancesHealth(logrus.New(), noCache{}, registry, nil, time.Second, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+var bzeAsset = chain_registry.ChainRegistryAsset{
+	Base:       "ubze",
+	Display:    "bze",
+	DenomUnits: []chain_registry.ChainRegistryAssetDenom{{Denom: "ubze"}, {Denom: "bze", Exponent: 6}},
+}
+
+func TestBaseAmountConvertsTheDisplayDenom(t *testing.T) {
+	b := newTestBalancesHealth(t, &stubRegistry{assets: []chain_registry.ChainRegistryAsset{bzeAsset}})
+
+	denom, amount, err := b.baseAmount(context.Background(), "BZE", "1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if denom != "ubze" || amount.String() != "1500000" {
+		t.Fatalf("expected 1500000 ubze, got %s %s", amount, denom)
+	}
+}
+
+func TestBaseAmountWithoutRegistry(t *testing.T) {
+	b := newTestBalancesHealth(t, &stubRegistry{err: errors.New("registry is down")})
+
+	denom, amount, err := b.baseAmount(context.Background(), "ubze", "1000")
+	if err != nil {
+		t.Fatalf("expected the denom considered a base denom, got %v", err)
+	}
+	if denom != "ubze" || amount.String() != "1000" {
+		t.Fatalf("expected 1000 ubze, got %s %s", amount, denom)
+	}
+
+	//a decimal amount needs the display denom conversion
+	if _, _, err = b.baseAmount(context.Background(), "bze", "1.5"); err == nil {
+		t.Fatal("expected an error converting a display amount without the registry")
+	}
+}
+
+func TestBaseAmountUsesTheLastAssetsWhenTheRegistryFails(t *testing.T) {
+	registry := &stubRegistry{assets: []chain_registry.ChainRegistryAsset{bzeAsset}}
+	b := newTestBalancesHealth(t, registry)
+	if _, _, err := b.baseAmount(context.Background(), "ubze", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	registry.assets, registry.err = nil, errors.New("registry is down")
+	denom, amount, err := b.baseAmount(context.Background(), "bze", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if denom != "ubze" || amount.String() != "2000000" {
+		t.Fatalf("expected 2000000 ubze, got %s %s", amount, denom)
+	}
+}
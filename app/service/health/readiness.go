@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/nodepool"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+// Probe returns an error when the dependency can not be used
+type Probe func(ctx context.Context) error
+
+type queryer interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+type nodesStatus interface {
+	Status() []nodepool.NodeStatus
+}
+
+// Readiness runs the probes of the dependencies needed to serve requests
+type Readiness struct {
+	logger logrus.FieldLogger
+	probes map[string]Probe
+}
+
+func NewReadiness(logger logrus.FieldLogger, probes map[string]Probe) (*Readiness, error) {
+	if logger == nil || len(probes) == 0 {
+		return nil, internal.NewInvalidDependenciesErr("NewReadiness")
+	}
+
+	return &Readiness{
+		logger: logger.WithField("service", "Health.Readiness"),
+		probes: probes,
+	}, nil
+}
+
+// Check runs all the probes in parallel, the API is ready when all of them pass
+func (r *Readiness) Check(ctx context.Context) dto.Readiness {
+	checks := make([]dto.ReadinessCheck, 0, len(r.probes))
+	var wg sync.WaitGroup
+	var mx sync.Mutex
+	for name, probe := range r.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := probe(ctx)
+			check := dto.ReadinessCheck{Name: name, IsHealthy: err == nil, LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				check.Error = err.Error()
+				r.logger.WithError(err).WithField("probe", name).Warn("readiness probe failed")
+			}
+
+			mx.Lock()
+			defer mx.Unlock()
+			checks = append(checks, check)
+		}()
+	}
+
+	wg.Wait()
+
+	slices.SortFunc(checks, func(a, b dto.ReadinessCheck) int { return strings.Compare(a.Name, b.Name) })
+	result := dto.Readiness{IsHealthy: true, Checks: checks}
+	for _, check := range checks {
+		result.IsHealthy = result.IsHealthy && check.IsHealthy
+	}
+
+	return result
+}
+
+// NewDatabaseProbe fails when the database does not answer a trivial query
+func NewDatabaseProbe(db queryer) Probe {
+	return func(ctx context.Context) error {
+		var one int
+
+		return db.GetContext(ctx, &one, "SELECT 1")
+	}
+}
+
+// NewPoolProbe fails when none of the nodes of the pool is available
+func NewPoolProbe(pool nodesStatus) Probe {
+	return func(ctx context.Context) error {
+		var errs []error
+		for _, node := range pool.Status() {
+			if node.Available {
+				return nil
+			}
+
+			if node.Error != "" {
+				errs = append(errs, fmt.Errorf("%s: %s", node.Host, node.Error))
+			}
+		}
+
+		return errors.Join(append([]error{errors.New("no node available")}, errs...)...)
+	}
+}
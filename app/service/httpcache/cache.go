@@ -0,0 +1,150 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+)
+
+const (
+	// AnyMarket tags the responses built from the data of all markets (e.g. tickers). They are dropped
+	// whenever a market is synced.
+	AnyMarket = ""
+
+	metricsName = "dex_response"
+	//keeps the memory bounded when clients ask for many distinct queries (e.g. history by address)
+	maxEntries = 10000
+)
+
+// Entry is a cached response body together with its validators
+type Entry struct {
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+}
+
+type cacheEntry struct {
+	Entry
+
+	tag       string
+	expiresAt time.Time
+}
+
+// Cache keeps the JSON encoded responses in memory, tagged by the market they were built from
+type Cache struct {
+	mx      sync.RWMutex
+	entries map[string]*cacheEntry
+	ttl     time.Duration
+
+	//bumped on every invalidation of a tag, so a response loaded before it is not stored after it
+	generations map[string]uint64
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		entries:     make(map[string]*cacheEntry),
+		ttl:         ttl,
+		generations: make(map[string]uint64),
+	}
+}
+
+// Generation returns the current generation of the tag, read before loading the response passed to Set
+func (c *Cache) Generation(tag string) uint64 {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	return c.generations[tag]
+}
+
+// Get returns the entry stored under key if it did not expire
+func (c *Cache) Get(key string) (*Entry, bool) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		metrics.CacheMiss(metricsName)
+
+		return nil, false
+	}
+
+	metrics.CacheHit(metricsName)
+
+	return &e.Entry, true
+}
+
+// Set encodes data and stores it under key. The entry is returned even when it could not be stored: when the cache
+// is full or when the tag was invalidated since generation, the data may be older than the invalidation.
+func (c *Cache) Set(key, tag string, generation uint64, data any) (*Entry, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(body)
+	now := time.Now()
+	e := &cacheEntry{
+		Entry: Entry{
+			Body:         body,
+			ETag:         fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16])),
+			LastModified: now.UTC().Truncate(time.Second),
+		},
+		tag:       tag,
+		expiresAt: now.Add(c.ttl),
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.generations[tag] != generation {
+		return &e.Entry, nil
+	}
+
+	if len(c.entries) >= maxEntries {
+		c.removeExpired(now)
+	}
+	if len(c.entries) < maxEntries {
+		c.entries[key] = e
+	}
+
+	return &e.Entry, nil
+}
+
+// Invalidate drops the entries of the given markets and the ones built from all markets
+func (c *Cache) Invalidate(marketIds ...string) {
+	if len(marketIds) == 0 {
+		return
+	}
+
+	tags := make(map[string]bool, len(marketIds)+1)
+	tags[AnyMarket] = true
+	for _, id := range marketIds {
+		tags[id] = true
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for tag := range tags {
+		c.generations[tag]++
+	}
+
+	for key, e := range c.entries {
+		if tags[e.tag] {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *Cache) removeExpired(now time.Time) {
+	for key, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package httpcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetStoresTheEntry(t *testing.T) {
+	c := NewCache(time.Minute)
+
+	entry, err := c.Set("orders", "ubze/uusdc", c.Generation("ubze/uusdc"), []string{"order"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cached, ok := c.Get("orders")
+	if !ok || cached.ETag != entry.ETag {
+		t.Fatalf("expected the entry cached, got %v", cached)
+	}
+}
+
+func TestSetRefusesDataLoadedBeforeTheInvalidation(t *testing.T) {
+	c := NewCache(time.Minute)
+
+	market := c.Generation("ubze/uusdc")
+	tickers := c.Generation(AnyMarket)
+	other := c.Generation("ubze/uatom")
+
+	//the market is synced while the responses are loaded
+	c.Invalidate("ubze/uusdc")
+
+	if entry, err := c.Set("orders", "ubze/uusdc", market, []string{"stale"}); err != nil || entry == nil {
+		t.Fatalf("expected the entry returned, got %v %v", entry, err)
+	}
+	if _, ok := c.Get("orders"); ok {
+		t.Fatal("the market response loaded before the invalidation was cached")
+	}
+
+	if _, err := c.Set("tickers", AnyMarket, tickers, []string{"stale"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("tickers"); ok {
+		t.Fatal("the all markets response loaded before the invalidation was cached")
+	}
+
+	if _, err := c.Set("other", "ubze/uatom", other, []string{"fresh"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("other"); !ok {
+		t.Fatal("the response of a market not invalidated was not cached")
+	}
+}
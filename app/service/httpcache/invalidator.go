@@ -0,0 +1,79 @@
+package httpcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type versionsRepository interface {
+	GetVersions(ctx context.Context) ([]entity.MarketSyncVersion, error)
+}
+
+// Invalidator watches the market versions bumped by the sync processes and drops the cached responses
+// of the markets that changed. The sync runs in other processes, so the versions are polled from the database.
+type Invalidator struct {
+	cache    *Cache
+	repo     versionsRepository
+	interval time.Duration
+	logger   logrus.FieldLogger
+
+	versions map[string]uint64
+}
+
+func NewInvalidator(cache *Cache, repo versionsRepository, interval time.Duration, logger logrus.FieldLogger) (*Invalidator, error) {
+	if cache == nil || repo == nil || interval <= 0 || logger == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewInvalidator")
+	}
+
+	return &Invalidator{
+		cache:    cache,
+		repo:     repo,
+		interval: interval,
+		logger:   logger.WithField("service", "HttpCacheInvalidator"),
+	}, nil
+}
+
+// Run polls the market versions until ctx is canceled
+func (i *Invalidator) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		i.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (i *Invalidator) poll(ctx context.Context) {
+	list, err := i.repo.GetVersions(ctx)
+	if err != nil {
+		i.logger.WithError(err).Warn("could not get market versions")
+
+		return
+	}
+
+	versions := make(map[string]uint64, len(list))
+	var changed []string
+	for _, v := range list {
+		versions[v.MarketID] = v.Version
+		if prev, ok := i.versions[v.MarketID]; !ok || prev != v.Version {
+			changed = append(changed, v.MarketID)
+		}
+	}
+
+	//on the first poll the cache is empty, there is nothing to invalidate
+	if i.versions != nil && len(changed) > 0 {
+		i.logger.WithField("markets", changed).Debug("invalidating cached responses")
+		i.cache.Invalidate(changed...)
+	}
+	i.versions = versions
+}
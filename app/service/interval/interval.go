@@ -39,6 +39,11 @@ func GetBiggestDuration() Length {
 	return oneDay
 }
 
+// GetLengths returns all the interval lengths, from the finest to the coarsest
+func GetLengths() []Length {
+	return []Length{fiveMinutes, quarterHour, oneHour, fourHours, oneDay}
+}
+
 func NewInterval(start, end time.Time, duration Length) *Interval {
 	return &Interval{
 		Start:        start,
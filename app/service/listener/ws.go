@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/cometbft/cometbft/abci/types"
 	"github.com/cometbft/cometbft/rpc/client/http"
@@ -15,11 +16,18 @@ import (
 )
 
 const (
-	tradebinStr = "tradebin"
+	tradebinStr  = "tradebin"
+	cointrunkStr = "cointrunk"
 
 	heartBeatInterval = time.Second * 60 * 5
 )
 
+// Event is a tradebin or cointrunk event along with the height of the block that emitted it
+type Event struct {
+	types.Event
+	Height int64
+}
+
 type TradebinListener struct {
 	logger logrus.FieldLogger
 	client *http.HTTP
@@ -36,7 +44,12 @@ func NewTradebinListener(conn *http.HTTP, logger logrus.FieldLogger) (*TradebinL
 	}, nil
 }
 
-func (w *TradebinListener) Listen(msgChan chan<- types.Event) error {
+// Listen sends the tradebin and cointrunk events to msgChan until ctx is canceled (returning nil) or one of the subscriptions
+// is closed (returning an error). msgChan is not closed, the caller owns it.
+func (w *TradebinListener) Listen(ctx context.Context, msgChan chan<- Event) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	if err := w.client.Start(); err != nil {
 		return fmt.Errorf("could not start ws client: %w", err)
 	}
@@ -44,14 +57,14 @@ func (w *TradebinListener) Listen(msgChan chan<- types.Event) error {
 	defer w.client.Stop()
 
 	// Subscribe to NewBlock events
-	blockEventChan, err := w.client.Subscribe(context.Background(), "block-listener", "tm.event = 'NewBlock'")
+	blockEventChan, err := w.client.Subscribe(ctx, "block-listener", "tm.event = 'NewBlock'")
 	if err != nil {
 		return err
 	}
 	defer w.client.UnsubscribeAll(context.Background(), "block-listener")
 
 	// Subscribe to Tx events
-	txEventChan, err := w.client.Subscribe(context.Background(), "tx-listener", "tm.event = 'Tx'")
+	txEventChan, err := w.client.Subscribe(ctx, "tx-listener", "tm.event = 'Tx'")
 	if err != nil {
 		return err
 	}
@@ -64,31 +77,42 @@ func (w *TradebinListener) Listen(msgChan chan<- types.Event) error {
 	// Start a ping ticker to keep the connection alive
 	ticker := time.NewTicker(heartBeatInterval) // Adjust interval as needed
 	defer ticker.Stop()
-	w.keepAliveTicker(ticker)
+	w.keepAliveTicker(ctx, ticker)
 
 	// Use a select statement to listen to both channels concurrently
 	for {
 		if blockChanClosed || txChanClosed {
-			w.logger.Error("one of the channels was closed")
-			close(msgChan)
-
-			return nil
+			return fmt.Errorf("subscription channel was closed by the node")
 		}
 
 		select {
+		case <-ctx.Done():
+			w.logger.Info("stopped listening")
+
+			return nil
 		case blockMsg, ok := <-blockEventChan:
 			if !ok {
 				blockChanClosed = true
 				continue
 			}
 			if evt, ok := blockMsg.Data.(tmtypes.EventDataNewBlock); ok {
+				var height int64
+				if evt.Block != nil {
+					height = evt.Block.Height
+					metrics.SetListenerHeight(height)
+				}
+
 				allEvents := evt.ResultFinalizeBlock.Events
 				for _, event := range allEvents {
-					if !strings.Contains(event.Type, tradebinStr) {
+					if !isListened(event.Type) {
 						continue
 					}
 
-					msgChan <- event
+					select {
+					case msgChan <- Event{Event: event, Height: height}:
+					case <-ctx.Done():
+						return nil
+					}
 				}
 			}
 
@@ -100,23 +124,30 @@ func (w *TradebinListener) Listen(msgChan chan<- types.Event) error {
 			if evt, ok := txMsg.Data.(tmtypes.EventDataTx); ok {
 				txResult := evt.Result
 				for _, event := range txResult.Events {
-					if !strings.Contains(event.Type, tradebinStr) {
+					if !isListened(event.Type) {
 						continue
 					}
 
-					msgChan <- event
+					select {
+					case msgChan <- Event{Event: event, Height: evt.Height}:
+					case <-ctx.Done():
+						return nil
+					}
 				}
 			}
 		}
 	}
 }
 
-func (w *TradebinListener) keepAliveTicker(ticker *time.Ticker) {
+// keepAliveTicker pings the node on every tick until ctx is canceled
+func (w *TradebinListener) keepAliveTicker(ctx context.Context, ticker *time.Ticker) {
 	go func() {
 		for {
 			select {
+			case <-ctx.Done():
+				return
 			case <-ticker.C:
-				resp, err := w.client.Health(context.Background())
+				resp, err := w.client.Health(ctx)
 				_ = resp
 				if err != nil {
 					w.logger.WithError(err).Error("failed to send keep alive request")
@@ -127,3 +158,7 @@ func (w *TradebinListener) keepAliveTicker(ticker *time.Ticker) {
 		}
 	}()
 }
+
+func isListened(eventType string) bool {
+	return strings.Contains(eventType, tradebinStr) || strings.Contains(eventType, cointrunkStr)
+}
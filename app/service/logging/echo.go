@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	contextKey = "request_logger"
+)
+
+// EchoMiddleware stores a request scoped logger in the echo context and writes one access log line per request.
+// It must be registered after middleware.RequestID() and the tracing middleware so it can pick up their ids.
+func EchoMiddleware(logger logrus.FieldLogger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+			req := ctx.Request()
+
+			l := logger.WithFields(logrus.Fields{
+				internal.LogFieldRequestId: ctx.Response().Header().Get(echo.HeaderXRequestID),
+				"method":                   req.Method,
+				"route":                    ctx.Path(),
+			})
+
+			spanCtx := trace.SpanContextFromContext(req.Context())
+			if spanCtx.HasTraceID() {
+				l = l.WithField(internal.LogFieldTraceId, spanCtx.TraceID().String())
+			}
+
+			ctx.Set(contextKey, l)
+
+			err := next(ctx)
+			if err != nil {
+				ctx.Error(err)
+			}
+
+			entry := l.WithFields(logrus.Fields{
+				"path":       req.URL.Path,
+				"status":     ctx.Response().Status,
+				"latency_ms": time.Since(start).Milliseconds(),
+				"remote_ip":  ctx.RealIP(),
+			})
+			if err != nil {
+				entry = entry.WithError(err)
+			}
+
+			entry.Info("request handled")
+
+			return nil
+		}
+	}
+}
+
+// FromContext returns the request scoped logger or the fallback when the middleware did not run
+func FromContext(ctx echo.Context, fallback logrus.FieldLogger) logrus.FieldLogger {
+	if l, ok := ctx.Get(contextKey).(logrus.FieldLogger); ok {
+		return l
+	}
+
+	return fallback
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+)
+
+// MeteredCache wraps a Cache and counts hits and misses under the provided name
+type MeteredCache struct {
+	name  string
+	cache Cache
+}
+
+// NewMeteredCache creates a new instance of MeteredCache
+func NewMeteredCache(name string, cache Cache) *MeteredCache {
+	return &MeteredCache{
+		name:  name,
+		cache: cache,
+	}
+}
+
+// Get retrieves data from the wrapped cache and records the lookup result
+func (c *MeteredCache) Get(key string) ([]byte, error) {
+	data, err := c.cache.Get(key)
+	if err != nil || data == nil {
+		metrics.CacheMiss(c.name)
+
+		return data, err
+	}
+
+	metrics.CacheHit(c.name)
+
+	return data, nil
+}
+
+// Set stores data in the wrapped cache
+func (c *MeteredCache) Set(key string, data []byte, expiration time.Duration) error {
+	return c.cache.Set(key, data, expiration)
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// lastTradeCollector exposes the age of the newest synced trade for each market.
+// The age is calculated on every scrape, so it keeps growing while a market is not synced.
+type lastTradeCollector struct {
+	desc *prometheus.Desc
+
+	mx     sync.RWMutex
+	trades map[string]time.Time
+}
+
+func newLastTradeCollector() *lastTradeCollector {
+	return &lastTradeCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "sync", "last_trade_age_seconds"),
+			"Seconds since the execution of the newest trade synced for each market.",
+			[]string{"market_id"},
+			nil,
+		),
+		trades: make(map[string]time.Time),
+	}
+}
+
+func (c *lastTradeCollector) set(marketId string, executedAt time.Time) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if existing, ok := c.trades[marketId]; ok && existing.After(executedAt) {
+		return
+	}
+
+	c.trades[marketId] = executedAt
+}
+
+func (c *lastTradeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *lastTradeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	now := time.Now()
+	for marketId, executedAt := range c.trades {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, now.Sub(executedAt).Seconds(), marketId)
+	}
+}
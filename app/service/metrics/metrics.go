@@ -0,0 +1,294 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "bze_agg"
+
+	resultSuccess = "success"
+	resultError   = "error"
+
+	cacheHit  = "hit"
+	cacheMiss = "miss"
+
+	unknownRoute = "unknown"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests handled, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency, by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "call_duration_seconds",
+		Help:      "Duration of gRPC calls made to the blockchain, by method and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "result"})
+
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Number of cache lookups, by cache name and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	syncOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "operations_total",
+		Help:      "Number of sync operations, by operation and result.",
+	}, []string{"operation", "result"})
+
+	syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of sync operations, by operation.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"operation"})
+
+	listenerEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "listener",
+		Name:      "events_total",
+		Help:      "Number of blockchain events received by the listener, by event type.",
+	}, []string{"event_type"})
+
+	listenerHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "listener",
+		Name:      "block_height",
+		Help:      "Height of the last block received by the listener.",
+	})
+
+	listenerLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "listener",
+		Name:      "block_lag",
+		Help:      "Number of blocks the listener is behind each health node.",
+	}, []string{"node"})
+
+	nodeHeight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "node",
+		Name:      "block_height",
+		Help:      "Latest block height reported by each health node.",
+	}, []string{"node"})
+
+	nodePoolAvailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "node_pool",
+		Name:      "node_available",
+		Help:      "1 when the node is used by the pool, 0 when it is skipped because it is down or behind.",
+	}, []string{"pool", "node"})
+
+	nodePoolRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "node_pool",
+		Name:      "retries_total",
+		Help:      "Number of calls retried on another node, by pool.",
+	}, []string{"pool"})
+
+	rateLimitRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rate_limit",
+		Name:      "rejected_total",
+		Help:      "Number of API requests rejected by the rate limiter, by tier (anonymous or partner).",
+	}, []string{"tier"})
+
+	registryUnresolved = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "chain_registry",
+		Name:      "unresolved_denoms_total",
+		Help:      "Number of times a denom missing from the chain registry was given the default exponent, by denom.",
+	}, []string{"denom"})
+
+	lastTrades = newLastTradeCollector()
+
+	currentListenerHeight int64
+	heightMx              sync.RWMutex
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		grpcDuration,
+		cacheRequests,
+		syncOperations,
+		syncDuration,
+		listenerEvents,
+		listenerHeight,
+		listenerLag,
+		nodeHeight,
+		nodePoolAvailable,
+		nodePoolRetries,
+		rateLimitRejected,
+		registryUnresolved,
+		lastTrades,
+	)
+}
+
+// Handler returns the http.Handler exposing all registered metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ListenAndServe starts a dedicated http server exposing the metrics on /metrics.
+// It is used by long-running commands that do not start the API server.
+func ListenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+
+	return http.ListenAndServe(addr, mux)
+}
+
+// EchoMiddleware records latency and status of every request handled by echo, labeled by the registered route
+func EchoMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+			err := next(ctx)
+
+			status := ctx.Response().Status
+			if err != nil {
+				var httpErr *echo.HTTPError
+				if errors.As(err, &httpErr) {
+					status = httpErr.Code
+				} else {
+					status = http.StatusInternalServerError
+				}
+			}
+
+			route := ctx.Path()
+			if route == "" {
+				route = unknownRoute
+			}
+
+			labels := prometheus.Labels{
+				"method": ctx.Request().Method,
+				"route":  route,
+				"status": strconv.Itoa(status),
+			}
+			httpRequests.With(labels).Inc()
+			httpDuration.With(labels).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
+
+// ObserveGrpcCall records the duration of a gRPC call started at the provided time
+func ObserveGrpcCall(method string, start time.Time, err error) {
+	grpcDuration.WithLabelValues(method, getResult(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveSync records the result and the duration of a sync operation started at the provided time
+func ObserveSync(operation string, start time.Time, err error) {
+	syncOperations.WithLabelValues(operation, getResult(err)).Inc()
+	syncDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+// CacheHit increments the hits counter of the provided cache
+func CacheHit(cache string) {
+	cacheRequests.WithLabelValues(cache, cacheHit).Inc()
+}
+
+// CacheMiss increments the misses counter of the provided cache
+func CacheMiss(cache string) {
+	cacheRequests.WithLabelValues(cache, cacheMiss).Inc()
+}
+
+// ListenerEvent increments the counter of events received by the listener for the provided type
+func ListenerEvent(eventType string) {
+	listenerEvents.WithLabelValues(eventType).Inc()
+}
+
+// SetListenerHeight saves the height of the last block received by the listener
+func SetListenerHeight(height int64) {
+	heightMx.Lock()
+	defer heightMx.Unlock()
+
+	currentListenerHeight = height
+	listenerHeight.Set(float64(height))
+}
+
+// SetNodeHeight saves the height reported by a health node.
+// When the listener is running in the same process, the lag between the node and the listener is updated as well.
+func SetNodeHeight(node string, height int64) {
+	nodeHeight.WithLabelValues(node).Set(float64(height))
+
+	heightMx.RLock()
+	defer heightMx.RUnlock()
+	if currentListenerHeight == 0 {
+		return
+	}
+
+	listenerLag.WithLabelValues(node).Set(float64(height - currentListenerHeight))
+}
+
+// SetNodePoolAvailable saves whether a node is used by the pool or skipped
+func SetNodePoolAvailable(pool, node string, available bool) {
+	var value float64
+	if available {
+		value = 1
+	}
+
+	nodePoolAvailable.WithLabelValues(pool, node).Set(value)
+}
+
+// NodePoolRetry increments the counter of calls retried on another node of the pool
+func NodePoolRetry(pool string) {
+	nodePoolRetries.WithLabelValues(pool).Inc()
+}
+
+// RateLimitRejected increments the counter of requests rejected by the rate limiter
+func RateLimitRejected(tier string) {
+	rateLimitRejected.WithLabelValues(tier).Inc()
+}
+
+// ChainRegistryUnresolved increments the counter of a denom given the default exponent
+func ChainRegistryUnresolved(denom string) {
+	registryUnresolved.WithLabelValues(denom).Inc()
+}
+
+// SetLastSyncedTrade saves the execution time of the newest trade synced for a market
+func SetLastSyncedTrade(marketId string, executedAt time.Time) {
+	lastTrades.set(marketId, executedAt)
+}
+
+func getResult(err error) string {
+	if err != nil {
+		return resultError
+	}
+
+	return resultSuccess
+}
@@ -0,0 +1,29 @@
+package nodepool
+
+import "errors"
+
+type permanentErr struct {
+	err error
+}
+
+func (e *permanentErr) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentErr) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks an error that is not caused by the node (e.g. a bad request), so Pool.Do returns it without retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var p *permanentErr
+	if errors.As(err, &p) {
+		return err
+	}
+
+	return &permanentErr{err: err}
+}
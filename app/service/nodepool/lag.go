@@ -0,0 +1,37 @@
+package nodepool
+
+import (
+	"slices"
+	"strings"
+)
+
+// Lag describes how far a node is behind the highest node
+type Lag struct {
+	Node string
+	//the height of the highest node
+	Expected int64
+	Found    int64
+}
+
+// Blocks returns the number of blocks the node is behind the highest node
+func (l Lag) Blocks() int64 {
+	return l.Expected - l.Found
+}
+
+// NodesLag compares the heights reported by the nodes (name => height) with the highest one and returns one entry
+// per node, sorted by node name
+func NodesLag(heights map[string]int64) []Lag {
+	var maxHeight int64
+	for _, height := range heights {
+		maxHeight = max(maxHeight, height)
+	}
+
+	result := make([]Lag, 0, len(heights))
+	for name, height := range heights {
+		result = append(result, Lag{Node: name, Expected: maxHeight, Found: height})
+	}
+
+	slices.SortFunc(result, func(a, b Lag) int { return strings.Compare(a.Node, b.Node) })
+
+	return result
+}
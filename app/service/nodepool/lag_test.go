@@ -0,0 +1,28 @@
+package nodepool
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNodesLagComparesWithTheHighestNode(t *testing.T) {
+	lags := NodesLag(map[string]int64{"c": 100, "a": 95, "b": 99})
+
+	expected := "[{a 100 95} {b 100 99} {c 100 100}]"
+	if fmt.Sprint(lags) != expected {
+		t.Fatalf("expected %s, got %v", expected, lags)
+	}
+
+	blocks := []int64{5, 1, 0}
+	for i, lag := range lags {
+		if lag.Blocks() != blocks[i] {
+			t.Errorf("%s: expected %d blocks behind, got %d", lag.Node, blocks[i], lag.Blocks())
+		}
+	}
+}
+
+func TestNodesLagWithoutNodes(t *testing.T) {
+	if lags := NodesLag(nil); len(lags) != 0 {
+		t.Fatalf("expected no lag, got %v", lags)
+	}
+}
@@ -0,0 +1,304 @@
+package nodepool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Prober returns the latest block height known by the node behind host
+type Prober func(ctx context.Context, host string) (int64, error)
+
+type node struct {
+	host    string
+	height  int64
+	latency time.Duration
+	up      bool
+	behind  bool
+	lastErr error
+}
+
+// NodeStatus is a snapshot of what the pool knows about a node
+type NodeStatus struct {
+	Host      string        `json:"host"`
+	Height    int64         `json:"height"`
+	Latency   time.Duration `json:"latency"`
+	Available bool          `json:"available"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Pool spreads the calls made to a blockchain service (REST, gRPC, RPC) over several nodes.
+// Nodes are probed periodically; the ones that are down or behind the highest node are skipped while healthy nodes exist.
+type Pool struct {
+	name   string
+	probe  Prober
+	logger logrus.FieldLogger
+
+	maxHeightDiff int64
+	retries       int
+	backoff       time.Duration
+
+	mx    sync.RWMutex
+	nodes []*node
+}
+
+func NewPool(name string, hosts []string, probe Prober, cfg config.NodePool, logger logrus.FieldLogger) (*Pool, error) {
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no hosts provided for node pool %s", name)
+	}
+
+	if probe == nil || logger == nil {
+		return nil, fmt.Errorf("invalid dependencies provided to NewPool")
+	}
+
+	p := &Pool{
+		name:          name,
+		probe:         probe,
+		logger:        logger.WithField("service", "NodePool").WithField("pool", name),
+		maxHeightDiff: int64(cfg.MaxHeightDiff),
+		retries:       cfg.Retries,
+		backoff:       time.Duration(cfg.BackoffMillis) * time.Millisecond,
+	}
+
+	//until the first probe every node is considered healthy, in the configured order
+	for _, h := range hosts {
+		p.nodes = append(p.nodes, &node{host: h, up: true})
+	}
+
+	return p, nil
+}
+
+// Name returns the name of the pool
+func (p *Pool) Name() string {
+	return p.name
+}
+
+// Hosts returns the hosts in the order they should be tried: available nodes by latency first, then the others.
+// Nodes that are down or behind are still returned last, so a call is attempted even when no node looks healthy.
+func (p *Pool) Hosts() []string {
+	p.mx.RLock()
+	defer p.mx.RUnlock()
+
+	available := make([]*node, 0, len(p.nodes))
+	var others []*node
+	for _, n := range p.nodes {
+		if n.up && !n.behind {
+			available = append(available, n)
+			continue
+		}
+		others = append(others, n)
+	}
+
+	sort.SliceStable(available, func(i, j int) bool {
+		return available[i].latency < available[j].latency
+	})
+
+	hosts := make([]string, 0, len(p.nodes))
+	for _, n := range append(available, others...) {
+		hosts = append(hosts, n.host)
+	}
+
+	return hosts
+}
+
+// Status returns a snapshot of all the nodes of the pool
+func (p *Pool) Status() []NodeStatus {
+	p.mx.RLock()
+	defer p.mx.RUnlock()
+
+	result := make([]NodeStatus, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		s := NodeStatus{
+			Host:      n.host,
+			Height:    n.height,
+			Latency:   n.latency,
+			Available: n.up && !n.behind,
+		}
+		if n.lastErr != nil {
+			s.Error = n.lastErr.Error()
+		}
+		result = append(result, s)
+	}
+
+	return result
+}
+
+// Do calls fn with the best host. When fn fails the node is marked as down and the call is retried,
+// after a backoff, on the next host. Errors wrapped with Permanent are returned without retrying.
+func (p *Pool) Do(ctx context.Context, fn func(ctx context.Context, host string) error) error {
+	hosts := p.Hosts()
+	var err error
+	for attempt := 0; attempt <= p.retries; attempt++ {
+		if attempt > 0 {
+			metrics.NodePoolRetry(p.name)
+			if waitErr := p.wait(ctx, attempt); waitErr != nil {
+				return errors.Join(err, waitErr)
+			}
+		}
+
+		host := hosts[attempt%len(hosts)]
+		err = fn(ctx, host)
+		if err == nil {
+			return nil
+		}
+
+		var permanent *permanentErr
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+
+		p.MarkFailed(host, err)
+	}
+
+	return err
+}
+
+// MarkFailed marks the node as down until the next successful probe
+func (p *Pool) MarkFailed(host string, err error) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	for _, n := range p.nodes {
+		if n.host != host {
+			continue
+		}
+
+		if n.up {
+			p.logger.WithError(err).WithField("host", host).Warn("node marked as down")
+		}
+		n.up = false
+		n.lastErr = err
+		metrics.SetNodePoolAvailable(p.name, host, false)
+	}
+}
+
+// SetHeight saves the height a node reported outside a probe (e.g. in a response header)
+func (p *Pool) SetHeight(host string, height int64) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	for _, n := range p.nodes {
+		if n.host == host && height > n.height {
+			n.height = height
+		}
+	}
+}
+
+// Run probes all nodes right away and then every interval until ctx is canceled.
+// Probers are expected to bound their own calls with the timeout of the client they use.
+func (p *Pool) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.Refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh probes all nodes concurrently and updates their height, latency and availability
+func (p *Pool) Refresh(ctx context.Context) {
+	p.mx.RLock()
+	hosts := make([]string, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		hosts = append(hosts, n.host)
+	}
+	p.mx.RUnlock()
+
+	type probeResult struct {
+		height  int64
+		latency time.Duration
+		err     error
+	}
+
+	results := make(map[string]probeResult, len(hosts))
+	var wg sync.WaitGroup
+	var mx sync.Mutex
+	for _, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			height, err := p.probe(ctx, host)
+			mx.Lock()
+			defer mx.Unlock()
+			results[host] = probeResult{height: height, latency: time.Since(start), err: err}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	heights := make(map[string]int64, len(results))
+	for host, res := range results {
+		if res.err == nil {
+			heights[host] = res.height
+		}
+	}
+	lagging := make(map[string]bool)
+	for _, lag := range NodesLag(heights) {
+		lagging[lag.Node] = lag.Blocks() > p.maxHeightDiff
+	}
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	for _, n := range p.nodes {
+		res, ok := results[n.host]
+		if !ok {
+			continue
+		}
+
+		l := p.logger.WithField("host", n.host)
+		n.latency = res.latency
+		n.lastErr = res.err
+		if res.err != nil {
+			if n.up {
+				l.WithError(res.err).Warn("node probe failed")
+			}
+			n.up = false
+		} else {
+			if !n.up {
+				l.Info("node is up again")
+			}
+			n.up = true
+			n.height = res.height
+		}
+
+		if lagging[n.host] && !n.behind {
+			l.WithField("height", n.height).Warn("node is behind the highest node")
+		}
+		n.behind = lagging[n.host]
+
+		metrics.SetNodePoolAvailable(p.name, n.host, n.up && !n.behind)
+	}
+}
+
+func (p *Pool) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(p.backoff * time.Duration(1<<(attempt-1)))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	onchainArticlesSource   = "cointrunk"
+	onchainArticlesCacheKey = "articles:onchain"
+	paidArticleTag          = "paid"
+)
+
+type onchainArticleStorage interface {
+	FindArticles(ctx context.Context, publisher string, limit, offset int) ([]entity.CointrunkArticleView, error)
+}
+
+// OnchainArticles serves the articles published on chain, indexed by the cointrunk sync
+type OnchainArticles struct {
+	logger   logrus.FieldLogger
+	cache    Cache
+	storage  onchainArticleStorage
+	cacheTtl time.Duration
+}
+
+func NewOnchainArticlesService(logger logrus.FieldLogger, cache Cache, storage onchainArticleStorage, cacheTtl time.Duration) (*OnchainArticles, error) {
+	if logger == nil || cache == nil || storage == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewOnchainArticlesService")
+	}
+
+	return &OnchainArticles{
+		logger:   logger.WithField("service", "Service.OnchainArticles"),
+		cache:    cache,
+		storage:  storage,
+		cacheTtl: cacheTtl,
+	}, nil
+}
+
+// GetArticles returns a page of the articles published on chain, latest first, optionally only the ones of a publisher.
+// The author is the publisher name, or its address for the paid articles of addresses that are not publishers.
+func (o *OnchainArticles) GetArticles(ctx context.Context, publisher string, page, limit int) ([]dto.Article, error) {
+	cacheKey := fmt.Sprintf("%s:%s:%d:%d", onchainArticlesCacheKey, publisher, page, limit)
+	cacheValue, err := o.cache.Get(cacheKey)
+	if err != nil {
+		o.logger.Errorf("failed to get onchain articles from cache: %v", err)
+	}
+
+	if cacheValue != nil {
+		var articles []dto.Article
+		if err = json.Unmarshal(cacheValue, &articles); err == nil {
+			return articles, nil
+		}
+		o.logger.Errorf("failed to unmarshal onchain articles from cache: %v", err)
+	}
+
+	items, err := o.storage.FindArticles(ctx, publisher, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+
+	articles := make([]dto.Article, 0, len(items))
+	for _, item := range items {
+		author := item.PublisherName
+		if author == "" {
+			author = item.Publisher
+		}
+
+		tags := []string{}
+		if item.Paid {
+			tags = append(tags, paidArticleTag)
+		}
+
+		articles = append(articles, dto.Article{
+			Title:       item.Title,
+			URL:         item.Url,
+			PictureURL:  item.Picture,
+			PublishDate: item.CreatedAt,
+			AuthorName:  author,
+			Source:      onchainArticlesSource,
+			Tags:        tags,
+		})
+	}
+
+	encoded, err := json.Marshal(articles)
+	if err == nil {
+		err = o.cache.Set(cacheKey, encoded, o.cacheTtl)
+	}
+	if err != nil {
+		o.logger.Errorf("failed to cache onchain articles: %v", err)
+	}
+
+	return articles, nil
+}
@@ -1,61 +1,160 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
 	"github.com/sirupsen/logrus"
+	"slices"
+	"strings"
 	"time"
 )
 
 const (
 	pricesCache       = "prices:all"
 	pricesCacheBackup = "prices:all:backup"
-
-	priceCacheExpireSeconds       = 180
-	priceBackupCacheExpireSeconds = 60 * 60 * 24 //1 day
 )
 
-type PriceProvider interface {
-	GetDenominationsPrices() ([]dto.CoinPrice, error)
+// PriceSource returns the prices it knows of the given ids in the given currencies, leaving out the others.
+// A source failing for some prices returns the error together with the prices it found.
+type PriceSource interface {
+	Name() string
+	GetPrices(ctx context.Context, ids, currencies []string) ([]dto.CoinPrice, error)
 }
 
 type PricesService struct {
-	cache        Cache
-	dataProvider PriceProvider
-	logger       logrus.FieldLogger
+	cache   Cache
+	sources []PriceSource
+	logger  logrus.FieldLogger
+
+	ids        []string
+	currencies []string
+	staleAfter time.Duration
+
+	cacheTtl       time.Duration
+	backupCacheTtl time.Duration
 }
 
-func NewPricesService(cache Cache, dataProvider PriceProvider, logger logrus.FieldLogger) (*PricesService, error) {
-	if dataProvider == nil || cache == nil || logger == nil {
+func NewPricesService(cache Cache, sources []PriceSource, logger logrus.FieldLogger, cfg config.PricesConfig, cacheTtl, backupCacheTtl time.Duration) (*PricesService, error) {
+	if len(sources) == 0 || cache == nil || logger == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewPricesService")
 	}
 
 	return &PricesService{
-		cache:        cache,
-		dataProvider: dataProvider,
-		logger:       logger.WithField("service", "Service.Prices"),
+		cache:   cache,
+		sources: sources,
+		logger:  logger.WithField("service", "Service.Prices"),
+
+		ids:        cfg.Ids(),
+		currencies: cfg.Currencies(),
+		staleAfter: config.Seconds(cfg.StaleSeconds),
+
+		cacheTtl:       cacheTtl,
+		backupCacheTtl: backupCacheTtl,
 	}, nil
 }
 
-func (p *PricesService) GetPrices() []dto.CoinPrice {
-	cacheValue := p.getPricesFromCache(pricesCache)
-	if cacheValue != nil {
-		return cacheValue
+// GetPrices returns the prices in the given currencies, all the configured ones when empty. The sources are asked
+// in order until every coin has a price, the prices no source could give are the last known ones flagged as stale.
+func (p *PricesService) GetPrices(ctx context.Context, currencies []string) ([]dto.CoinPrice, error) {
+	for _, currency := range currencies {
+		if !slices.Contains(p.currencies, currency) {
+			return nil, internal.NewValidationErr(fmt.Sprintf("unsupported currency %s. expected one of: %s", currency, strings.Join(p.currencies, ", ")))
+		}
+	}
+
+	if len(currencies) == 0 {
+		currencies = p.currencies
 	}
 
-	prices := p.getPricesFromProvider()
+	prices := p.getPricesFromCache(pricesCache)
 	if prices == nil {
-		//return backup
-		return p.getPricesFromCache(pricesCacheBackup)
+		prices = p.getPricesFromSources(ctx)
 	}
 
-	p.cachePrices(prices)
+	var result []dto.CoinPrice
+	for _, price := range prices {
+		if !slices.Contains(currencies, price.PriceDenom) {
+			continue
+		}
+
+		updatedAt, err := time.Parse(time.RFC3339, price.UpdatedAt)
+		price.Stale = price.Source != config.PriceSourceStatic && (err != nil || time.Since(updatedAt) > p.staleAfter)
+		result = append(result, price)
+	}
 
-	return prices
+	return result, nil
 }
 
-func (p *PricesService) cachePrices(prices []dto.CoinPrice) {
+// getPricesFromSources asks the sources in order for the prices still missing, then completes them with the backup.
+// The prices are cached only when the sources gave all of them, so a failing source is asked again on the next call.
+func (p *PricesService) getPricesFromSources(ctx context.Context) []dto.CoinPrice {
+	found := make(map[config.PriceKey]dto.CoinPrice)
+	for _, source := range p.sources {
+		missingIds := p.missingIds(found)
+		if len(missingIds) == 0 {
+			break
+		}
+
+		l := p.logger.WithField("source", source.Name())
+		prices, err := source.GetPrices(ctx, missingIds, p.currencies)
+		if err != nil {
+			l.WithError(err).Warn("failed to get prices from source")
+		}
+
+		for _, price := range prices {
+			key := config.PriceKey{Id: price.Denom, Currency: price.PriceDenom}
+			if _, ok := found[key]; !ok {
+				found[key] = price
+			}
+		}
+	}
+
+	complete := len(p.missingIds(found)) == 0
+	if !complete {
+		for _, price := range p.getPricesFromCache(pricesCacheBackup) {
+			key := config.PriceKey{Id: price.Denom, Currency: price.PriceDenom}
+			if _, ok := found[key]; !ok && slices.Contains(p.currencies, key.Currency) {
+				p.logger.WithField("id", key.Id).WithField("currency", key.Currency).Warn("no source has the price, using the last known one")
+				found[key] = price
+			}
+		}
+	}
+
+	result := make([]dto.CoinPrice, 0, len(found))
+	for _, price := range found {
+		result = append(result, price)
+	}
+
+	slices.SortFunc(result, func(a, b dto.CoinPrice) int {
+		return strings.Compare(a.Denom+":"+a.PriceDenom, b.Denom+":"+b.PriceDenom)
+	})
+
+	p.cachePrices(result, complete)
+
+	return result
+}
+
+// missingIds returns the ids not priced yet in all the currencies
+func (p *PricesService) missingIds(found map[config.PriceKey]dto.CoinPrice) []string {
+	var result []string
+	for _, id := range p.ids {
+		for _, currency := range p.currencies {
+			if _, ok := found[config.PriceKey{Id: id, Currency: currency}]; !ok {
+				result = append(result, id)
+
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+func (p *PricesService) cachePrices(prices []dto.CoinPrice, complete bool) {
 	encoded, err := json.Marshal(prices)
 	if err != nil {
 		p.logger.Errorf("failed to marshal prices in order to cache them: %v", err)
@@ -63,21 +162,19 @@ func (p *PricesService) cachePrices(prices []dto.CoinPrice) {
 		return
 	}
 
-	err = p.cache.Set(pricesCache, encoded, time.Duration(priceCacheExpireSeconds)*time.Second)
-	if err != nil {
-		p.logger.Errorf("failed to cache prices: %v", err)
+	if complete {
+		err = p.cache.Set(pricesCache, encoded, p.cacheTtl)
+		if err != nil {
+			p.logger.Errorf("failed to cache prices: %v", err)
+		}
 	}
 
-	err = p.cache.Set(pricesCacheBackup, encoded, time.Duration(priceBackupCacheExpireSeconds)*time.Second)
+	err = p.cache.Set(pricesCacheBackup, encoded, p.backupCacheTtl)
 	if err != nil {
 		p.logger.Errorf("failed to cache prices for backup: %v", err)
 	}
 }
 
-func (p *PricesService) getBackupPrices() []dto.CoinPrice {
-	return p.getPricesFromCache(pricesCacheBackup)
-}
-
 func (p *PricesService) getPricesFromCache(key string) []dto.CoinPrice {
 	cacheValue, err := p.cache.Get(key)
 	if err != nil {
@@ -98,14 +195,3 @@ func (p *PricesService) getPricesFromCache(key string) []dto.CoinPrice {
 
 	return nil
 }
-
-func (p *PricesService) getPricesFromProvider() []dto.CoinPrice {
-	prices, err := p.dataProvider.GetDenominationsPrices()
-	if err != nil {
-		p.logger.Errorf("failed to get prices from provider: %v", err)
-
-		return nil
-	}
-
-	return prices
-}
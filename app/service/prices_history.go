@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type priceSnapshotStorage interface {
+	Save(ctx context.Context, items []*entity.PriceSnapshot) error
+	GetBetween(ctx context.Context, denom, currency string, from, to time.Time) ([]entity.PriceSnapshot, error)
+}
+
+type currentPrices interface {
+	GetPrices(ctx context.Context, currencies []string) ([]dto.CoinPrice, error)
+}
+
+// PricesHistory saves the prices over time and reads them back
+type PricesHistory struct {
+	logger  logrus.FieldLogger
+	storage priceSnapshotStorage
+	prices  currentPrices
+}
+
+func NewPricesHistoryService(logger logrus.FieldLogger, storage priceSnapshotStorage, prices currentPrices) (*PricesHistory, error) {
+	if logger == nil || storage == nil || prices == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewPricesHistoryService")
+	}
+
+	return &PricesHistory{
+		logger:  logger.WithField("service", "Service.PricesHistory"),
+		storage: storage,
+		prices:  prices,
+	}, nil
+}
+
+// TakeSnapshots saves the current prices in all the currencies. The stale prices are skipped, they were already
+// saved when they were fresh.
+func (p *PricesHistory) TakeSnapshots(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("price_snapshot", start, err) }()
+
+	prices, err := p.prices.GetPrices(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	takenAt := time.Now().UTC().Truncate(time.Second)
+	var items []*entity.PriceSnapshot
+	for _, price := range prices {
+		if price.Stale {
+			p.logger.WithField("denom", price.Denom).WithField("currency", price.PriceDenom).Warn("stale price not saved")
+
+			continue
+		}
+
+		items = append(items, &entity.PriceSnapshot{
+			Denom:    price.Denom,
+			Currency: price.PriceDenom,
+			Price:    price.Price,
+			Source:   price.Source,
+			TakenAt:  takenAt,
+		})
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	if err = p.storage.Save(ctx, items); err != nil {
+		return err
+	}
+
+	p.logger.WithField("count", len(items)).Debug("price snapshots saved")
+
+	return nil
+}
+
+// GetHistory returns the price at the end of each period of the given length in [from, to), that is the last
+// snapshot taken in the period. The periods without snapshots are skipped.
+func (p *PricesHistory) GetHistory(ctx context.Context, denom, currency string, from, to time.Time, step time.Duration) ([]response.PricePoint, error) {
+	snapshots, err := p.storage.GetBetween(ctx, denom, currency, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []response.PricePoint
+	var lastPeriod time.Time
+	for _, snapshot := range snapshots {
+		period := snapshot.TakenAt.Truncate(step)
+		point := response.PricePoint{
+			Time:    period.UTC().Format(time.RFC3339),
+			TakenAt: snapshot.TakenAt.UTC().Format(time.RFC3339),
+			Price:   snapshot.Price,
+			Source:  snapshot.Source,
+		}
+
+		//the snapshots are sorted, a later one of the same period replaces the previous
+		if len(result) > 0 && period.Equal(lastPeriod) {
+			result[len(result)-1] = point
+		} else {
+			result = append(result, point)
+		}
+		lastPeriod = period
+	}
+
+	return result, nil
+}
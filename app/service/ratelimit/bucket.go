@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket holding at most Burst tokens, refilled with Rate tokens per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result is the outcome of taking tokens from a bucket
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	//time until the requested tokens are available, when not allowed
+	RetryAfter time.Duration
+	//time until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// Store keeps the buckets. Take must be atomic for a key.
+type Store interface {
+	Take(ctx context.Context, key string, cost int, limit Limit, now time.Time) (Result, error)
+}
+
+// take refills a bucket that had tokens at last and takes cost tokens from it, if available.
+// It returns the tokens left in the bucket.
+func take(tokens float64, last time.Time, cost int, limit Limit, now time.Time) (float64, Result) {
+	elapsed := now.Sub(last).Seconds()
+	if elapsed > 0 {
+		tokens = math.Min(float64(limit.Burst), tokens+elapsed*limit.Rate)
+	}
+
+	res := Result{Limit: limit.Burst}
+	if tokens >= float64(cost) {
+		tokens -= float64(cost)
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((float64(cost) - tokens) / limit.Rate)
+	}
+
+	res.Remaining = int(math.Floor(tokens))
+	res.ResetAfter = secondsToDuration((float64(limit.Burst) - tokens) / limit.Rate)
+
+	return tokens, res
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
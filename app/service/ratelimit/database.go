@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+)
+
+const databaseCleanupInterval = 10 * time.Minute
+
+// DatabaseStore keeps the buckets in the rate_limit_bucket table, so they are shared by all the API replicas.
+// Every Take creates the bucket row when missing and locks it in a transaction.
+type DatabaseStore struct {
+	db internal.Database
+
+	mx          sync.Mutex
+	lastCleanup time.Time
+	//buckets not updated for this long are full and can be deleted
+	maxIdle time.Duration
+}
+
+func NewDatabaseStore(db internal.Database, maxIdle time.Duration) (*DatabaseStore, error) {
+	if db == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewDatabaseStore")
+	}
+
+	return &DatabaseStore{db: db, maxIdle: maxIdle}, nil
+}
+
+func (s *DatabaseStore) Take(ctx context.Context, key string, cost int, limit Limit, now time.Time) (res Result, err error) {
+	s.cleanup(ctx, now)
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	//the bucket row is created full first, so the select below always locks a row: a missing row is not locked and
+	//two first requests would both start from a full bucket. ON DUPLICATE KEY UPDATE takes the exclusive lock
+	//INSERT IGNORE would not, avoiding a deadlock with the select.
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rate_limit_bucket (bucket_key, tokens, updated_at_ms) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE bucket_key = bucket_key;`,
+		key, float64(limit.Burst), now.UnixMilli())
+	if err != nil {
+		return res, fmt.Errorf("could not create rate limit bucket: %w", err)
+	}
+
+	var row struct {
+		Tokens    float64 `db:"tokens"`
+		UpdatedAt int64   `db:"updated_at_ms"`
+	}
+	err = tx.GetContext(ctx, &row, `SELECT tokens, updated_at_ms FROM rate_limit_bucket WHERE bucket_key = ? FOR UPDATE`, key)
+	if err != nil {
+		return res, fmt.Errorf("could not read rate limit bucket: %w", err)
+	}
+
+	var tokens float64
+	tokens, res = take(row.Tokens, time.UnixMilli(row.UpdatedAt), cost, limit, now)
+
+	_, err = tx.ExecContext(ctx, `UPDATE rate_limit_bucket SET tokens = ?, updated_at_ms = ? WHERE bucket_key = ?`, tokens, now.UnixMilli(), key)
+	if err != nil {
+		return res, fmt.Errorf("could not save rate limit bucket: %w", err)
+	}
+
+	return res, tx.Commit()
+}
+
+// cleanup deletes, from time to time, the buckets that were not used recently
+func (s *DatabaseStore) cleanup(ctx context.Context, now time.Time) {
+	s.mx.Lock()
+	if now.Sub(s.lastCleanup) < databaseCleanupInterval {
+		s.mx.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mx.Unlock()
+
+	_, _ = s.db.ExecContext(ctx, `DELETE FROM rate_limit_bucket WHERE updated_at_ms < ?`, now.Add(-s.maxIdle).UnixMilli())
+}
@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/logging"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderApiKey = "X-API-Key"
+
+	HeaderLimit     = "X-RateLimit-Limit"
+	HeaderRemaining = "X-RateLimit-Remaining"
+	HeaderReset     = "X-RateLimit-Reset"
+
+	tierAnonymous = "anonymous"
+	tierPartner   = "partner"
+)
+
+type partner struct {
+	name string
+	key  string
+}
+
+// Limiter limits the requests of the anonymous clients by IP and the requests of the partners by API key
+type Limiter struct {
+	store  Store
+	logger logrus.FieldLogger
+
+	anonymous Limit
+	partner   Limit
+	partners  []partner
+
+	expensiveCost   int
+	expensiveRoutes map[string]bool
+}
+
+func NewLimiter(store Store, cfg config.RateLimit, expensiveRoutes []string, logger logrus.FieldLogger) (*Limiter, error) {
+	if store == nil || logger == nil {
+		return nil, fmt.Errorf("invalid dependencies provided to NewLimiter")
+	}
+
+	l := &Limiter{
+		store:           store,
+		logger:          logger.WithField("service", "RateLimiter"),
+		anonymous:       Limit{Rate: cfg.AnonymousRps, Burst: cfg.AnonymousBurst},
+		partner:         Limit{Rate: cfg.PartnerRps, Burst: cfg.PartnerBurst},
+		expensiveCost:   cfg.ExpensiveCost,
+		expensiveRoutes: make(map[string]bool),
+	}
+
+	for name, key := range cfg.ApiKeys {
+		l.partners = append(l.partners, partner{name: name, key: key})
+	}
+
+	for _, r := range expensiveRoutes {
+		l.expensiveRoutes[r] = true
+	}
+
+	return l, nil
+}
+
+// MaxRefill returns the time needed to refill an empty bucket of any tier
+func MaxRefill(cfg config.RateLimit) time.Duration {
+	return time.Duration(math.Max(
+		float64(cfg.AnonymousBurst)/cfg.AnonymousRps,
+		float64(cfg.PartnerBurst)/cfg.PartnerRps,
+	) * float64(time.Second))
+}
+
+// EchoMiddleware rejects the requests over the limit with 429 and sets the X-RateLimit-* headers on all responses.
+// Requests with an unknown API key are rejected with 401. When the store fails the request is allowed.
+// Requests for which skip returns true are not limited.
+func (l *Limiter) EchoMiddleware(skip func(ctx echo.Context) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if skip != nil && skip(ctx) {
+				return next(ctx)
+			}
+
+			tier, key, limit := tierAnonymous, "ip:"+ctx.RealIP(), l.anonymous
+			if apiKey := ctx.Request().Header.Get(HeaderApiKey); apiKey != "" {
+				p, ok := l.findPartner(apiKey)
+				if !ok {
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid API key")
+				}
+				tier, key, limit = tierPartner, "key:"+p.name, l.partner
+			}
+
+			cost := 1
+			if l.expensiveRoutes[ctx.Path()] {
+				cost = l.expensiveCost
+			}
+
+			res, err := l.store.Take(ctx.Request().Context(), key, cost, limit, time.Now())
+			if err != nil {
+				logging.FromContext(ctx, l.logger).WithError(err).Error("could not check the rate limit, request allowed")
+
+				return next(ctx)
+			}
+
+			h := ctx.Response().Header()
+			h.Set(HeaderLimit, strconv.Itoa(res.Limit))
+			h.Set(HeaderRemaining, strconv.Itoa(res.Remaining))
+			h.Set(HeaderReset, strconv.Itoa(ceilSeconds(res.ResetAfter)))
+			if !res.Allowed {
+				metrics.RateLimitRejected(tier)
+				h.Set(echo.HeaderRetryAfter, strconv.Itoa(ceilSeconds(res.RetryAfter)))
+
+				return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// findPartner compares the key with every partner key in constant time
+func (l *Limiter) findPartner(apiKey string) (partner, bool) {
+	var found partner
+	ok := false
+	for _, p := range l.partners {
+		if subtle.ConstantTimeCompare([]byte(p.key), []byte(apiKey)) == 1 {
+			found, ok = p, true
+		}
+	}
+
+	return found, ok
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memoryCleanupInterval = time.Minute
+
+type memoryBucket struct {
+	tokens float64
+	last   time.Time
+	//the time the bucket is full again, after which it can be forgotten
+	fullAt time.Time
+}
+
+// MemoryStore keeps the buckets in memory. Every replica of the API has its own buckets.
+type MemoryStore struct {
+	mx          sync.Mutex
+	buckets     map[string]*memoryBucket
+	lastCleanup time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*memoryBucket)}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, cost int, limit Limit, now time.Time) (Result, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.cleanup(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	var res Result
+	b.tokens, res = take(b.tokens, b.last, cost, limit, now)
+	b.last = now
+	b.fullAt = now.Add(res.ResetAfter)
+
+	return res, nil
+}
+
+// cleanup removes the full buckets, they are the same as a new bucket
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < memoryCleanupInterval {
+		return
+	}
+	s.lastCleanup = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
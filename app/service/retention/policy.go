@@ -0,0 +1,90 @@
+package retention
+
+import (
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/interval"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+)
+
+const day = time.Hour * 24
+
+// Policy tells which data is kept. Every interval length is kept at least as long as the finer ones
+// (see config.Retention), so a pruned range of a length is covered by the next coarser length.
+type Policy struct {
+	historyDays int
+	//interval length => days, 0 keeps them forever
+	intervalDays map[int]int
+	//from the finest to the coarsest
+	lengths []int
+}
+
+func NewPolicy(cfg config.Retention) *Policy {
+	p := &Policy{
+		historyDays:  cfg.HistoryDays,
+		intervalDays: make(map[int]int),
+	}
+
+	for _, r := range cfg.Intervals() {
+		p.intervalDays[r.Length] = r.Days
+		p.lengths = append(p.lengths, r.Length)
+	}
+
+	return p
+}
+
+// Lengths returns the interval lengths, from the finest to the coarsest
+func (p *Policy) Lengths() []int {
+	return p.lengths
+}
+
+// HistoryCutoff returns the time before which the trade history is pruned, zero when it is kept forever
+func (p *Policy) HistoryCutoff(now time.Time) time.Time {
+	return cutoff(p.historyDays, now)
+}
+
+// IntervalsCutoff returns the time before which the intervals of the given length are pruned, zero when
+// they are kept forever
+func (p *Policy) IntervalsCutoff(length int, now time.Time) time.Time {
+	return cutoff(p.intervalDays[length], now)
+}
+
+// Coarser returns the next coarser interval length, 0 for the coarsest
+func (p *Policy) Coarser(length int) int {
+	for i, l := range p.lengths {
+		if l == length && i+1 < len(p.lengths) {
+			return p.lengths[i+1]
+		}
+	}
+
+	return 0
+}
+
+// KeptSince returns the time since which the intervals of the given length are complete, zero when they are kept
+// forever. It is aligned to the coarser intervals, so the range before it is covered by whole coarser intervals.
+func (p *Policy) KeptSince(length int, now time.Time) time.Time {
+	c := p.IntervalsCutoff(length, now)
+	if c.IsZero() {
+		return c
+	}
+
+	align := length
+	if coarser := p.Coarser(length); coarser > 0 {
+		align = coarser
+	}
+
+	start, end := interval.GetTimestampInterval(c.Unix(), interval.Length(align))
+	if start.Before(c) {
+		return end
+	}
+
+	return start
+}
+
+func cutoff(days int, now time.Time) time.Time {
+	if days <= 0 {
+		return time.Time{}
+	}
+
+	return now.Add(-time.Duration(days) * day)
+}
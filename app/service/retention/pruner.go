@@ -0,0 +1,155 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+// deleteBatchSize keeps the delete statements short, so they don't lock the tables the sync writes to
+const deleteBatchSize = 5000
+
+type marketsRepo interface {
+	GetMarkets(ctx context.Context) ([]entity.Market, error)
+}
+
+type intervalsRepo interface {
+	CountUncovered(ctx context.Context, marketId string, length, coarserLength int, before time.Time) (int, error)
+	DeleteStartedBefore(ctx context.Context, marketId string, length int, before time.Time, limit int) (int64, error)
+}
+
+type historyRepo interface {
+	GetLastHistoryOrder(ctx context.Context, marketId string) (*entity.MarketHistory, error)
+	DeleteAddedToIntervalBefore(ctx context.Context, marketId string, before time.Time, keepId int, limit int) (int64, error)
+}
+
+// Pruner deletes the history and the intervals that are older than the retention policy allows
+type Pruner struct {
+	logger    logrus.FieldLogger
+	policy    *Policy
+	markets   marketsRepo
+	intervals intervalsRepo
+	history   historyRepo
+}
+
+func NewPruner(logger logrus.FieldLogger, policy *Policy, markets marketsRepo, intervals intervalsRepo, history historyRepo) (*Pruner, error) {
+	if logger == nil || policy == nil || markets == nil || intervals == nil || history == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewPruner")
+	}
+
+	return &Pruner{
+		logger:    logger.WithField("service", "RetentionPruner"),
+		policy:    policy,
+		markets:   markets,
+		intervals: intervals,
+		history:   history,
+	}, nil
+}
+
+// Prune applies the retention policy to all markets. A market failing does not stop the others.
+func (p *Pruner) Prune(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("prune", start, err) }()
+
+	markets, err := p.markets.GetMarkets(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, m := range markets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		l := p.logger.WithField(internal.LogFieldMarketId, m.MarketID)
+		if err := p.pruneIntervals(ctx, l, m.MarketID, start); err != nil {
+			l.WithError(err).Error("could not prune intervals")
+			errs = append(errs, err)
+		}
+
+		if err := p.pruneHistory(ctx, l, m.MarketID, start); err != nil {
+			l.WithError(err).Error("could not prune history")
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *Pruner) pruneIntervals(ctx context.Context, l logrus.FieldLogger, marketId string, now time.Time) error {
+	for _, length := range p.policy.Lengths() {
+		before := p.policy.IntervalsCutoff(length, now)
+		if before.IsZero() {
+			continue
+		}
+
+		ll := l.WithField("length", length)
+		//the coarsest intervals are only pruned on request, there is nothing to fall back to
+		if coarser := p.policy.Coarser(length); coarser > 0 {
+			uncovered, err := p.intervals.CountUncovered(ctx, marketId, length, coarser, before)
+			if err != nil {
+				return err
+			}
+
+			if uncovered > 0 {
+				ll.WithField("uncovered", uncovered).Warn("skipping intervals not covered by coarser intervals yet")
+				continue
+			}
+		}
+
+		deleted, err := deleteInBatches(func() (int64, error) {
+			return p.intervals.DeleteStartedBefore(ctx, marketId, length, before, deleteBatchSize)
+		})
+		if err != nil {
+			return err
+		}
+
+		ll.WithField("deleted", deleted).Info("intervals pruned")
+	}
+
+	return nil
+}
+
+func (p *Pruner) pruneHistory(ctx context.Context, l logrus.FieldLogger, marketId string, now time.Time) error {
+	before := p.policy.HistoryCutoff(now)
+	if before.IsZero() {
+		return nil
+	}
+
+	last, err := p.history.GetLastHistoryOrder(ctx, marketId)
+	if err != nil || last == nil {
+		return err
+	}
+
+	deleted, err := deleteInBatches(func() (int64, error) {
+		return p.history.DeleteAddedToIntervalBefore(ctx, marketId, before, last.ID, deleteBatchSize)
+	})
+	if err != nil {
+		return err
+	}
+
+	l.WithField("deleted", deleted).Info("history pruned")
+
+	return nil
+}
+
+func deleteInBatches(deleteBatch func() (int64, error)) (int64, error) {
+	var total int64
+	for {
+		deleted, err := deleteBatch()
+		if err != nil {
+			return total, err
+		}
+
+		total += deleted
+		if deleted < deleteBatchSize {
+			return total, nil
+		}
+	}
+}
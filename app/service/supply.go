@@ -1,29 +1,44 @@
 package service
 
 import (
+	"context"
+	math2 "cosmossdk.io/math"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/chain_registry"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
 	"github.com/sirupsen/logrus"
-	"math"
-	"strconv"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	totalSupplyCacheKey       = "supply:total_supply"
 	circulatingSupplyCacheKey = "supply:circulating_supply"
-
-	cacheExpireSeconds = 600
+	supplyListCacheKey        = "supply:list"
 )
 
 type chainRegistry interface {
-	GetAssetDetails(denom string) (*chain_registry.ChainRegistryAsset, error)
+	GetAssetDetails(ctx context.Context, denom string) (*chain_registry.ChainRegistryAsset, error)
+}
+
+type supplyRegistry interface {
+	chainRegistry
+	GetAssets(ctx context.Context) ([]chain_registry.ChainRegistryAsset, error)
 }
 
 type RestDataProvider interface {
-	GetTotalSupply(denom string) (int64, error)
-	GetCommunityPoolTotal(denom string) (float64, error)
+	GetTotalSupply(ctx context.Context, denom string) (math2.Int, error)
+	GetAllSupply(ctx context.Context) (map[string]math2.Int, error)
+	GetCommunityPoolTotal(ctx context.Context, denom string) (math2.LegacyDec, error)
+	GetBalance(ctx context.Context, address, denom string) (math2.Int, error)
+	GetModuleAccountAddress(ctx context.Context, name string) (string, error)
 }
 
 type Cache interface {
@@ -35,10 +50,15 @@ type Supply struct {
 	cache        Cache
 	dataProvider RestDataProvider
 	logger       logrus.FieldLogger
-	registry     chainRegistry
+	registry     supplyRegistry
+	cacheTtl     time.Duration
+	//denom => amounts left out of its circulating supply
+	exclusions map[string][]config.SupplyExclusion
+	//module name => address
+	moduleAddresses sync.Map
 }
 
-func NewSupplyService(logger logrus.FieldLogger, cache Cache, provider RestDataProvider, registry chainRegistry) (*Supply, error) {
+func NewSupplyService(logger logrus.FieldLogger, cache Cache, provider RestDataProvider, registry supplyRegistry, cacheTtl time.Duration, exclusions map[string][]config.SupplyExclusion) (*Supply, error) {
 	if logger == nil || cache == nil || provider == nil || registry == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewSupplyService")
 	}
@@ -48,11 +68,13 @@ func NewSupplyService(logger logrus.FieldLogger, cache Cache, provider RestDataP
 		dataProvider: provider,
 		logger:       logger.WithField("service", "Service.Supply"),
 		registry:     registry,
+		cacheTtl:     cacheTtl,
+		exclusions:   exclusions,
 	}, nil
 }
 
-func (s *Supply) GetTotalSupply(denom string) (string, error) {
-	display, err := s.getDisplayDenom(denom)
+func (s *Supply) GetTotalSupply(ctx context.Context, denom string) (string, error) {
+	display, err := s.getDisplayDenom(ctx, denom)
 	if err != nil {
 		return "", err
 	}
@@ -67,17 +89,16 @@ func (s *Supply) GetTotalSupply(denom string) (string, error) {
 		return string(cacheValue), nil
 	}
 
-	uTotalSupply, err := s.dataProvider.GetTotalSupply(denom)
+	uTotalSupply, err := s.dataProvider.GetTotalSupply(ctx, denom)
 	if err != nil {
 		s.logger.Errorf("failed to get total supply from data provider: %v", err)
 
-		return "0", nil
+		return "", err
 	}
 
-	totalSupply := float64(uTotalSupply) / math.Pow(10, float64(display.Exponent))
-	supplyStr := fmt.Sprintf("%.2f", totalSupply)
+	supplyStr := toDisplayString(uTotalSupply, display.Exponent)
 
-	err = s.cache.Set(cacheKey, []byte(supplyStr), time.Duration(cacheExpireSeconds)*time.Second)
+	err = s.cache.Set(cacheKey, []byte(supplyStr), s.cacheTtl)
 	if err != nil {
 		s.logger.Errorf("failed to set total supply to cache: %v", err)
 	}
@@ -85,14 +106,21 @@ func (s *Supply) GetTotalSupply(denom string) (string, error) {
 	return supplyStr, nil
 }
 
-func (s *Supply) GetCirculatingSupply(denom string) (string, error) {
-	display, err := s.getDisplayDenom(denom)
+func (s *Supply) GetCirculatingSupply(ctx context.Context, denom string) (string, error) {
+	breakdown, err := s.GetCirculatingBreakdown(ctx, denom)
 	if err != nil {
 		return "", err
 	}
 
-	if !display.IsBZE() {
-		return s.GetTotalSupply(denom)
+	return breakdown.Circulating, nil
+}
+
+// GetCirculatingBreakdown returns the circulating supply together with every amount excluded from the total
+// supply (see config.Supply.Exclusions), so the number can be audited. Denoms without exclusions circulate entirely.
+func (s *Supply) GetCirculatingBreakdown(ctx context.Context, denom string) (*response.CirculatingSupply, error) {
+	display, err := s.getDisplayDenom(ctx, denom)
+	if err != nil {
+		return nil, err
 	}
 
 	cacheKey := s.getCirculatingSupplyCacheKey(denom)
@@ -102,34 +130,224 @@ func (s *Supply) GetCirculatingSupply(denom string) (string, error) {
 	}
 
 	if cacheValue != nil {
-		return string(cacheValue), nil
+		var cached response.CirculatingSupply
+		if err = json.Unmarshal(cacheValue, &cached); err == nil {
+			return &cached, nil
+		}
+		s.logger.Errorf("failed to decode cached circulating supply: %v", err)
 	}
 
-	// Get the total supply as string
-	totalSupplyStr, _ := s.GetTotalSupply(denom)
+	total, err := s.dataProvider.GetTotalSupply(ctx, denom)
+	if err != nil {
+		s.logger.Errorf("failed to get total supply from data provider: %v", err)
 
-	// Convert total supply to float64
-	totalSupplyFloat, _ := strconv.ParseFloat(totalSupplyStr, 64)
+		return nil, err
+	}
 
-	// Get the community pool total
-	communityPoolTotal, err := s.dataProvider.GetCommunityPoolTotal(denom)
+	excluded, err := s.getExcluded(ctx, denom)
 	if err != nil {
-		s.logger.Errorf("failed to get community pool funds: %v", err)
+		s.logger.Errorf("failed to get the amounts excluded from the circulating supply: %v", err)
 
-		return "0", nil
+		return nil, err
 	}
 
-	adjustedPoolTotal := communityPoolTotal / math.Pow(10, float64(display.Exponent))
+	result := &response.CirculatingSupply{
+		Denom:    denom,
+		Total:    toDisplayString(total, display.Exponent),
+		Excluded: []response.ExcludedSupply{},
+	}
+
+	circulating := total
+	for _, e := range excluded {
+		circulating = circulating.Sub(e.amount)
+		result.Excluded = append(result.Excluded, response.ExcludedSupply{
+			Label:   e.Label,
+			Kind:    e.Kind,
+			Address: e.address,
+			Module:  e.module(),
+			Amount:  toDisplayString(e.amount, display.Exponent),
+		})
+	}
+	result.Circulating = toDisplayString(circulating, display.Exponent)
 
-	// Calculate the adjusted supply
-	adjustedSupply := totalSupplyFloat - adjustedPoolTotal
-	resultStr := fmt.Sprintf("%.2f", adjustedSupply)
-	err = s.cache.Set(cacheKey, []byte(resultStr), time.Duration(cacheExpireSeconds)*time.Second)
+	encoded, err := json.Marshal(result)
+	if err == nil {
+		err = s.cache.Set(cacheKey, encoded, s.cacheTtl)
+	}
 	if err != nil {
 		s.logger.Errorf("failed to set circulating supply to cache: %v", err)
 	}
 
-	return resultStr, nil
+	return result, nil
+}
+
+// ListSupply returns the total and circulating supply of every chain registry asset known by the chain, sorted by denom
+func (s *Supply) ListSupply(ctx context.Context) ([]response.SupplyListItem, error) {
+	cached, err := s.cache.Get(supplyListCacheKey)
+	if err != nil {
+		s.logger.Errorf("failed to get supply list from cache: %v", err)
+	}
+
+	if cached != nil {
+		var result []response.SupplyListItem
+		if err = json.Unmarshal(cached, &result); err == nil {
+			return result, nil
+		}
+		s.logger.Errorf("failed to decode cached supply list: %v", err)
+	}
+
+	assets, err := s.registry.GetAssets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := s.dataProvider.GetAllSupply(ctx)
+	if err != nil {
+		s.logger.Errorf("failed to get all the supply from data provider: %v", err)
+
+		return nil, err
+	}
+
+	result := []response.SupplyListItem{}
+	for _, asset := range assets {
+		total, ok := all[asset.Base]
+		display := asset.GetDisplayDenomUnit()
+		if !ok || display == nil {
+			continue
+		}
+
+		excluded, err := s.getExcluded(ctx, asset.Base)
+		if err != nil {
+			s.logger.WithField("denom", asset.Base).Errorf("failed to get the amounts excluded from the circulating supply: %v", err)
+
+			return nil, err
+		}
+
+		circulating := total
+		for _, e := range excluded {
+			circulating = circulating.Sub(e.amount)
+		}
+
+		result = append(result, response.SupplyListItem{
+			Denom:       asset.Base,
+			Symbol:      asset.Symbol,
+			Total:       toDisplayString(total, display.Exponent),
+			Circulating: toDisplayString(circulating, display.Exponent),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Denom < result[j].Denom })
+
+	encoded, err := json.Marshal(result)
+	if err == nil {
+		err = s.cache.Set(supplyListCacheKey, encoded, s.cacheTtl)
+	}
+	if err != nil {
+		s.logger.Errorf("failed to set supply list to cache: %v", err)
+	}
+
+	return result, nil
+}
+
+// GetSnapshot returns the current supply of the denom in base units, bypassing the cache.
+// The circulating supply excludes the same amounts as GetCirculatingBreakdown.
+func (s *Supply) GetSnapshot(ctx context.Context, denom string) (*entity.SupplySnapshot, error) {
+	total, err := s.dataProvider.GetTotalSupply(ctx, denom)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded, err := s.getExcluded(ctx, denom)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, circulating := math2.ZeroInt(), total
+	for _, e := range excluded {
+		circulating = circulating.Sub(e.amount)
+		if e.Kind == config.SupplyExclusionCommunityPool {
+			pool = pool.Add(e.amount)
+		}
+	}
+
+	return &entity.SupplySnapshot{
+		Denom:         denom,
+		Total:         total.String(),
+		CommunityPool: pool.String(),
+		Circulating:   circulating.String(),
+		TakenAt:       time.Now().UTC(),
+	}, nil
+}
+
+// excludedAmount is the amount (base units) held by an excluded account
+type excludedAmount struct {
+	config.SupplyExclusion
+	address string
+	amount  math2.Int
+}
+
+func (e excludedAmount) module() string {
+	if e.Kind != config.SupplyExclusionModule {
+		return ""
+	}
+
+	return e.Account
+}
+
+// getExcluded reads the current amount of every exclusion configured for the denom
+func (s *Supply) getExcluded(ctx context.Context, denom string) ([]excludedAmount, error) {
+	var result []excludedAmount
+	for _, e := range s.exclusions[denom] {
+		item := excludedAmount{SupplyExclusion: e, address: e.Account}
+		switch e.Kind {
+		case config.SupplyExclusionCommunityPool:
+			pool, err := s.dataProvider.GetCommunityPoolTotal(ctx, denom)
+			if err != nil {
+				return nil, fmt.Errorf("community pool: %w", err)
+			}
+			item.amount = pool.TruncateInt()
+		case config.SupplyExclusionModule:
+			address, err := s.getModuleAddress(ctx, e.Account)
+			if err != nil {
+				return nil, fmt.Errorf("module %s: %w", e.Account, err)
+			}
+			item.address = address
+			fallthrough
+		default:
+			balance, err := s.dataProvider.GetBalance(ctx, item.address, denom)
+			if err != nil {
+				return nil, fmt.Errorf("%s balance: %w", e.Label, err)
+			}
+			item.amount = balance
+		}
+
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// getModuleAddress returns the address of the module account, it never changes so it is read only once
+func (s *Supply) getModuleAddress(ctx context.Context, name string) (string, error) {
+	if address, ok := s.moduleAddresses.Load(name); ok {
+		return address.(string), nil
+	}
+
+	address, err := s.dataProvider.GetModuleAccountAddress(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	s.moduleAddresses.Store(name, address)
+
+	return address, nil
+}
+
+// toDisplayString converts the base units amount to display units rounded to 2 decimals
+func toDisplayString(amount math2.Int, exponent int) string {
+	cents := math2.LegacyNewDecFromIntWithPrec(amount, int64(exponent)).MulInt64(100).RoundInt()
+	result := math2.LegacyNewDecFromIntWithPrec(cents, 2).String()
+
+	return result[:strings.Index(result, ".")+3]
 }
 
 func (s *Supply) getTotalSupplyCacheKey(denom string) string {
@@ -140,15 +358,45 @@ func (s *Supply) getCirculatingSupplyCacheKey(denom string) string {
 	return fmt.Sprintf("%s:%s", circulatingSupplyCacheKey, denom)
 }
 
-func (s *Supply) getDisplayDenom(denom string) (*chain_registry.ChainRegistryAssetDenom, error) {
-	details, err := s.registry.GetAssetDetails(denom)
+// registryErr marks the errors of the chain registry (unknown denom, no display unit, registry unavailable) so they
+// can be told apart from the errors of the data provider
+type registryErr struct {
+	err error
+}
+
+func (e registryErr) Error() string {
+	return e.err.Error()
+}
+
+func (e registryErr) Unwrap() error {
+	return e.err
+}
+
+// IsRegistryErr returns true when the supply could not be computed because of the chain registry
+func IsRegistryErr(err error) bool {
+	var regErr registryErr
+
+	return errors.As(err, &regErr)
+}
+
+func (s *Supply) getDisplayDenom(ctx context.Context, denom string) (*chain_registry.ChainRegistryAssetDenom, error) {
+	display, err := getDisplayDenom(ctx, s.registry, denom)
+	if err != nil {
+		return nil, registryErr{err: err}
+	}
+
+	return display, nil
+}
+
+func getDisplayDenom(ctx context.Context, registry chainRegistry, denom string) (*chain_registry.ChainRegistryAssetDenom, error) {
+	details, err := registry.GetAssetDetails(ctx, denom)
 	if err != nil {
-		return nil, fmt.Errorf("denom %s not found in registry", denom)
+		return nil, err
 	}
 
 	display := details.GetDisplayDenomUnit()
 	if display == nil {
-		return nil, fmt.Errorf("%s has no display denomination", denom)
+		return nil, internal.NewNotFoundErr(fmt.Sprintf("%s has no display denomination", denom))
 	}
 
 	return display, nil
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	math2 "cosmossdk.io/math"
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/response"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type supplySnapshotStorage interface {
+	Save(ctx context.Context, s *entity.SupplySnapshot) error
+	GetBetween(ctx context.Context, denom string, from, to time.Time) ([]entity.SupplySnapshot, error)
+}
+
+type supplySnapshotSource interface {
+	GetSnapshot(ctx context.Context, denom string) (*entity.SupplySnapshot, error)
+}
+
+// SupplyHistory saves the supply of the tracked denoms over time and reads it back
+type SupplyHistory struct {
+	logger   logrus.FieldLogger
+	storage  supplySnapshotStorage
+	source   supplySnapshotSource
+	registry chainRegistry
+}
+
+func NewSupplyHistoryService(logger logrus.FieldLogger, storage supplySnapshotStorage, source supplySnapshotSource, registry chainRegistry) (*SupplyHistory, error) {
+	if logger == nil || storage == nil || source == nil || registry == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewSupplyHistoryService")
+	}
+
+	return &SupplyHistory{
+		logger:   logger.WithField("service", "Service.SupplyHistory"),
+		storage:  storage,
+		source:   source,
+		registry: registry,
+	}, nil
+}
+
+// TakeSnapshots saves the current supply of the denoms. A failing denom does not stop the others.
+func (s *SupplyHistory) TakeSnapshots(ctx context.Context, denoms []string) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("supply_snapshot", start, err) }()
+
+	var errs []error
+	for _, denom := range denoms {
+		l := s.logger.WithField("denom", denom)
+		snapshot, err := s.source.GetSnapshot(ctx, denom)
+		if err != nil {
+			l.WithError(err).Error("could not get the supply")
+			errs = append(errs, fmt.Errorf("%s: %w", denom, err))
+
+			continue
+		}
+
+		if err = s.storage.Save(ctx, snapshot); err != nil {
+			l.WithError(err).Error("could not save the supply snapshot")
+			errs = append(errs, fmt.Errorf("%s: %w", denom, err))
+
+			continue
+		}
+
+		l.WithField("circulating", snapshot.Circulating).Debug("supply snapshot saved")
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetHistory returns the supply at the end of each period of the given length in [from, to), that is the last
+// snapshot taken in the period. The periods without snapshots are skipped.
+func (s *SupplyHistory) GetHistory(ctx context.Context, denom string, from, to time.Time, step time.Duration) ([]response.SupplyPoint, error) {
+	display, err := getDisplayDenom(ctx, s.registry, denom)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.storage.GetBetween(ctx, denom, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []response.SupplyPoint
+	var lastPeriod time.Time
+	for _, snapshot := range snapshots {
+		period := snapshot.TakenAt.Truncate(step)
+		point := response.SupplyPoint{
+			Time:          period.UTC().Format(time.RFC3339),
+			TakenAt:       snapshot.TakenAt.UTC().Format(time.RFC3339),
+			Total:         toDisplayAmount(snapshot.Total, display.Exponent),
+			CommunityPool: toDisplayAmount(snapshot.CommunityPool, display.Exponent),
+			Circulating:   toDisplayAmount(snapshot.Circulating, display.Exponent),
+		}
+
+		//the snapshots are sorted, a later one of the same period replaces the previous
+		if len(result) > 0 && period.Equal(lastPeriod) {
+			result[len(result)-1] = point
+		} else {
+			result = append(result, point)
+		}
+		lastPeriod = period
+	}
+
+	return result, nil
+}
+
+// toDisplayAmount converts an amount in base units to display units
+func toDisplayAmount(amount string, exponent int) string {
+	amt, ok := math2.NewIntFromString(amount)
+	if !ok {
+		return "0"
+	}
+
+	return converter.TrimAmountTrailingZeros(math2.LegacyNewDecFromIntWithPrec(amt, int64(exponent)).String())
+}
@@ -0,0 +1,133 @@
+package sync
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	cointrunkTypes "github.com/bze-alphateam/bze/x/cointrunk/types"
+	"github.com/sirupsen/logrus"
+)
+
+const cointrunkBatchSize = 500
+
+type cointrunkProvider interface {
+	GetAllArticles(ctx context.Context) ([]cointrunkTypes.Article, error)
+	GetArticlesAfter(ctx context.Context, lastId uint64) ([]cointrunkTypes.Article, error)
+	GetAllPublishers(ctx context.Context) ([]cointrunkTypes.Publisher, error)
+}
+
+type cointrunkRepo interface {
+	SaveArticles(ctx context.Context, items []*entity.CointrunkArticle) error
+	SavePublishers(ctx context.Context, items []*entity.CointrunkPublisher) error
+	GetLastArticleId(ctx context.Context) (uint64, error)
+}
+
+// Cointrunk indexes the articles published on chain and their publishers
+type Cointrunk struct {
+	storage  cointrunkRepo
+	logger   logrus.FieldLogger
+	provider cointrunkProvider
+}
+
+func NewCointrunkSync(logger logrus.FieldLogger, storage cointrunkRepo, provider cointrunkProvider) (*Cointrunk, error) {
+	if storage == nil || logger == nil || provider == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewCointrunkSync")
+	}
+
+	return &Cointrunk{
+		storage:  storage,
+		logger:   logger.WithField("service", "CointrunkSync"),
+		provider: provider,
+	}, nil
+}
+
+// SyncAll saves all the publishers, then all the articles. The articles can not be deleted on chain,
+// so saving them all again only updates the existing ones.
+func (c *Cointrunk) SyncAll(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("cointrunk", start, err) }()
+
+	if err = c.syncPublishers(ctx, nil); err != nil {
+		return err
+	}
+
+	articles, err := c.provider.GetAllArticles(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.saveArticles(ctx, articles)
+}
+
+// SyncNewArticles saves the articles published since the latest article saved
+func (c *Cointrunk) SyncNewArticles(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("cointrunk_articles", start, err) }()
+
+	lastId, err := c.storage.GetLastArticleId(ctx)
+	if err != nil {
+		return err
+	}
+
+	articles, err := c.provider.GetArticlesAfter(ctx, lastId)
+	if err != nil {
+		return err
+	}
+
+	return c.saveArticles(ctx, articles)
+}
+
+// SyncPublishers saves the publishers with the given addresses, all of them when no address is given. The chain
+// only lists all the publishers, they are few.
+func (c *Cointrunk) SyncPublishers(ctx context.Context, addresses ...string) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("cointrunk_publishers", start, err) }()
+
+	return c.syncPublishers(ctx, addresses)
+}
+
+func (c *Cointrunk) syncPublishers(ctx context.Context, addresses []string) error {
+	publishers, err := c.provider.GetAllPublishers(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pEntities []*entity.CointrunkPublisher
+	for _, source := range publishers {
+		if len(addresses) > 0 && !slices.Contains(addresses, source.GetAddress()) {
+			continue
+		}
+
+		pEntities = append(pEntities, converter.NewCointrunkPublisherEntity(&source))
+	}
+
+	c.logger.Infof("saving %d publishers", len(pEntities))
+	for _, batch := range converter.SplitSlice(pEntities, cointrunkBatchSize) {
+		if err = c.storage.SavePublishers(ctx, batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Cointrunk) saveArticles(ctx context.Context, articles []cointrunkTypes.Article) error {
+	var aEntities []*entity.CointrunkArticle
+	for _, source := range articles {
+		aEntities = append(aEntities, converter.NewCointrunkArticleEntity(&source))
+	}
+
+	c.logger.Infof("saving %d articles", len(aEntities))
+	for _, batch := range converter.SplitSlice(aEntities, cointrunkBatchSize) {
+		if err := c.storage.SaveArticles(ctx, batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,9 +1,11 @@
 package sync
 
 import (
+	"context"
 	"github.com/bze-alphateam/bze-aggregator-api/app/dto/chain_registry"
 	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/sirupsen/logrus"
@@ -15,16 +17,16 @@ const (
 )
 
 type assetProvider interface {
-	GetAssetDetails(denom string) (*chain_registry.ChainRegistryAsset, error)
+	GetAssetDetailsOrDefault(ctx context.Context, denom string) (*chain_registry.ChainRegistryAsset, error)
 }
 
 type historyProvider interface {
-	GetMarketHistory(marketId string, limit uint64, key string) (list []types.HistoryOrder, paginationKey string, err error)
+	GetMarketHistory(ctx context.Context, marketId string, limit uint64, key string) (list []types.HistoryOrder, paginationKey string, err error)
 }
 
 type historyStorage interface {
-	GetLastHistoryOrder(marketId string) (*entity.MarketHistory, error)
-	SaveMarketHistoryOrders(marketId string, orders []*entity.MarketHistory, clearExecutedAt []time.Time) error
+	GetLastHistoryOrder(ctx context.Context, marketId string) (*entity.MarketHistory, error)
+	SaveMarketHistoryOrders(ctx context.Context, marketId string, orders []*entity.MarketHistory, clearExecutedAt []time.Time) error
 }
 
 type History struct {
@@ -53,7 +55,10 @@ func NewHistorySync(logger logrus.FieldLogger, dataProvider historyProvider, sto
 // SyncHistory syncs the history orders for the given market.
 // It resumes from the last order found in DB for this market
 // if batchSize is 0, it will use the value of requestedHistoryLength constant as limit
-func (h *History) SyncHistory(market *types.Market, batchSize uint64) error {
+func (h *History) SyncHistory(ctx context.Context, market *types.Market, batchSize uint64) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("history", start, err) }()
+
 	marketId := converter.GetMarketId(market.GetBase(), market.GetQuote())
 
 	h.locker.Lock(getHistoryLockKey(marketId))
@@ -64,28 +69,30 @@ func (h *History) SyncHistory(market *types.Market, batchSize uint64) error {
 		histLimit = batchSize
 	}
 
-	l := h.logger.WithField("market", marketId).WithField("process", "SyncHistory")
+	l := h.logger.WithField(internal.LogFieldMarketId, marketId).WithField("process", "SyncHistory")
 	l.Info("preparing to sync history")
-	conv, err := converter.NewTypesConverter(h.assetProvider, market)
+	conv, err := converter.NewTypesConverter(ctx, h.assetProvider, market)
 	if err != nil {
 		return err
 	}
 
 	l.Info("fetching last order from market's history")
-	last, err := h.storage.GetLastHistoryOrder(marketId)
+	last, err := h.storage.GetLastHistoryOrder(ctx, marketId)
 	if err != nil {
 		return err
 	}
 
 	if last == nil {
 		l.Info("no last order found. Will sync the entire history")
+	} else {
+		metrics.SetLastSyncedTrade(marketId, last.ExecutedAt)
 	}
 
 	l.Info("starting loop to fetch history")
 	var key string
 	for {
 		l.Info("fetching market history from blockchain")
-		hist, next, err := h.dataProvider.GetMarketHistory(marketId, histLimit, key)
+		hist, next, err := h.dataProvider.GetMarketHistory(ctx, marketId, histLimit, key)
 		if err != nil {
 			return err
 		}
@@ -95,7 +102,7 @@ func (h *History) SyncHistory(market *types.Market, batchSize uint64) error {
 			break
 		}
 
-		done, err := h.syncHistoryList(market, hist, last, conv)
+		done, err := h.syncHistoryList(ctx, market, hist, last, conv)
 		if err != nil {
 			return err
 		}
@@ -111,9 +118,9 @@ func (h *History) SyncHistory(market *types.Market, batchSize uint64) error {
 	return nil
 }
 
-func (h *History) syncHistoryList(market *types.Market, list []types.HistoryOrder, lastSyncedOrder *entity.MarketHistory, conv *converter.TypesConverter) (finished bool, err error) {
+func (h *History) syncHistoryList(ctx context.Context, market *types.Market, list []types.HistoryOrder, lastSyncedOrder *entity.MarketHistory, conv *converter.TypesConverter) (finished bool, err error) {
 	marketId := converter.GetMarketId(market.GetBase(), market.GetQuote())
-	l := h.logger.WithField("market", marketId)
+	l := h.logger.WithField(internal.LogFieldMarketId, marketId)
 	l.Info("syncing history list")
 	if len(list) == 0 {
 		l.Info("no history found on the blockchain")
@@ -144,7 +151,14 @@ func (h *History) syncHistoryList(market *types.Market, list []types.HistoryOrde
 		return
 	}
 
-	err = h.storage.SaveMarketHistoryOrders(marketId, toUpdate, toClear)
+	err = h.storage.SaveMarketHistoryOrders(ctx, marketId, toUpdate, toClear)
+	if err != nil {
+		return
+	}
+
+	for _, hist := range toUpdate {
+		metrics.SetLastSyncedTrade(marketId, hist.ExecutedAt)
+	}
 	l.Info("successfully synced history list")
 
 	return
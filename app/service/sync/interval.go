@@ -1,10 +1,12 @@
 package sync
 
 import (
+	"context"
 	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/interval"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	tradebinTypes "github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/sirupsen/logrus"
@@ -13,13 +15,17 @@ import (
 )
 
 type histStorage interface {
-	GetByExecutedAt(marketId string, executedAt time.Time) ([]entity.MarketHistory, error)
-	GetOldestNotAddedToInterval(marketId string) (*entity.MarketHistory, error)
-	MarkAsAddedToInterval(ids []int) error
+	GetByExecutedAt(ctx context.Context, marketId string, executedAt time.Time) ([]entity.MarketHistory, error)
+	GetOldestNotAddedToInterval(ctx context.Context, marketId string) (*entity.MarketHistory, error)
+	MarkAsAddedToInterval(ctx context.Context, ids []int) error
 }
 
 type intervalStorage interface {
-	Save([]*entity.MarketHistoryInterval) error
+	Save(ctx context.Context, items []*entity.MarketHistoryInterval) error
+}
+
+type statsTicker interface {
+	SyncStats(ctx context.Context, marketId string) error
 }
 
 type IntervalSync struct {
@@ -27,10 +33,11 @@ type IntervalSync struct {
 	hist            histStorage
 	locker          locker
 	intervalStorage intervalStorage
+	tickers         statsTicker
 }
 
-func NewIntervalSync(logger logrus.FieldLogger, histStorage histStorage, l locker, intervalStorage intervalStorage) (*IntervalSync, error) {
-	if logger == nil || histStorage == nil || l == nil || intervalStorage == nil {
+func NewIntervalSync(logger logrus.FieldLogger, histStorage histStorage, l locker, intervalStorage intervalStorage, tickers statsTicker) (*IntervalSync, error) {
+	if logger == nil || histStorage == nil || l == nil || intervalStorage == nil || tickers == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewIntervalSync")
 	}
 
@@ -39,20 +46,24 @@ func NewIntervalSync(logger logrus.FieldLogger, histStorage histStorage, l locke
 		hist:            histStorage,
 		locker:          l,
 		intervalStorage: intervalStorage,
+		tickers:         tickers,
 	}, nil
 }
 
 // SyncIntervals - queries for last intervals synced for each configured duration and tries to fill them from history
-func (i *IntervalSync) SyncIntervals(market *tradebinTypes.Market) error {
+func (i *IntervalSync) SyncIntervals(ctx context.Context, market *tradebinTypes.Market) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("intervals", start, err) }()
+
 	marketId := converter.GetMarketId(market.GetBase(), market.GetQuote())
 
 	i.locker.Lock(getIntervalLockKey(marketId))
 	defer i.locker.Unlock(getIntervalLockKey(marketId))
 
-	l := i.logger.WithField("market", marketId).WithField("process", "SyncIntervals")
+	l := i.logger.WithField(internal.LogFieldMarketId, marketId).WithField("process", "SyncIntervals")
 	l.Info("preparing to sync market intervals")
 
-	oldest, err := i.hist.GetOldestNotAddedToInterval(marketId)
+	oldest, err := i.hist.GetOldestNotAddedToInterval(ctx, marketId)
 	if err != nil {
 		return fmt.Errorf("error getting oldest not-added to interval: %s", err.Error())
 	}
@@ -62,7 +73,7 @@ func (i *IntervalSync) SyncIntervals(market *tradebinTypes.Market) error {
 	}
 
 	timestampToSync, _ := interval.GetTimestampInterval(oldest.ExecutedAt.Unix(), interval.GetBiggestDuration())
-	orders, err := i.hist.GetByExecutedAt(marketId, timestampToSync)
+	orders, err := i.hist.GetByExecutedAt(ctx, marketId, timestampToSync)
 	if err != nil {
 		return fmt.Errorf("error getting orders from history: %s", err.Error())
 	}
@@ -81,7 +92,7 @@ func (i *IntervalSync) SyncIntervals(market *tradebinTypes.Market) error {
 
 	toSaveBatches := converter.SplitIntervalsSlice(toSave, 1000)
 	for _, entities := range toSaveBatches {
-		err = i.intervalStorage.Save(entities)
+		err = i.intervalStorage.Save(ctx, entities)
 		if err != nil {
 			l.WithError(err).Error("could not save intervals batch")
 		}
@@ -93,7 +104,7 @@ func (i *IntervalSync) SyncIntervals(market *tradebinTypes.Market) error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			err = i.hist.MarkAsAddedToInterval(batch)
+			err = i.hist.MarkAsAddedToInterval(ctx, batch)
 			if err != nil {
 				l.WithError(err).Error("could not mark intervals as added")
 			}
@@ -103,5 +114,10 @@ func (i *IntervalSync) SyncIntervals(market *tradebinTypes.Market) error {
 	l.Info("waiting for history orders to be marked as done and intervals saved")
 	wg.Wait()
 
+	//the ticker is rebuilt periodically, a failure here only delays its stats
+	if err := i.tickers.SyncStats(ctx, marketId); err != nil {
+		l.WithError(err).Error("could not sync ticker stats")
+	}
+
 	return nil
 }
@@ -1,8 +1,10 @@
 package sync
 
 import (
+	"context"
 	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	tradebinTypes "github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/sirupsen/logrus"
@@ -10,15 +12,15 @@ import (
 )
 
 type marketProvider interface {
-	GetAllMarkets() ([]tradebinTypes.Market, error)
+	GetAllMarkets(ctx context.Context) ([]tradebinTypes.Market, error)
 }
 
 type marketRepo interface {
-	SaveIfNotExists(items []*entity.Market) error
+	SaveIfNotExists(ctx context.Context, items []*entity.Market) error
 }
 
 type marketHistoryRepo interface {
-	GetFirstMarketOrderTime(marketId string) (time.Time, error)
+	GetFirstMarketOrderTime(ctx context.Context, marketId string) (time.Time, error)
 }
 
 type Market struct {
@@ -41,8 +43,11 @@ func NewMarketSync(logger logrus.FieldLogger, storage marketRepo, provider marke
 	}, nil
 }
 
-func (m *Market) SyncMarkets() error {
-	list, err := m.provider.GetAllMarkets()
+func (m *Market) SyncMarkets(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("markets", start, err) }()
+
+	list, err := m.provider.GetAllMarkets(ctx)
 	if err != nil {
 		return err
 	}
@@ -52,7 +57,7 @@ func (m *Market) SyncMarkets() error {
 	var entities []*entity.Market
 	for _, source := range list {
 		target := converter.NewMarketEntity(&source)
-		hist, err := m.history.GetFirstMarketOrderTime(target.MarketID)
+		hist, err := m.history.GetFirstMarketOrderTime(ctx, target.MarketID)
 		if err != nil {
 			return err
 		}
@@ -65,7 +70,7 @@ func (m *Market) SyncMarkets() error {
 		entities = append(entities, target)
 	}
 
-	err = m.storage.SaveIfNotExists(entities)
+	err = m.storage.SaveIfNotExists(ctx, entities)
 	if err != nil {
 		return err
 	}
@@ -1,20 +1,28 @@
 package sync
 
 import (
+	"context"
+	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/sirupsen/logrus"
+	"time"
 )
 
 type orderDataProvider interface {
-	GetActiveBuyOrders(marketId string) ([]types.AggregatedOrder, error)
-	GetActiveSellOrders(marketId string) ([]types.AggregatedOrder, error)
+	GetActiveBuyOrders(ctx context.Context, marketId string) ([]types.AggregatedOrder, error)
+	GetActiveSellOrders(ctx context.Context, marketId string) ([]types.AggregatedOrder, error)
 }
 
 type orderStorage interface {
-	Upsert(list []*entity.MarketOrder, marketIds []string) error
+	Upsert(ctx context.Context, list []*entity.MarketOrder, marketIds []string) error
+}
+
+type orderBookTicker interface {
+	SyncOrderBook(ctx context.Context, marketId string, orders []*entity.MarketOrder) error
 }
 
 type Order struct {
@@ -23,12 +31,13 @@ type Order struct {
 	dataProvider  orderDataProvider
 	storage       orderStorage
 	assetProvider assetProvider
+	tickers       orderBookTicker
 
 	locker locker
 }
 
-func NewOrderSync(logger logrus.FieldLogger, dataProvider orderDataProvider, storage orderStorage, assetProvider assetProvider, l locker) (*Order, error) {
-	if logger == nil || dataProvider == nil || storage == nil || assetProvider == nil || l == nil {
+func NewOrderSync(logger logrus.FieldLogger, dataProvider orderDataProvider, storage orderStorage, assetProvider assetProvider, tickers orderBookTicker, l locker) (*Order, error) {
+	if logger == nil || dataProvider == nil || storage == nil || assetProvider == nil || tickers == nil || l == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewOrderSync")
 	}
 
@@ -37,64 +46,81 @@ func NewOrderSync(logger logrus.FieldLogger, dataProvider orderDataProvider, sto
 		dataProvider:  dataProvider,
 		storage:       storage,
 		assetProvider: assetProvider,
+		tickers:       tickers,
 		locker:        l,
 	}, nil
 }
 
-func (o *Order) SyncMarket(market *types.Market) error {
+func (o *Order) SyncMarket(ctx context.Context, market *types.Market) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("orders", start, err) }()
+
 	mId := converter.GetMarketId(market.GetBase(), market.GetQuote())
 
 	o.locker.Lock(getOrderLockKey(mId))
 	defer o.locker.Unlock(getOrderLockKey(mId))
 
-	buys, err := o.dataProvider.GetActiveBuyOrders(mId)
+	l := o.logger.WithField(internal.LogFieldMarketId, mId)
+
+	buys, err := o.dataProvider.GetActiveBuyOrders(ctx, mId)
 	if err != nil {
+		l.WithError(err).Error("error getting buy orders")
 		return err
 	}
 
-	sells, err := o.dataProvider.GetActiveSellOrders(mId)
+	sells, err := o.dataProvider.GetActiveSellOrders(ctx, mId)
 	if err != nil {
-		o.logger.WithError(err).Error("error getting syncing sell orders")
+		l.WithError(err).Error("error getting sell orders")
 		return err
 	}
 
 	list := append(buys, sells...)
-	err = o.syncList(list, market)
+	saved, err := o.syncList(ctx, l, list, market)
 	if err != nil {
-		o.logger.WithError(err).Error("error syncing sell orders")
+		l.WithError(err).Error("error syncing orders")
 		return err
 	}
 
+	//the ticker is rebuilt periodically, a failure here only delays its bid and ask
+	if err := o.tickers.SyncOrderBook(ctx, mId, saved); err != nil {
+		l.WithError(err).Error("error syncing ticker order book")
+	}
+
 	return nil
 }
 
-func (o *Order) syncList(source []types.AggregatedOrder, market *types.Market) error {
+// syncList replaces the stored orders of the market with the active ones and returns them. A market without active
+// orders has its stored orders deleted, so the ticker rebuilt from the stored orders has no bid and ask either.
+// The stored orders are kept when none of the active orders could be converted.
+func (o *Order) syncList(ctx context.Context, l logrus.FieldLogger, source []types.AggregatedOrder, market *types.Market) ([]*entity.MarketOrder, error) {
+	var entities []*entity.MarketOrder
 	if len(source) == 0 {
-		o.logger.Info("no active orders found")
+		l.Info("no active orders found")
+	} else {
+		conv, err := converter.NewTypesConverter(ctx, o.assetProvider, market)
+		if err != nil {
+			return nil, err
+		}
 
-		return nil
+		entities = o.convertAggregatedOrder(l, source, conv)
+		if len(entities) == 0 {
+			return nil, fmt.Errorf("none of the %d active orders could be converted", len(source))
+		}
 	}
 
-	conv, err := converter.NewTypesConverter(o.assetProvider, market)
+	err := o.storage.Upsert(ctx, entities, []string{converter.GetMarketId(market.GetBase(), market.GetQuote())})
 	if err != nil {
-		return err
-	}
-
-	entities := o.convertAggregatedOrder(source, conv)
-	if len(entities) == 0 {
-		o.logger.Info("no converter orders found")
-
-		return nil
+		return nil, err
 	}
 
-	return o.storage.Upsert(entities, []string{converter.GetMarketId(market.GetBase(), market.GetQuote())})
+	return entities, nil
 }
 
-func (o *Order) convertAggregatedOrder(source []types.AggregatedOrder, conv *converter.TypesConverter) (entities []*entity.MarketOrder) {
+func (o *Order) convertAggregatedOrder(l logrus.FieldLogger, source []types.AggregatedOrder, conv *converter.TypesConverter) (entities []*entity.MarketOrder) {
 	for _, order := range source {
 		e, err := conv.AggregatedOrderToOrderEntity(&order)
 		if err != nil {
-			o.logger.WithError(err).Error("error converting order proto to entity")
+			l.WithError(err).Error("error converting order proto to entity")
 			continue
 		}
 
@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/dto/chain_registry"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze/x/tradebin/types"
+	"github.com/sirupsen/logrus"
+)
+
+type stubOrders struct {
+	buys, sells []types.AggregatedOrder
+}
+
+func (s *stubOrders) GetActiveBuyOrders(context.Context, string) ([]types.AggregatedOrder, error) {
+	return s.buys, nil
+}
+
+func (s *stubOrders) GetActiveSellOrders(context.Context, string) ([]types.AggregatedOrder, error) {
+	return s.sells, nil
+}
+
+// memoryOrders keeps the orders of the markets like MarketOrderRepository.Upsert
+type memoryOrders struct {
+	byMarket map[string][]*entity.MarketOrder
+}
+
+func (m *memoryOrders) Upsert(_ context.Context, list []*entity.MarketOrder, marketIds []string) error {
+	for _, id := range marketIds {
+		delete(m.byMarket, id)
+	}
+	for _, o := range list {
+		m.byMarket[o.MarketID] = append(m.byMarket[o.MarketID], o)
+	}
+
+	return nil
+}
+
+type stubAssets struct{}
+
+func (stubAssets) GetAssetDetailsOrDefault(_ context.Context, denom string) (*chain_registry.ChainRegistryAsset, error) {
+	return &chain_registry.ChainRegistryAsset{
+		Base:       denom,
+		Display:    denom,
+		DenomUnits: []chain_registry.ChainRegistryAssetDenom{{Denom: denom}},
+	}, nil
+}
+
+type stubTickers struct {
+	synced map[string][]*entity.MarketOrder
+}
+
+func (s *stubTickers) SyncOrderBook(_ context.Context, marketId string, orders []*entity.MarketOrder) error {
+	s.synced[marketId] = orders
+
+	return nil
+}
+
+type noLock struct{}
+
+func (noLock) Lock(string)   {}
+func (noLock) Unlock(string) {}
+
+const testMarketId = "ubze/uusdc"
+
+func newTestOrderSync(t *testing.T, provider *stubOrders) (*Order, *memoryOrders, *stubTickers) {
+	t.Helper()
+
+	stored := &memoryOrders{byMarket: map[string][]*entity.MarketOrder{
+		testMarketId: {{MarketID: testMarketId, OrderType: "buy", Amount: "10", Price: "1"}},
+	}}
+	tickers := &stubTickers{synced: make(map[string][]*entity.MarketOrder)}
+
+	o, err := NewOrderSync(logrus.New(), provider, stored, stubAssets{}, tickers, noLock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return o, stored, tickers
+}
+
+func TestSyncMarketDeletesTheOrdersOfAMarketWithoutActiveOrders(t *testing.T) {
+	o, stored, tickers := newTestOrderSync(t, &stubOrders{})
+
+	if err := o.SyncMarket(context.Background(), &types.Market{Base: "ubze", Quote: "uusdc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored.byMarket[testMarketId]) != 0 {
+		t.Fatalf("expected the stored orders deleted, got %d", len(stored.byMarket[testMarketId]))
+	}
+	if orders, ok := tickers.synced[testMarketId]; !ok || len(orders) != 0 {
+		t.Fatalf("expected the ticker synced without orders, got %v", orders)
+	}
+}
+
+func TestSyncMarketKeepsTheOrdersWhenNoneIsConverted(t *testing.T) {
+	o, stored, tickers := newTestOrderSync(t, &stubOrders{
+		buys: []types.AggregatedOrder{{MarketId: testMarketId, OrderType: "buy", Amount: "10", Price: "not a price"}},
+	})
+
+	if err := o.SyncMarket(context.Background(), &types.Market{Base: "ubze", Quote: "uusdc"}); err == nil {
+		t.Fatal("expected an error when no active order could be converted")
+	}
+
+	if len(stored.byMarket[testMarketId]) != 1 {
+		t.Fatalf("expected the stored orders kept, got %d", len(stored.byMarket[testMarketId]))
+	}
+	if _, ok := tickers.synced[testMarketId]; ok {
+		t.Fatal("the ticker order book must not be synced from a failed conversion")
+	}
+}
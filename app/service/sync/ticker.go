@@ -0,0 +1,188 @@
+package sync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/bze-alphateam/bze-aggregator-api/app/entity"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	tickerIntervalLength = 5 //minutes
+	tickerWindow         = time.Hour * 24
+)
+
+type tickerStorage interface {
+	SaveOrderBook(ctx context.Context, marketId string, bid, ask sql.NullString) error
+	SaveStats(ctx context.Context, stats *entity.TickerStats) error
+}
+
+type tickerIntervalsRepo interface {
+	GetIntervalsByExecutedAt(ctx context.Context, marketId string, executedAt time.Time, length int) ([]entity.MarketHistoryInterval, error)
+}
+
+type tickerHistoryRepo interface {
+	GetLastHistoryOrder(ctx context.Context, marketId string) (*entity.MarketHistory, error)
+}
+
+type tickerOrdersRepo interface {
+	GetHighestBuy(ctx context.Context, marketId string) (*entity.MarketOrder, error)
+	GetLowestSell(ctx context.Context, marketId string) (*entity.MarketOrder, error)
+}
+
+type tickerMarketsRepo interface {
+	GetMarkets(ctx context.Context) ([]entity.Market, error)
+}
+
+// TickerSync maintains the market_ticker table read by the tickers endpoint. The order and interval syncs update
+// the ticker of the market they synced, while RollWindow moves the 24h window of all markets forward.
+type TickerSync struct {
+	logger    logrus.FieldLogger
+	storage   tickerStorage
+	intervals tickerIntervalsRepo
+	history   tickerHistoryRepo
+	orders    tickerOrdersRepo
+	markets   tickerMarketsRepo
+}
+
+func NewTickerSync(logger logrus.FieldLogger, storage tickerStorage, intervals tickerIntervalsRepo, history tickerHistoryRepo, orders tickerOrdersRepo, markets tickerMarketsRepo) (*TickerSync, error) {
+	if logger == nil || storage == nil || intervals == nil || history == nil || orders == nil || markets == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewTickerSync")
+	}
+
+	return &TickerSync{
+		logger:    logger.WithField("service", "TickerSync"),
+		storage:   storage,
+		intervals: intervals,
+		history:   history,
+		orders:    orders,
+		markets:   markets,
+	}, nil
+}
+
+// SyncOrderBook saves the best bid and ask found in the orders just synced for the market
+func (t *TickerSync) SyncOrderBook(ctx context.Context, marketId string, orders []*entity.MarketOrder) error {
+	var bid, ask *entity.MarketOrder
+	for _, o := range orders {
+		switch o.OrderType {
+		case entity.OrderTypeBuy:
+			if bid == nil || o.PriceDec > bid.PriceDec {
+				bid = o
+			}
+		case entity.OrderTypeSell:
+			if ask == nil || o.PriceDec < ask.PriceDec {
+				ask = o
+			}
+		}
+	}
+
+	return t.storage.SaveOrderBook(ctx, marketId, orderPrice(bid), orderPrice(ask))
+}
+
+// SyncStats computes the 24h stats of the market from its 5 minutes intervals and its last trade
+func (t *TickerSync) SyncStats(ctx context.Context, marketId string) error {
+	now := time.Now()
+	intervals, err := t.intervals.GetIntervalsByExecutedAt(ctx, marketId, now.Add(-tickerWindow), tickerIntervalLength)
+	if err != nil {
+		return err
+	}
+
+	stats := &entity.TickerStats{MarketID: marketId, UpdatedAt: now}
+	if len(intervals) > 0 {
+		stats.OpenPrice = sql.NullString{String: intervals[0].OpenPrice, Valid: true}
+	}
+
+	high := math.LegacyZeroDec()
+	low := math.LegacyZeroDec()
+	bVolume := math.LegacyZeroDec()
+	qVolume := math.LegacyZeroDec()
+	for _, i := range intervals {
+		bVolume = bVolume.Add(math.LegacyMustNewDecFromStr(i.BaseVolume))
+		qVolume = qVolume.Add(math.LegacyMustNewDecFromStr(i.QuoteVolume))
+
+		iHigh := math.LegacyMustNewDecFromStr(i.HighestPrice)
+		iLow := math.LegacyMustNewDecFromStr(i.LowestPrice)
+		if iHigh.GT(high) {
+			high = iHigh
+		}
+
+		if iLow.LT(low) || low.IsZero() {
+			low = iLow
+		}
+	}
+	stats.High = high.String()
+	stats.Low = low.String()
+	stats.BaseVolume = bVolume.String()
+	stats.QuoteVolume = qVolume.String()
+
+	//the last price is only shown while the last trade is in the window
+	last, err := t.history.GetLastHistoryOrder(ctx, marketId)
+	if err != nil {
+		return err
+	}
+	if last != nil && last.ExecutedAt.After(now.Add(-tickerWindow)) {
+		stats.LastPrice = sql.NullString{String: last.Price, Valid: true}
+	}
+
+	return t.storage.SaveStats(ctx, stats)
+}
+
+// RollWindow rebuilds the ticker of every market: the 24h stats change even when a market has no new trades.
+// The order book is rebuilt from the stored orders, so the table can be filled from scratch.
+func (t *TickerSync) RollWindow(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveSync("tickers", start, err) }()
+
+	markets, err := t.markets.GetMarkets(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, m := range markets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		l := t.logger.WithField(internal.LogFieldMarketId, m.MarketID)
+		if err := t.rebuildOrderBook(ctx, m.MarketID); err != nil {
+			l.WithError(err).Error("could not rebuild ticker order book")
+			errs = append(errs, err)
+		}
+
+		if err := t.SyncStats(ctx, m.MarketID); err != nil {
+			l.WithError(err).Error("could not sync ticker stats")
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t *TickerSync) rebuildOrderBook(ctx context.Context, marketId string) error {
+	buy, err := t.orders.GetHighestBuy(ctx, marketId)
+	if err != nil {
+		return err
+	}
+
+	sell, err := t.orders.GetLowestSell(ctx, marketId)
+	if err != nil {
+		return err
+	}
+
+	return t.storage.SaveOrderBook(ctx, marketId, orderPrice(buy), orderPrice(sell))
+}
+
+func orderPrice(o *entity.MarketOrder) sql.NullString {
+	if o == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: o.Price, Valid: true}
+}
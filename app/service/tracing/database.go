@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Database wraps an internal.Database and records a client span for every call
+type Database struct {
+	db internal.Database
+}
+
+func NewDatabase(db internal.Database) *Database {
+	return &Database{db: db}
+}
+
+func (d *Database) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	ctx, span := d.startSpan(ctx, "NamedExec", query)
+	res, err := d.db.NamedExecContext(ctx, query, arg)
+	EndSpan(span, err)
+
+	return res, err
+}
+
+func (d *Database) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	_, span := d.startSpan(ctx, "Begin", "")
+	tx, err := d.db.BeginTxx(ctx, opts)
+	EndSpan(span, err)
+
+	return tx, err
+}
+
+func (d *Database) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := d.startSpan(ctx, "Get", query)
+	err := d.db.GetContext(ctx, dest, query, args...)
+	EndSpan(span, ignoreNoRows(err))
+
+	return err
+}
+
+func (d *Database) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := d.startSpan(ctx, "Exec", query)
+	res, err := d.db.ExecContext(ctx, query, args...)
+	EndSpan(span, err)
+
+	return res, err
+}
+
+func (d *Database) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := d.startSpan(ctx, "Select", query)
+	err := d.db.SelectContext(ctx, dest, query, args...)
+	EndSpan(span, ignoreNoRows(err))
+
+	return err
+}
+
+func (d *Database) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	ctx, span := d.startSpan(ctx, "Queryx", query)
+	rows, err := d.db.QueryxContext(ctx, query, args...)
+	EndSpan(span, err)
+
+	return rows, err
+}
+
+func (d *Database) startSpan(ctx context.Context, operation, query string) (context.Context, trace.Span) {
+	return Tracer().Start(
+		ctx,
+		"db."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMySQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+// ignoreNoRows avoids marking spans as failed when a query simply found nothing
+func ignoreNoRows(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,237 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// setupRecorder installs a tracer provider keeping the ended spans in memory
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	return recorder
+}
+
+// newHealthClient returns a gRPC client, instrumented like the blockchain clients, of an in-memory health server
+func newHealthClient(t *testing.T) grpc_health_v1.HealthClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		GrpcDialOption(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func spanByKind(t *testing.T, spans []sdktrace.ReadOnlySpan, kind trace.SpanKind) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	var found sdktrace.ReadOnlySpan
+	for _, s := range spans {
+		if s.SpanKind() != kind {
+			continue
+		}
+		if found != nil {
+			t.Fatalf("expected a single %s span, got %s and %s", kind, found.Name(), s.Name())
+		}
+		found = s
+	}
+
+	if found == nil {
+		t.Fatalf("no %s span recorded", kind)
+	}
+
+	return found
+}
+
+func statusCode(span sdktrace.ReadOnlySpan) int64 {
+	for _, attr := range span.Attributes() {
+		if attr.Key == semconv.HTTPResponseStatusCodeKey {
+			return attr.Value.AsInt64()
+		}
+	}
+
+	return 0
+}
+
+func TestSpansAreChildrenOfTheRequestSpan(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	var downstreamParent string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstreamParent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer downstream.Close()
+
+	httpClient := NewHTTPClient(time.Second)
+	grpcClient := newHealthClient(t)
+
+	e := echo.New()
+	e.Use(EchoMiddleware())
+	e.GET("/api/test/:id", func(ctx echo.Context) error {
+		c := ctx.Request().Context()
+		req, _ := http.NewRequestWithContext(c, http.MethodGet, downstream.URL, nil)
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+
+		if _, err = grpcClient.Check(c, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+			return err
+		}
+
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	//the request continues the trace of the caller
+	const callerTraceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/test/1", nil)
+	req.Header.Set("traceparent", "00-"+callerTraceId+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	server := spanByKind(t, spans, trace.SpanKindServer)
+	if server.Name() != "GET /api/test/:id" {
+		t.Errorf("unexpected server span name %s", server.Name())
+	}
+	if server.SpanContext().TraceID().String() != callerTraceId || server.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("the server span does not continue the caller trace: %s", server.SpanContext().TraceID())
+	}
+	if statusCode(server) != http.StatusOK {
+		t.Errorf("expected the status code 200 on the server span, got %d", statusCode(server))
+	}
+
+	var clients []sdktrace.ReadOnlySpan
+	for _, s := range spans {
+		if s.SpanKind() == trace.SpanKindClient {
+			clients = append(clients, s)
+		}
+	}
+	if len(clients) != 2 {
+		t.Fatalf("expected the http and grpc client spans, got %d", len(clients))
+	}
+
+	for _, client := range clients {
+		if client.Parent().SpanID() != server.SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of the server span", client.Name())
+		}
+		if client.SpanContext().TraceID() != server.SpanContext().TraceID() {
+			t.Errorf("span %s is not part of the request trace", client.Name())
+		}
+	}
+
+	grpcSpan := clients[0]
+	httpSpan := clients[1]
+	if grpcSpan.Name() != "grpc.health.v1.Health/Check" {
+		grpcSpan, httpSpan = httpSpan, grpcSpan
+	}
+	if grpcSpan.Name() != "grpc.health.v1.Health/Check" {
+		t.Errorf("no grpc client span, got %s and %s", grpcSpan.Name(), httpSpan.Name())
+	}
+
+	//the downstream service continues the trace from the http client span
+	expectedParent := "00-" + callerTraceId + "-" + httpSpan.SpanContext().SpanID().String() + "-01"
+	if downstreamParent != expectedParent {
+		t.Errorf("expected the traceparent %s downstream, got %s", expectedParent, downstreamParent)
+	}
+}
+
+func TestEchoMiddlewareReturnsTheHandlerError(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	notFound := echo.NewHTTPError(http.StatusNotFound, "market not found")
+	failure := errors.New("database is down")
+	cases := []struct {
+		err    error
+		status int
+		failed bool
+	}{
+		{err: notFound, status: http.StatusNotFound},
+		{err: failure, status: http.StatusInternalServerError, failed: true},
+	}
+
+	for _, tc := range cases {
+		recorder.Reset()
+
+		handler := EchoMiddleware()(func(ctx echo.Context) error { return tc.err })
+		e := echo.New()
+		ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/test", nil), httptest.NewRecorder())
+
+		if err := handler(ctx); err != tc.err {
+			t.Fatalf("expected the handler error %v, got %v", tc.err, err)
+		}
+		if ctx.Response().Committed {
+			t.Error("the middleware must leave the error to the error handler")
+		}
+
+		spans := recorder.Ended()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+
+		span := spans[0]
+		if statusCode(span) != int64(tc.status) {
+			t.Errorf("expected the status code %d on the span, got %d", tc.status, statusCode(span))
+		}
+		if len(span.Events()) != 1 || span.Events()[0].Name != "exception" {
+			t.Errorf("expected the error recorded on the span, got %v", span.Events())
+		}
+		if failed := span.Status().Code == codes.Error; failed != tc.failed {
+			t.Errorf("expected the span failed %v, got status %v", tc.failed, span.Status())
+		}
+	}
+}
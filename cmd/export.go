@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/export"
+	"github.com/bze-alphateam/bze-aggregator-api/cmd/factory"
+	"github.com/bze-alphateam/bze-aggregator-api/cmd/handlers"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagFrom   = "from"
+	flagTo     = "to"
+	flagFormat = "format"
+	flagOutput = "output"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the DEX data of a market",
+	Long: `List of export commands:
+Usage:
+./bze-agg export trades --market-id "ubze/uvdl"
+./bze-agg export candles --market-id "ubze/uvdl" --minutes 60
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Usage()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.PersistentFlags().String(flagMarketId, "", "the market id to export, e.g. ubze/uvdl")
+	exportCmd.PersistentFlags().String(flagFrom, "", "export the rows from this time on: 2006-01-02 or RFC 3339. Default: the first row")
+	exportCmd.PersistentFlags().String(flagTo, "", "export the rows before this time: 2006-01-02 or RFC 3339. Default: now")
+	exportCmd.PersistentFlags().String(flagFormat, export.FormatCSV, "export format: csv, ndjson or parquet")
+	exportCmd.PersistentFlags().String(flagOutput, "", "file written by the export. Default: stdout")
+}
+
+// exportArgs are the flags shared by the export commands
+type exportArgs struct {
+	marketId string
+	from     time.Time
+	to       time.Time
+	format   string
+	output   string
+}
+
+func readExportArgs(cmd *cobra.Command) (*exportArgs, error) {
+	a := &exportArgs{}
+	a.marketId, _ = cmd.Flags().GetString(flagMarketId)
+	if a.marketId == "" {
+		return nil, fmt.Errorf("--%s is required", flagMarketId)
+	}
+
+	a.format, _ = cmd.Flags().GetString(flagFormat)
+	a.output, _ = cmd.Flags().GetString(flagOutput)
+
+	var err error
+	from, _ := cmd.Flags().GetString(flagFrom)
+	if a.from, err = parseExportTime(from); err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", flagFrom, err)
+	}
+
+	to, _ := cmd.Flags().GetString(flagTo)
+	if a.to, err = parseExportTime(to); err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", flagTo, err)
+	}
+
+	if !a.from.IsZero() && !a.to.IsZero() && !a.from.Before(a.to) {
+		return nil, fmt.Errorf("--%s must be before --%s", flagFrom, flagTo)
+	}
+
+	return a, nil
+}
+
+func parseExportTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// runExport sets up the export handler and calls write with the output of the export
+func runExport(cmd *cobra.Command, name string, write func(ctx context.Context, h *handlers.Export, a *exportArgs, out io.Writer) error) error {
+	a, err := readExportArgs(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	logger, err := internal.NewLogger(cfg)
+	if err != nil {
+		return err
+	}
+	logger = logger.WithField("command", name)
+
+	ctx, cleanup := newCommandContext(logger)
+	defer cleanup()
+
+	handler, err := factory.GetExportHandler(cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	out, closeOut, err := openExportOutput(a.output, logger)
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+
+	buf := bufio.NewWriter(out)
+	if err = write(ctx, handler, a, buf); err != nil {
+		return err
+	}
+
+	return buf.Flush()
+}
+
+func openExportOutput(path string, logger logrus.FieldLogger) (io.Writer, func(), error) {
+	if path == "" {
+		return os.Stdout, func() {}, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, func() {
+		if err := f.Close(); err != nil {
+			logger.WithError(err).Error("could not close the export file")
+		}
+	}, nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/interval"
+	"github.com/bze-alphateam/bze-aggregator-api/cmd/handlers"
+	"github.com/spf13/cobra"
+)
+
+const flagMinutes = "minutes"
+
+var exportCandlesCmd = &cobra.Command{
+	Use:   "candles",
+	Args:  cobra.ExactArgs(0),
+	Short: "Export the candles of a market",
+	Long: `Writes the intervals (candles) of a market, oldest first, as CSV, NDJSON or Parquet
+Usage:
+./bze-agg export candles --market-id "ubze/uvdl" --minutes 1440 --output daily.csv
+./bze-agg export candles --market-id "ubze/uvdl" --minutes 5 --from 2025-01-01 --format ndjson
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		minutes, _ := cmd.Flags().GetInt(flagMinutes)
+		if !slices.Contains(interval.GetLengths(), interval.Length(minutes)) {
+			return fmt.Errorf("invalid --%s: expected one of %v", flagMinutes, interval.GetLengths())
+		}
+
+		return runExport(cmd, "export_candles", func(ctx context.Context, h *handlers.Export, a *exportArgs, out io.Writer) error {
+			return h.Candles(ctx, a.marketId, minutes, a.from, a.to, a.format, out)
+		})
+	},
+}
+
+func init() {
+	exportCmd.AddCommand(exportCandlesCmd)
+	exportCandlesCmd.Flags().Int(flagMinutes, 60, "the length of the candles: 5, 15, 60, 240 or 1440")
+}
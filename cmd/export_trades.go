@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"context"
+	"io"
+
+	"github.com/bze-alphateam/bze-aggregator-api/cmd/handlers"
+	"github.com/spf13/cobra"
+)
+
+var exportTradesCmd = &cobra.Command{
+	Use:   "trades",
+	Args:  cobra.ExactArgs(0),
+	Short: "Export the trades of a market",
+	Long: `Writes the trades of a market, oldest first, as CSV, NDJSON or Parquet
+Usage:
+./bze-agg export trades --market-id "ubze/uvdl" --output trades.csv
+./bze-agg export trades --market-id "ubze/uvdl" --from 2024-01-01 --to 2025-01-01 --format ndjson
+./bze-agg export trades --market-id "ubze/uvdl" --format parquet --output trades.parquet
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExport(cmd, "export_trades", func(ctx context.Context, h *handlers.Export, a *exportArgs, out io.Writer) error {
+			return h.Trades(ctx, a.marketId, a.from, a.to, a.format, out)
+		})
+	},
+}
+
+func init() {
+	exportCmd.AddCommand(exportTradesCmd)
+}
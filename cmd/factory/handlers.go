@@ -1,21 +1,29 @@
 package factory
 
 import (
+	"context"
+	"time"
+
 	"github.com/bze-alphateam/bze-aggregator-api/app/repository"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/alerting"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/client"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/data_provider"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/dex"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/health"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/lock"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/retention"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/sync"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
 	"github.com/bze-alphateam/bze-aggregator-api/cmd/handlers"
 	"github.com/bze-alphateam/bze-aggregator-api/connector"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze-aggregator-api/server/config"
 	"github.com/sirupsen/logrus"
 )
 
 func GetMarketsSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.MarketsSync, error) {
-	locker := lock.GetInMemoryLocker()
-	db, err := connector.NewDatabaseConnection()
+	db, err := getDatabase(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -25,7 +33,7 @@ func GetMarketsSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*h
 		return nil, err
 	}
 
-	grpc, err := client.NewGrpcClient(cfg, locker, logger)
+	grpc, err := connector.GetGrpcClient(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -54,8 +62,7 @@ func GetMarketsSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*h
 }
 
 func GetMarketOrderSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.MarketOrderSync, error) {
-	locker := lock.GetInMemoryLocker()
-	db, err := connector.NewDatabaseConnection()
+	db, err := getDatabase(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +72,7 @@ func GetMarketOrderSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger)
 		return nil, err
 	}
 
-	grpc, err := client.NewGrpcClient(cfg, locker, logger)
+	grpc, err := connector.GetGrpcClient(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -75,17 +82,17 @@ func GetMarketOrderSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger)
 		return nil, err
 	}
 
-	regClient, err := client.NewChainRegistry()
+	chainReg, err := connector.GetChainRegistry(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	chainReg, err := data_provider.NewChainRegistry(logger, service.NewInMemoryCache(), regClient)
+	tickers, err := getTickerSync(db, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	orderSync, err := sync.NewOrderSync(logger, data, repo, chainReg, lock.GetInMemoryLocker())
+	orderSync, err := sync.NewOrderSync(logger, data, repo, chainReg, tickers, lock.GetInMemoryLocker())
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +102,12 @@ func GetMarketOrderSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger)
 		return nil, err
 	}
 
-	handler, err := handlers.NewMarketOrderSyncHandler(logger, marketProvider, orderSync)
+	versions, err := repository.NewMarketSyncVersionRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	handler, err := handlers.NewMarketOrderSyncHandler(logger, marketProvider, orderSync, versions)
 	if err != nil {
 		return nil, err
 	}
@@ -104,8 +116,7 @@ func GetMarketOrderSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger)
 }
 
 func GetMarketHistorySyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.MarketHistorySync, error) {
-	locker := lock.GetInMemoryLocker()
-	db, err := connector.NewDatabaseConnection()
+	db, err := getDatabase(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +126,7 @@ func GetMarketHistorySyncHandler(cfg *config.AppConfig, logger logrus.FieldLogge
 		return nil, err
 	}
 
-	grpc, err := client.NewGrpcClient(cfg, locker, logger)
+	grpc, err := connector.GetGrpcClient(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -125,27 +136,27 @@ func GetMarketHistorySyncHandler(cfg *config.AppConfig, logger logrus.FieldLogge
 		return nil, err
 	}
 
-	regClient, err := client.NewChainRegistry()
+	chainReg, err := connector.GetChainRegistry(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	chainReg, err := data_provider.NewChainRegistry(logger, service.NewInMemoryCache(), regClient)
+	history, err := sync.NewHistorySync(logger, data, repo, chainReg, lock.GetInMemoryLocker())
 	if err != nil {
 		return nil, err
 	}
 
-	history, err := sync.NewHistorySync(logger, data, repo, chainReg, lock.GetInMemoryLocker())
+	marketProvider, err := data_provider.NewMarketProvider(grpc, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	marketProvider, err := data_provider.NewMarketProvider(grpc, logger)
+	versions, err := repository.NewMarketSyncVersionRepository(db)
 	if err != nil {
 		return nil, err
 	}
 
-	handler, err := handlers.NewMarketHistorySync(logger, marketProvider, history)
+	handler, err := handlers.NewMarketHistorySync(logger, marketProvider, history, versions)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +166,7 @@ func GetMarketHistorySyncHandler(cfg *config.AppConfig, logger logrus.FieldLogge
 
 func GetMarketIntervalSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.MarketIntervalSync, error) {
 	locker := lock.GetInMemoryLocker()
-	db, err := connector.NewDatabaseConnection()
+	db, err := getDatabase(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +176,7 @@ func GetMarketIntervalSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogg
 		return nil, err
 	}
 
-	grpc, err := client.NewGrpcClient(cfg, locker, logger)
+	grpc, err := connector.GetGrpcClient(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +186,12 @@ func GetMarketIntervalSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogg
 		return nil, err
 	}
 
-	history, err := sync.NewIntervalSync(logger, repo, locker, iRepo)
+	tickers, err := getTickerSync(db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := sync.NewIntervalSync(logger, repo, locker, iRepo, tickers)
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +201,12 @@ func GetMarketIntervalSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogg
 		return nil, err
 	}
 
-	handler, err := handlers.NewMarketIntervalSync(logger, marketProvider, history)
+	versions, err := repository.NewMarketSyncVersionRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	handler, err := handlers.NewMarketIntervalSync(logger, marketProvider, history, versions)
 	if err != nil {
 		return nil, err
 	}
@@ -195,7 +216,7 @@ func GetMarketIntervalSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogg
 
 func GetSyncListener(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.Listener, error) {
 	locker := lock.GetInMemoryLocker()
-	db, err := connector.NewDatabaseConnection()
+	db, err := getDatabase(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +226,7 @@ func GetSyncListener(cfg *config.AppConfig, logger logrus.FieldLogger) (*handler
 		return nil, err
 	}
 
-	grpc, err := client.NewGrpcClient(cfg, locker, logger)
+	grpc, err := connector.GetGrpcClient(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -235,22 +256,22 @@ func GetSyncListener(cfg *config.AppConfig, logger logrus.FieldLogger) (*handler
 		return nil, err
 	}
 
-	interval, err := sync.NewIntervalSync(logger, hRepo, locker, iRepo)
+	tickers, err := getTickerSync(db, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	hData, err := data_provider.NewHistoryDataProvider(logger, grpc)
+	interval, err := sync.NewIntervalSync(logger, hRepo, locker, iRepo, tickers)
 	if err != nil {
 		return nil, err
 	}
 
-	regClient, err := client.NewChainRegistry()
+	hData, err := data_provider.NewHistoryDataProvider(logger, grpc)
 	if err != nil {
 		return nil, err
 	}
 
-	chainReg, err := data_provider.NewChainRegistry(logger, service.NewInMemoryCache(), regClient)
+	chainReg, err := connector.GetChainRegistry(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -270,10 +291,496 @@ func GetSyncListener(cfg *config.AppConfig, logger logrus.FieldLogger) (*handler
 		return nil, err
 	}
 
-	order, err := sync.NewOrderSync(logger, oData, oRepo, chainReg, locker)
+	order, err := sync.NewOrderSync(logger, oData, oRepo, chainReg, tickers, locker)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]handlers.NodeStatusClient, len(cfg.Blockchain.HealthNodes))
+	for name, host := range cfg.Blockchain.HealthNodes {
+		rpc, err := connector.GetRpcClient(host, cfg.Timeouts.RpcSeconds)
+		if err != nil {
+			return nil, err
+		}
+
+		node, err := data_provider.NewBlockchainProvider(rpc)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes[name] = node
+	}
+
+	wsNodes, err := connector.GetWsPool(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := repository.NewMarketSyncVersionRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	pruner, err := getPruner(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	supplyHistory, err := getSupplyHistory(cfg, db, logger, chainReg)
+	if err != nil {
+		return nil, err
+	}
+
+	pricesHistory, err := getPricesHistory(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := getArticles(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var cointrunk handlers.CointrunkIndexer
+	if cfg.Articles.OnchainIndex {
+		cointrunk, err = getCointrunkSync(db, grpc, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	evaluator, err := getHealthEvaluator(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []handlers.Job{
+		{
+			Name:     "prune",
+			Interval: time.Duration(cfg.Retention.PruneIntervalHours) * time.Hour,
+			Run:      pruner.Prune,
+		},
+		{
+			Name:     "supply_snapshot",
+			Interval: time.Duration(cfg.Supply.SnapshotMinutes) * time.Minute,
+			Run: func(ctx context.Context) error {
+				return supplyHistory.TakeSnapshots(ctx, cfg.Supply.Denoms())
+			},
+		},
+		{
+			Name:     "price_snapshot",
+			Interval: time.Duration(cfg.Prices.SnapshotMinutes) * time.Minute,
+			Run:      pricesHistory.TakeSnapshots,
+		},
+		{
+			Name:     "articles",
+			Interval: time.Duration(cfg.Articles.RefreshMinutes) * time.Minute,
+			Run:      articles.Refresh,
+		},
+		{
+			Name:     "health_alerts",
+			Interval: time.Duration(cfg.Alerting.IntervalSeconds) * time.Second,
+			Run:      evaluator.Evaluate,
+		},
+	}
+
+	return handlers.NewListener(logger, wsNodes, history, interval, order, market, mProvider, locker, versions, tickers, nodes, jobs, cointrunk)
+}
+
+func GetSupplySyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.SupplySync, error) {
+	db, err := getDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	chainReg, err := connector.GetChainRegistry(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	supplyHistory, err := getSupplyHistory(cfg, db, logger, chainReg)
+	if err != nil {
+		return nil, err
+	}
+
+	return handlers.NewSupplySyncHandler(logger, supplyHistory, cfg.Supply.Denoms())
+}
+
+// getSupplyHistory returns the service saving the supply snapshots
+func getSupplyHistory(cfg *config.AppConfig, db internal.Database, logger logrus.FieldLogger, chainReg *data_provider.ChainRegistry) (*service.SupplyHistory, error) {
+	nodes, err := connector.GetRestPool(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	rest, err := client.NewBlockchainQueryClient(nodes, config.Seconds(cfg.Timeouts.HttpSeconds))
+	if err != nil {
+		return nil, err
+	}
+
+	supply, err := service.NewSupplyService(logger, service.NewInMemoryCache(), rest, chainReg, config.Seconds(cfg.Cache.SupplySeconds), cfg.Supply.ExclusionsByDenom())
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := repository.NewSupplySnapshotRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NewSupplyHistoryService(logger, snapshots, supply, chainReg)
+}
+
+// getHealthEvaluator returns the evaluator running the configured health checks and notifying the configured sinks
+func getHealthEvaluator(cfg *config.AppConfig, db internal.Database, logger logrus.FieldLogger) (*alerting.Evaluator, error) {
+	nodes, err := connector.GetRestPool(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	rest, err := client.NewBlockchainQueryClient(nodes, config.Seconds(cfg.Timeouts.HttpSeconds))
+	if err != nil {
+		return nil, err
+	}
+
+	hRepo, err := repository.NewMarketHistoryRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	healthNodes := make(map[string]service.NodeInfoClient, len(cfg.Blockchain.HealthNodes))
+	for name, host := range cfg.Blockchain.HealthNodes {
+		rpc, err := connector.GetRpcClient(host, cfg.Timeouts.RpcSeconds)
+		if err != nil {
+			return nil, err
+		}
+
+		node, err := data_provider.NewBlockchainProvider(rpc)
+		if err != nil {
+			return nil, err
+		}
+
+		healthNodes[name] = node
+	}
+
+	//the evaluator runs less often than the cache expires, the results are always fresh
+	healthService, err := service.NewHealthService(logger, service.NewInMemoryCache(), rest, hRepo, healthNodes, time.Second, int64(cfg.Health.NodesMaxHeightDiff), cfg.Health.NodesMaxLatency())
+	if err != nil {
+		return nil, err
+	}
+
+	cache := service.NewInMemoryCache()
+	chainReg, err := connector.GetChainRegistry(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := health.NewBalancesHealth(logger, cache, chainReg, cfg.PrefixedEndpoints, config.Seconds(cfg.Timeouts.HttpSeconds), config.Seconds(cfg.Cache.BalancesSeconds))
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []alerting.Check
+	for _, market := range cfg.Alerting.MarketChecks() {
+		checks = append(checks, alerting.NewMarketCheck(healthService, market.MarketId, market.Minutes))
+	}
+
+	if cfg.Alerting.AggregatorMinutes > 0 {
+		checks = append(checks, alerting.NewAggregatorCheck(healthService, cfg.Alerting.AggregatorMinutes))
+	}
+
+	if len(healthNodes) > 0 {
+		checks = append(checks, alerting.NewNodesCheck(healthService))
+	}
+
+	for _, balance := range cfg.Alerting.BalanceChecks() {
+		checks = append(checks, alerting.NewBalanceCheck(balances, balance.Address, balance.Denom, balance.MinAmount))
+	}
+
+	sinks, err := getAlertSinks(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := repository.NewHealthEventRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return alerting.NewEvaluator(logger, events, checks, sinks, cfg.Alerting.FailureThreshold, time.Duration(cfg.Alerting.CooldownMinutes)*time.Minute)
+}
+
+// getAlertSinks returns the sinks having a url configured
+func getAlertSinks(cfg *config.AppConfig) ([]alerting.Sink, error) {
+	httpClient := tracing.NewHTTPClient(config.Seconds(cfg.Timeouts.HttpSeconds))
+	var sinks []alerting.Sink
+	if cfg.Alerting.WebhookUrl != "" {
+		sink, err := alerting.NewWebhookSink(cfg.Alerting.WebhookUrl, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if cfg.Alerting.SlackWebhookUrl != "" {
+		sink, err := alerting.NewSlackSink(cfg.Alerting.SlackWebhookUrl, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if cfg.Alerting.TelegramBotToken != "" {
+		sink, err := alerting.NewTelegramSink(cfg.Alerting.TelegramApiUrl, cfg.Alerting.TelegramBotToken, cfg.Alerting.TelegramChatId, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return sinks, nil
+}
+
+func GetCointrunkSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.CointrunkSync, error) {
+	db, err := getDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	grpc, err := connector.GetGrpcClient(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	cointrunk, err := getCointrunkSync(db, grpc, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return handlers.NewCointrunkSyncHandler(logger, cointrunk)
+}
+
+func getCointrunkSync(db internal.Database, grpc *client.GrpcClient, logger logrus.FieldLogger) (*sync.Cointrunk, error) {
+	provider, err := data_provider.NewCointrunkProvider(logger, grpc)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewCointrunkRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return sync.NewCointrunkSync(logger, repo, provider)
+}
+
+func GetArticlesSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.ArticlesSync, error) {
+	db, err := getDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := getArticles(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return handlers.NewArticlesSyncHandler(logger, articles)
+}
+
+// getArticles returns the service saving the articles of the feeds
+func getArticles(cfg *config.AppConfig, db internal.Database, logger logrus.FieldLogger) (*service.Articles, error) {
+	storage, err := repository.NewArticleRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NewArticlesService(logger, service.NewInMemoryCache(), storage, cfg.Articles.FeedList(), config.Seconds(cfg.Cache.ArticlesSeconds), config.Seconds(cfg.Timeouts.HttpSeconds))
+}
+
+func GetPricesSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.PricesSync, error) {
+	db, err := getDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pricesHistory, err := getPricesHistory(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return handlers.NewPricesSyncHandler(logger, pricesHistory)
+}
+
+// getPricesHistory returns the service saving the price snapshots
+func getPricesHistory(cfg *config.AppConfig, db internal.Database, logger logrus.FieldLogger) (*service.PricesHistory, error) {
+	sources, err := getPriceSources(cfg, db)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := service.NewPricesService(service.NewInMemoryCache(), sources, logger, cfg.Prices, config.Seconds(cfg.Cache.PricesSeconds), config.Seconds(cfg.Cache.PricesBackupSeconds))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := repository.NewPriceSnapshotRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NewPricesHistoryService(logger, snapshots, prices)
+}
+
+// getPriceSources returns the configured price sources, in the order they are asked
+func getPriceSources(cfg *config.AppConfig, db internal.Database) ([]service.PriceSource, error) {
+	timeout := config.Seconds(cfg.Timeouts.HttpSeconds)
+	var sources []service.PriceSource
+	for _, name := range cfg.Prices.SourceNames() {
+		switch name {
+		case config.PriceSourceCoingecko:
+			source, err := client.NewCoingeckoClient(cfg.Coingecko.Host, timeout)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		case config.PriceSourceOsmosis:
+			source, err := client.NewOsmosisClient(cfg.Prices.OsmosisHost, cfg.Prices.Entries(cfg.Prices.OsmosisPools), timeout)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		case config.PriceSourceDex:
+			tickers, err := repository.NewMarketTickerRepository(db)
+			if err != nil {
+				return nil, err
+			}
+			source, err := data_provider.NewDexPrice(tickers, cfg.Prices.Entries(cfg.Prices.DexMarkets))
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		case config.PriceSourceStatic:
+			sources = append(sources, data_provider.NewStaticPrice(cfg.Prices.Entries(cfg.Prices.Static)))
+		}
+	}
+
+	return sources, nil
+}
+
+func GetPruneHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.Prune, error) {
+	db, err := getDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pruner, err := getPruner(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return handlers.NewPruneHandler(logger, pruner)
+}
+
+// getPruner returns the service deleting the data older than the configured retention
+func getPruner(cfg *config.AppConfig, db internal.Database, logger logrus.FieldLogger) (*retention.Pruner, error) {
+	mRepo, err := repository.NewMarketRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	iRepo, err := repository.NewMarketIntervalRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	hRepo, err := repository.NewMarketHistoryRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return retention.NewPruner(logger, retention.NewPolicy(cfg.Retention), mRepo, iRepo, hRepo)
+}
+
+func GetTickersSyncHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.TickersSync, error) {
+	db, err := getDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers, err := getTickerSync(db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return handlers.NewTickersSyncHandler(logger, tickers)
+}
+
+// getTickerSync returns the service maintaining the precomputed tickers
+func getTickerSync(db internal.Database, logger logrus.FieldLogger) (*sync.TickerSync, error) {
+	tRepo, err := repository.NewMarketTickerRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	iRepo, err := repository.NewMarketIntervalRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	hRepo, err := repository.NewMarketHistoryRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	oRepo, err := repository.NewMarketOrderRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	mRepo, err := repository.NewMarketRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return sync.NewTickerSync(logger, tRepo, iRepo, hRepo, oRepo, mRepo)
+}
+
+func GetExportHandler(cfg *config.AppConfig, logger logrus.FieldLogger) (*handlers.Export, error) {
+	db, err := getDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mRepo, err := repository.NewMarketRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	iRepo, err := repository.NewMarketIntervalRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	hRepo, err := repository.NewMarketHistoryRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := dex.NewExportService(logger, hRepo, iRepo, mRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	return handlers.NewExportHandler(logger, service)
+}
+
+// getDatabase returns the shared database pool wrapped with the query timeout and tracing instrumentation
+func getDatabase(cfg *config.AppConfig) (internal.Database, error) {
+	db, err := connector.GetDatabase(cfg.Database)
 	if err != nil {
 		return nil, err
 	}
 
-	return handlers.NewListener(logger, history, interval, order, market, mProvider, locker)
+	return tracing.NewDatabase(connector.NewTimeoutDatabase(db, config.Seconds(cfg.Timeouts.DatabaseSeconds))), nil
 }
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type articlesFeeds interface {
+	Refresh(ctx context.Context) error
+}
+
+type ArticlesSync struct {
+	feeds  articlesFeeds
+	logger logrus.FieldLogger
+}
+
+func NewArticlesSyncHandler(logger logrus.FieldLogger, feeds articlesFeeds) (*ArticlesSync, error) {
+	if logger == nil || feeds == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewArticlesSyncHandler")
+	}
+
+	return &ArticlesSync{logger: logger, feeds: feeds}, nil
+}
+
+// SyncAll reads the articles feeds and saves their articles
+func (a *ArticlesSync) SyncAll(ctx context.Context) error {
+	err := a.feeds.Refresh(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.logger.Info("articles sync finished")
+
+	return nil
+}
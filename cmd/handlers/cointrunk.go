@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type cointrunkStorage interface {
+	SyncAll(ctx context.Context) error
+}
+
+type CointrunkSync struct {
+	storage cointrunkStorage
+	logger  logrus.FieldLogger
+}
+
+func NewCointrunkSyncHandler(logger logrus.FieldLogger, storage cointrunkStorage) (*CointrunkSync, error) {
+	if logger == nil || storage == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewCointrunkSyncHandler")
+	}
+
+	return &CointrunkSync{logger: logger, storage: storage}, nil
+}
+
+// SyncAll indexes the articles published on chain and their publishers
+func (s *CointrunkSync) SyncAll(ctx context.Context) error {
+	err := s.storage.SyncAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("cointrunk sync finished")
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/export"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type exporter interface {
+	ExportTrades(ctx context.Context, marketId string, from, to time.Time, w export.Writer) error
+	ExportCandles(ctx context.Context, marketId string, length int, from, to time.Time, w export.Writer) error
+}
+
+type Export struct {
+	service exporter
+	logger  logrus.FieldLogger
+}
+
+func NewExportHandler(logger logrus.FieldLogger, service exporter) (*Export, error) {
+	if logger == nil || service == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewExportHandler")
+	}
+
+	return &Export{logger: logger, service: service}, nil
+}
+
+// Trades writes the trades of the market executed in [from, to) to out, in the given format
+func (e *Export) Trades(ctx context.Context, marketId string, from, to time.Time, format string, out io.Writer) error {
+	return e.write(format, out, func(w export.Writer) error {
+		return e.service.ExportTrades(ctx, marketId, from, to, w)
+	})
+}
+
+// Candles writes the intervals of the market started in [from, to) to out, in the given format
+func (e *Export) Candles(ctx context.Context, marketId string, minutes int, from, to time.Time, format string, out io.Writer) error {
+	return e.write(format, out, func(w export.Writer) error {
+		return e.service.ExportCandles(ctx, marketId, minutes, from, to, w)
+	})
+}
+
+func (e *Export) write(format string, out io.Writer, fn func(w export.Writer) error) error {
+	w, err := export.NewWriter(format, out)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(w); err != nil {
+		return err
+	}
+
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	e.logger.Info("export finished")
+
+	return nil
+}
@@ -1,41 +1,52 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/sirupsen/logrus"
 )
 
 type historyStorage interface {
-	SyncHistory(market *types.Market, batchSize uint64) error
+	SyncHistory(ctx context.Context, market *types.Market, batchSize uint64) error
 }
 
 type MarketHistorySync struct {
 	mProvider marketProvider
 	storage   historyStorage
+	versions  marketVersions
 	logger    logrus.FieldLogger
 }
 
-func NewMarketHistorySync(logger logrus.FieldLogger, provider marketProvider, storage historyStorage) (*MarketHistorySync, error) {
-	if logger == nil || provider == nil || storage == nil {
+func NewMarketHistorySync(logger logrus.FieldLogger, provider marketProvider, storage historyStorage, versions marketVersions) (*MarketHistorySync, error) {
+	if logger == nil || provider == nil || storage == nil || versions == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewMarketHistorySync")
 	}
 
 	return &MarketHistorySync{
 		mProvider: provider,
 		storage:   storage,
+		versions:  versions,
 		logger:    logger,
 	}, nil
 }
 
-func (m *MarketHistorySync) SyncHistory(marketId string) error {
-	return syncMarket(marketId, m.mProvider, m.logger, m.syncMarket)
+func (m *MarketHistorySync) SyncHistory(ctx context.Context, marketId string) error {
+	return syncMarket(ctx, marketId, m.mProvider, m.logger, m.syncMarket)
 }
 
-func (m *MarketHistorySync) SyncAll() {
-	syncAll(m.mProvider, m.logger, m.syncMarket)
+func (m *MarketHistorySync) SyncAll(ctx context.Context) {
+	syncAll(ctx, m.mProvider, m.logger, m.syncMarket)
 }
 
-func (m *MarketHistorySync) syncMarket(market *types.Market) error {
-	return m.storage.SyncHistory(market, 0)
+func (m *MarketHistorySync) syncMarket(ctx context.Context, market *types.Market) error {
+	err := m.storage.SyncHistory(ctx, market, 0)
+	if err != nil {
+		return err
+	}
+
+	bumpVersion(ctx, m.versions, m.logger, market)
+
+	return nil
 }
@@ -1,41 +1,52 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/sirupsen/logrus"
 )
 
 type intervalStorage interface {
-	SyncIntervals(market *types.Market) error
+	SyncIntervals(ctx context.Context, market *types.Market) error
 }
 
 type MarketIntervalSync struct {
 	mProvider marketProvider
 	storage   intervalStorage
+	versions  marketVersions
 	logger    logrus.FieldLogger
 }
 
-func NewMarketIntervalSync(logger logrus.FieldLogger, provider marketProvider, storage intervalStorage) (*MarketIntervalSync, error) {
-	if logger == nil || provider == nil || storage == nil {
+func NewMarketIntervalSync(logger logrus.FieldLogger, provider marketProvider, storage intervalStorage, versions marketVersions) (*MarketIntervalSync, error) {
+	if logger == nil || provider == nil || storage == nil || versions == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewMarketIntervalSync")
 	}
 
 	return &MarketIntervalSync{
 		mProvider: provider,
 		storage:   storage,
+		versions:  versions,
 		logger:    logger,
 	}, nil
 }
 
-func (m *MarketIntervalSync) SyncIntervals(marketId string) error {
-	return syncMarket(marketId, m.mProvider, m.logger, m.syncInterval)
+func (m *MarketIntervalSync) SyncIntervals(ctx context.Context, marketId string) error {
+	return syncMarket(ctx, marketId, m.mProvider, m.logger, m.syncInterval)
 }
 
-func (m *MarketIntervalSync) SyncAll() {
-	syncAll(m.mProvider, m.logger, m.syncInterval)
+func (m *MarketIntervalSync) SyncAll(ctx context.Context) {
+	syncAll(ctx, m.mProvider, m.logger, m.syncInterval)
 }
 
-func (m *MarketIntervalSync) syncInterval(market *types.Market) error {
-	return m.storage.SyncIntervals(market)
+func (m *MarketIntervalSync) syncInterval(ctx context.Context, market *types.Market) error {
+	err := m.storage.SyncIntervals(ctx, market)
+	if err != nil {
+		return err
+	}
+
+	bumpVersion(ctx, m.versions, m.logger, market)
+
+	return nil
 }
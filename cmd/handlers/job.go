@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"context"
+	"time"
+)
+
+// Job is a task the listener runs at a fixed interval next to the sync, e.g. pruning old data
+type Job struct {
+	Name string
+	//0 disables the job
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
@@ -1,20 +1,36 @@
 package handlers
 
 import (
+	"context"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/client"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/listener"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze/x/tradebin/types"
 	types2 "github.com/cometbft/cometbft/abci/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	historyBatchSize = 150
 	lockMarketsKey   = "sync:listener:lock:markets"
+	lockCointrunkKey = "sync:listener:lock:cointrunk"
+
+	nodesHeightInterval = time.Second * 30
+	//the tickers 24h window moves forward at the pace of the 5 minutes intervals
+	tickersWindowInterval = time.Minute * 5
+
+	wsMinBackoff = time.Second
+	wsMaxBackoff = time.Minute
 )
 
 type locker interface {
@@ -22,115 +38,215 @@ type locker interface {
 	Unlock(key string)
 }
 
+type wsNodes interface {
+	Hosts() []string
+	MarkFailed(host string, err error)
+}
+
+// CointrunkIndexer saves the cointrunk articles and publishers changed by the events
+type CointrunkIndexer interface {
+	SyncNewArticles(ctx context.Context) error
+	SyncPublishers(ctx context.Context, addresses ...string) error
+}
+
+type NodeStatusClient interface {
+	GetStatus(ctx context.Context) (*coretypes.ResultStatus, error)
+}
+
 type Listener struct {
 	logger    logrus.FieldLogger
+	wsNodes   wsNodes
 	h         historyStorage
 	i         intervalStorage
 	o         orderStorage
 	m         marketStorage
 	mProvider marketProvider
 	locker    locker
-
-	markets map[string]types.Market
+	versions  marketVersions
+	tickers   tickerWindow
+	nodes     map[string]NodeStatusClient
+	jobs      []Job
+	//nil when the articles published on chain are not indexed
+	cointrunk CointrunkIndexer
+
+	//markets is replaced, never modified, when the markets are reloaded
+	markets   map[string]types.Market
+	marketsMx sync.RWMutex
 }
 
-func NewListener(logger logrus.FieldLogger, h historyStorage, i intervalStorage, o orderStorage, m marketStorage, mProvider marketProvider, locker locker) (*Listener, error) {
-	if logger == nil || h == nil || i == nil || o == nil || m == nil || mProvider == nil || locker == nil {
+func NewListener(logger logrus.FieldLogger, wsNodes wsNodes, h historyStorage, i intervalStorage, o orderStorage, m marketStorage, mProvider marketProvider, locker locker, versions marketVersions, tickers tickerWindow, nodes map[string]NodeStatusClient, jobs []Job, cointrunk CointrunkIndexer) (*Listener, error) {
+	if logger == nil || wsNodes == nil || h == nil || i == nil || o == nil || m == nil || mProvider == nil || locker == nil || versions == nil || tickers == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewListener")
 	}
 
-	err := m.SyncMarkets()
+	ctx := context.Background()
+	err := m.SyncMarkets(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	markets, err := getMarketsMap(mProvider)
+	markets, err := getMarketsMap(ctx, mProvider)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Listener{
 		logger:    logger,
+		wsNodes:   wsNodes,
 		h:         h,
 		i:         i,
 		o:         o,
 		m:         m,
 		mProvider: mProvider,
 		locker:    locker,
+		versions:  versions,
+		tickers:   tickers,
+		nodes:     nodes,
+		jobs:      jobs,
+		cointrunk: cointrunk,
 		markets:   markets,
 	}, nil
 }
 
-func (l *Listener) ListenAndSync() error {
+// ListenAndSync syncs all markets, then listens for tradebin events until ctx is canceled.
+// On return, all the events received so far have been handled.
+func (l *Listener) ListenAndSync(ctx context.Context) error {
 	defer l.logger.Info("ListenAndSync stopped")
 
-	conn, err := client.GetWsClient()
-	if err != nil {
-		return err
-	}
-	l.logger.Debug("connection established")
-
-	blockchain, err := listener.NewTradebinListener(conn, l.logger)
+	err := l.initialSync(ctx)
 	if err != nil {
+		l.logger.WithError(err).Error("error during initial sync")
 		return err
 	}
-	l.logger.Debug("created blockchain listener")
 
-	err = l.initialSync()
-	if err != nil {
-		l.logger.WithError(err).Error("error during initial sync")
-		return err
+	go l.watchNodesHeight(ctx)
+	go l.rollTickersWindow(ctx)
+	for _, job := range l.jobs {
+		go l.runJob(ctx, job)
 	}
 
-	msgChan := make(chan types2.Event)
+	msgChan := make(chan listener.Event)
 	go func() {
-		err := blockchain.Listen(msgChan)
-		if err != nil {
-			l.logger.WithError(err).Error("error listening for messages")
-			close(msgChan)
-		}
+		defer close(msgChan)
+		l.listen(ctx, msgChan)
 	}()
 
+	// events already received are synced even if ctx gets canceled meanwhile
+	handleCtx := context.WithoutCancel(ctx)
+	var workers sync.WaitGroup
 	for msg := range msgChan {
-		go l.handleMessage(msg)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			l.handleMessage(handleCtx, msg)
+		}()
 	}
 
+	l.logger.Info("waiting for in-flight events to be synced")
+	workers.Wait()
+
 	return nil
 }
 
-func (l *Listener) handleMessage(event types2.Event) {
-	eventLogger := l.logger.WithField("event", event.Type)
-	m := l.getEventMarket(event)
+// listen subscribes to the best websocket node until ctx is canceled. When the subscription fails, the node is
+// marked as down and the listener moves to the next node, syncing all markets to catch up on the missed events.
+func (l *Listener) listen(ctx context.Context, msgChan chan<- listener.Event) {
+	backoff := wsMinBackoff
+	for {
+		host := l.wsNodes.Hosts()[0]
+		logger := l.logger.WithField("host", host)
+		start := time.Now()
+		err := l.listenOn(ctx, host, msgChan)
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.WithError(err).Error("error listening for messages")
+		l.wsNodes.MarkFailed(host, err)
+		if time.Since(start) > wsMaxBackoff {
+			backoff = wsMinBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, wsMaxBackoff)
+
+		if err := l.initialSync(ctx); err != nil {
+			logger.WithError(err).Error("error syncing markets after reconnecting")
+		}
+	}
+}
+
+func (l *Listener) listenOn(ctx context.Context, host string, msgChan chan<- listener.Event) error {
+	conn, err := client.NewWsClient(host)
+	if err != nil {
+		return err
+	}
+
+	blockchain, err := listener.NewTradebinListener(conn, l.logger.WithField("host", host))
+	if err != nil {
+		return err
+	}
+	l.logger.WithField("host", host).Info("listening for blockchain events")
+
+	return blockchain.Listen(ctx, msgChan)
+}
+
+func (l *Listener) handleMessage(ctx context.Context, event listener.Event) {
+	eventLogger := l.logger.WithFields(logrus.Fields{
+		internal.LogFieldEventType:   event.Type,
+		internal.LogFieldBlockHeight: event.Height,
+	})
+	metrics.ListenerEvent(event.Type)
+	m := l.getEventMarket(event.Event)
+
+	ctx, span := tracing.StartSpan(ctx, "listener.handleMessage", trace.WithAttributes(
+		attribute.String(internal.LogFieldEventType, event.Type),
+		attribute.Int64(internal.LogFieldBlockHeight, event.Height),
+	))
+	defer span.End()
+	if m != nil {
+		marketId := converter.GetMarketId(m.GetBase(), m.GetQuote())
+		eventLogger = eventLogger.WithField(internal.LogFieldMarketId, marketId)
+		span.SetAttributes(attribute.String(internal.LogFieldMarketId, marketId))
+	}
 
 	switch event.Type {
 	case "bze.tradebin.MarketCreatedEvent":
 		eventLogger.Info("syncing markets")
-		err := l.m.SyncMarkets()
+		err := l.m.SyncMarkets(ctx)
 		if err != nil {
 			eventLogger.WithError(err).Error("error syncing markets")
+			span.RecordError(err)
 		}
 
 		//when a new market is created we should refresh our markets list that we keep in memory
 		l.lockMarkets()
 		defer l.unlockMarkets()
-		l.markets, err = getMarketsMap(l.mProvider)
-		if err != nil {
+		if _, err = l.reloadMarkets(ctx); err != nil {
 			eventLogger.WithError(err).Error("error when trying to resync all markets")
+			span.RecordError(err)
 		}
+		m = l.getEventMarket(event.Event)
 	case "bze.tradebin.OrderExecutedEvent":
 		eventLogger.Info("syncing history")
 		if m == nil {
 			eventLogger.Error("could not find market for this event")
 			break
 		}
-		err := l.h.SyncHistory(m, historyBatchSize)
+		err := l.h.SyncHistory(ctx, m, historyBatchSize)
 		if err != nil {
 			eventLogger.WithError(err).Error("error syncing history")
+			span.RecordError(err)
 		}
 
-		err = l.i.SyncIntervals(m)
+		err = l.i.SyncIntervals(ctx, m)
 		if err != nil {
 			eventLogger.WithError(err).Error("error syncing intervals")
+			span.RecordError(err)
 		}
 
 		fallthrough
@@ -142,15 +258,97 @@ func (l *Listener) handleMessage(event types2.Event) {
 			eventLogger.Error("could not find market for this event")
 			break
 		}
-		err := l.o.SyncMarket(m)
+		err := l.o.SyncMarket(ctx, m)
 		if err != nil {
 			eventLogger.WithError(err).Error("error syncing orders")
+			span.RecordError(err)
+		}
+	case "bze.cointrunk.ArticleAddedEvent":
+		eventLogger.Info("indexing cointrunk articles")
+		if err := l.indexCointrunk(ctx, true, getEventAttribute(event.Event, "publisher")); err != nil {
+			eventLogger.WithError(err).Error("error indexing cointrunk articles")
+			span.RecordError(err)
 		}
+	case "bze.cointrunk.PublisherAddedEvent", "bze.cointrunk.PublisherUpdatedEvent", "bze.cointrunk.PublisherRespectPaidEvent":
+		eventLogger.Info("indexing cointrunk publisher")
+		if err := l.indexCointrunk(ctx, false, getEventAttribute(event.Event, "address")); err != nil {
+			eventLogger.WithError(err).Error("error indexing cointrunk publisher")
+			span.RecordError(err)
+		}
+	}
+
+	if m != nil {
+		bumpVersion(ctx, l.versions, eventLogger, m)
 	}
 
 	eventLogger.Debug("message handled")
 }
 
+// watchNodesHeight periodically saves the height of the health nodes, so the listener lag can be measured
+func (l *Listener) watchNodesHeight(ctx context.Context) {
+	if len(l.nodes) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(nodesHeightInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for name, node := range l.nodes {
+			status, err := node.GetStatus(ctx)
+			if err != nil {
+				l.logger.WithError(err).WithField("node", name).Warn("could not get node status")
+				continue
+			}
+
+			metrics.SetNodeHeight(name, status.SyncInfo.LatestBlockHeight)
+		}
+	}
+}
+
+// rollTickersWindow periodically rebuilds the tickers, so their 24h stats also change for markets without trades
+func (l *Listener) rollTickersWindow(ctx context.Context) {
+	ticker := time.NewTicker(tickersWindowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := l.tickers.RollWindow(ctx); err != nil {
+			l.logger.WithError(err).Error("could not roll the tickers window")
+		}
+	}
+}
+
+// runJob runs the job at its interval until ctx is canceled
+func (l *Listener) runJob(ctx context.Context, job Job) {
+	if job.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := job.Run(ctx); err != nil {
+			l.logger.WithError(err).WithField("job", job.Name).Error("scheduled job failed")
+		}
+	}
+}
+
 func (l *Listener) lockMarkets() {
 	l.locker.Lock(lockMarketsKey)
 }
@@ -160,65 +358,129 @@ func (l *Listener) unlockMarkets() {
 }
 
 func (l *Listener) getEventMarket(event types2.Event) *types.Market {
-	for _, attr := range event.Attributes {
-		if string(attr.Key) == "market_id" {
-			mId := strings.Trim(string(attr.Value), "\"")
-			m, ok := l.markets[mId]
-			if ok {
-				return &m
-			}
+	mId := getEventAttribute(event, "market_id")
+	if mId == "" {
+		return nil
+	}
+
+	l.marketsMx.RLock()
+	defer l.marketsMx.RUnlock()
+	m, ok := l.markets[mId]
+	if !ok {
+		return nil
+	}
 
-			return nil
+	return &m
+}
+
+// indexCointrunk saves the new articles, when requested, and the publisher. All the publishers are saved when the
+// event does not tell the publisher.
+func (l *Listener) indexCointrunk(ctx context.Context, articles bool, publisher string) error {
+	if l.cointrunk == nil {
+		return nil
+	}
+
+	l.locker.Lock(lockCointrunkKey)
+	defer l.locker.Unlock(lockCointrunkKey)
+
+	if articles {
+		if err := l.cointrunk.SyncNewArticles(ctx); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	if publisher == "" {
+		return l.cointrunk.SyncPublishers(ctx)
+	}
+
+	return l.cointrunk.SyncPublishers(ctx, publisher)
 }
 
-func (l *Listener) initialSync() (err error) {
+// getEventAttribute returns the value of the attribute, without the quotes of the typed events
+func getEventAttribute(event types2.Event, key string) string {
+	for _, attr := range event.Attributes {
+		if attr.Key == key {
+			return strings.Trim(attr.Value, "\"")
+		}
+	}
+
+	return ""
+}
+
+func (l *Listener) initialSync(ctx context.Context) (err error) {
 	logger := l.logger.WithField("process", "initialSync")
 	l.lockMarkets()
 	defer l.unlockMarkets()
 	logger.Info("syncing markets")
-	err = l.m.SyncMarkets()
+	//the markets already saved are still synced
+	if err = l.m.SyncMarkets(ctx); err != nil {
+		logger.WithError(err).Error("error syncing markets")
+	}
 
-	l.markets, err = getMarketsMap(l.mProvider)
+	markets, err := l.reloadMarkets(ctx)
 	if err != nil {
 		return err
 	}
 
-	for _, m := range l.markets {
-		logger = logger.WithField("market", converter.GetMarketId(m.GetBase(), m.GetQuote()))
+	for _, m := range markets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// the market being synced is finished even if ctx gets canceled meanwhile
+		syncCtx := context.WithoutCancel(ctx)
+
+		logger = logger.WithField(internal.LogFieldMarketId, converter.GetMarketId(m.GetBase(), m.GetQuote()))
 		logger.Info("syncing history")
-		err = l.h.SyncHistory(&m, 0)
+		err = l.h.SyncHistory(syncCtx, &m, 0)
 		if err != nil {
 			logger.WithError(err).Error("error syncing history")
 			continue
 		}
 
 		logger.Info("syncing orders")
-		err = l.o.SyncMarket(&m)
+		err = l.o.SyncMarket(syncCtx, &m)
 		if err != nil {
 			logger.WithError(err).Error("error syncing orders")
 			continue
 		}
 
 		logger.Info("syncing intervals")
-		err = l.i.SyncIntervals(&m)
+		err = l.i.SyncIntervals(syncCtx, &m)
 		if err != nil {
 			logger.WithError(err).Error("error syncing intervals")
 			continue
 		}
 
+		bumpVersion(syncCtx, l.versions, logger, &m)
 		logger.Info("market synced")
 	}
 
+	//catch up on the articles published while the listener was not listening
+	if err := l.indexCointrunk(context.WithoutCancel(ctx), true, ""); err != nil {
+		l.logger.WithError(err).Error("error indexing cointrunk articles")
+	}
+
 	l.logger.Info("initial sync finished")
 	return nil
 }
 
-func getMarketsMap(mProvider marketProvider) (map[string]types.Market, error) {
-	mTypes, err := mProvider.GetAllMarkets()
+// reloadMarkets replaces the markets kept in memory, they are left as they are when the markets can not be loaded
+func (l *Listener) reloadMarkets(ctx context.Context) (map[string]types.Market, error) {
+	markets, err := getMarketsMap(ctx, l.mProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	l.marketsMx.Lock()
+	defer l.marketsMx.Unlock()
+	l.markets = markets
+
+	return markets, nil
+}
+
+func getMarketsMap(ctx context.Context, mProvider marketProvider) (map[string]types.Market, error) {
+	mTypes, err := mProvider.GetAllMarkets(ctx)
 	if err != nil {
 		return nil, err
 	}
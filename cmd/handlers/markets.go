@@ -1,12 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/sirupsen/logrus"
 )
 
 type marketStorage interface {
-	SyncMarkets() error
+	SyncMarkets(ctx context.Context) error
 }
 
 type MarketsSync struct {
@@ -22,8 +23,8 @@ func NewMarketsSyncHandler(logger logrus.FieldLogger, storage marketStorage) (*M
 	return &MarketsSync{logger: logger, storage: storage}, nil
 }
 
-func (s *MarketsSync) SyncMarkets() {
-	err := s.storage.SyncMarkets()
+func (s *MarketsSync) SyncMarkets(ctx context.Context) {
+	err := s.storage.SyncMarkets(ctx)
 	if err != nil {
 		s.logger.WithError(err).Error("could not save markets")
 		return
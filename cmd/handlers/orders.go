@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze/x/tradebin/types"
@@ -8,38 +10,46 @@ import (
 )
 
 type marketProvider interface {
-	GetAllMarkets() ([]types.Market, error)
+	GetAllMarkets(ctx context.Context) ([]types.Market, error)
 }
 
 type orderStorage interface {
-	SyncMarket(market *types.Market) error
+	SyncMarket(ctx context.Context, market *types.Market) error
 }
 
 type MarketOrderSync struct {
 	mProvider marketProvider
 	storage   orderStorage
+	versions  marketVersions
 	logger    logrus.FieldLogger
 }
 
-func NewMarketOrderSyncHandler(logger logrus.FieldLogger, mProvider marketProvider, storage orderStorage) (*MarketOrderSync, error) {
-	if mProvider == nil || logger == nil || storage == nil {
+func NewMarketOrderSyncHandler(logger logrus.FieldLogger, mProvider marketProvider, storage orderStorage, versions marketVersions) (*MarketOrderSync, error) {
+	if mProvider == nil || logger == nil || storage == nil || versions == nil {
 		return nil, internal.NewInvalidDependenciesErr("NewMarketOrderSyncHandler")
 	}
 
-	return &MarketOrderSync{mProvider: mProvider, logger: logger, storage: storage}, nil
+	return &MarketOrderSync{mProvider: mProvider, logger: logger, storage: storage, versions: versions}, nil
 }
 
-func (s *MarketOrderSync) SyncAll() {
-	syncAll(s.mProvider, s.logger, s.syncMarket)
+func (s *MarketOrderSync) SyncAll(ctx context.Context) {
+	syncAll(ctx, s.mProvider, s.logger, s.syncMarket)
 }
 
-func (s *MarketOrderSync) SyncMarketOrders(marketId string) error {
-	return syncMarket(marketId, s.mProvider, s.logger, s.syncMarket)
+func (s *MarketOrderSync) SyncMarketOrders(ctx context.Context, marketId string) error {
+	return syncMarket(ctx, marketId, s.mProvider, s.logger, s.syncMarket)
 }
 
-func (s *MarketOrderSync) syncMarket(market *types.Market) error {
-	l := s.logger.WithField("market_id", converter.GetMarketId(market.GetBase(), market.GetQuote()))
+func (s *MarketOrderSync) syncMarket(ctx context.Context, market *types.Market) error {
+	l := s.logger.WithField(internal.LogFieldMarketId, converter.GetMarketId(market.GetBase(), market.GetQuote()))
 	l.Info("preparing to sync market")
 
-	return s.storage.SyncMarket(market)
+	err := s.storage.SyncMarket(ctx, market)
+	if err != nil {
+		return err
+	}
+
+	bumpVersion(ctx, s.versions, l, market)
+
+	return nil
 }
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type priceSnapshots interface {
+	TakeSnapshots(ctx context.Context) error
+}
+
+type PricesSync struct {
+	snapshots priceSnapshots
+	logger    logrus.FieldLogger
+}
+
+func NewPricesSyncHandler(logger logrus.FieldLogger, snapshots priceSnapshots) (*PricesSync, error) {
+	if logger == nil || snapshots == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewPricesSyncHandler")
+	}
+
+	return &PricesSync{logger: logger, snapshots: snapshots}, nil
+}
+
+// SyncAll saves a snapshot of the current prices
+func (p *PricesSync) SyncAll(ctx context.Context) error {
+	err := p.snapshots.TakeSnapshots(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Info("prices sync finished")
+
+	return nil
+}
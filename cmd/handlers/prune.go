@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type pruner interface {
+	Prune(ctx context.Context) error
+}
+
+type Prune struct {
+	pruner pruner
+	logger logrus.FieldLogger
+}
+
+func NewPruneHandler(logger logrus.FieldLogger, pruner pruner) (*Prune, error) {
+	if logger == nil || pruner == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewPruneHandler")
+	}
+
+	return &Prune{logger: logger, pruner: pruner}, nil
+}
+
+// PruneAll deletes the history and intervals of all markets that are older than the retention policy allows
+func (p *Prune) PruneAll(ctx context.Context) error {
+	err := p.pruner.Prune(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Info("prune finished")
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type supplySnapshots interface {
+	TakeSnapshots(ctx context.Context, denoms []string) error
+}
+
+type SupplySync struct {
+	snapshots supplySnapshots
+	denoms    []string
+	logger    logrus.FieldLogger
+}
+
+func NewSupplySyncHandler(logger logrus.FieldLogger, snapshots supplySnapshots, denoms []string) (*SupplySync, error) {
+	if logger == nil || snapshots == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewSupplySyncHandler")
+	}
+
+	return &SupplySync{logger: logger, snapshots: snapshots, denoms: denoms}, nil
+}
+
+// SyncAll saves a snapshot of the supply of every tracked denom
+func (s *SupplySync) SyncAll(ctx context.Context) error {
+	if len(s.denoms) == 0 {
+		s.logger.Info("no tracked denoms")
+
+		return nil
+	}
+
+	err := s.snapshots.TakeSnapshots(ctx, s.denoms)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("supply sync finished")
+
+	return nil
+}
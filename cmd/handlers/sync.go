@@ -1,14 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/converter"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze/x/tradebin/types"
 	"github.com/sirupsen/logrus"
 )
 
-func getMarkets(provider marketProvider, logger logrus.FieldLogger) []types.Market {
-	res, err := provider.GetAllMarkets()
+// marketVersions records the markets synced, so the API can drop their cached responses
+type marketVersions interface {
+	Bump(ctx context.Context, marketId string) error
+}
+
+func getMarkets(ctx context.Context, provider marketProvider, logger logrus.FieldLogger) []types.Market {
+	res, err := provider.GetAllMarkets(ctx)
 	if err != nil {
 		logger.WithError(err).Error("could not get markets")
 		return nil
@@ -17,8 +24,8 @@ func getMarkets(provider marketProvider, logger logrus.FieldLogger) []types.Mark
 	return res
 }
 
-func syncMarket(marketId string, provider marketProvider, logger logrus.FieldLogger, syncFunc func(m *types.Market) error) error {
-	all := getMarkets(provider, logger)
+func syncMarket(ctx context.Context, marketId string, provider marketProvider, logger logrus.FieldLogger, syncFunc func(ctx context.Context, m *types.Market) error) error {
+	all := getMarkets(ctx, provider, logger)
 	if len(all) == 0 {
 		return fmt.Errorf("no markets found")
 	}
@@ -26,28 +33,43 @@ func syncMarket(marketId string, provider marketProvider, logger logrus.FieldLog
 	for _, m := range all {
 		mId := converter.GetMarketId(m.GetBase(), m.GetQuote())
 		if mId == marketId {
-			return syncFunc(&m)
+			return syncFunc(ctx, &m)
 		}
 	}
 
 	return fmt.Errorf("market %s not found", marketId)
 }
 
-func syncAll(provider marketProvider, logger logrus.FieldLogger, syncFunc func(m *types.Market) error) {
-	res := getMarkets(provider, logger)
+// syncAll runs syncFunc for every market. When ctx is canceled it stops before starting the next market,
+// while the market being synced is allowed to finish so that its writes are not rolled back halfway.
+func syncAll(ctx context.Context, provider marketProvider, logger logrus.FieldLogger, syncFunc func(ctx context.Context, m *types.Market) error) {
+	res := getMarkets(ctx, provider, logger)
 	if len(res) == 0 {
 		logger.Error("could not fetch markets to use in syncAll")
 		return
 	}
 
 	for _, m := range res {
+		if ctx.Err() != nil {
+			logger.Info("sync interrupted")
+			return
+		}
+
 		mId := converter.GetMarketId(m.GetBase(), m.GetQuote())
-		l := logger.WithField("market_id", mId)
+		l := logger.WithField(internal.LogFieldMarketId, mId)
 
-		err := syncFunc(&m)
+		err := syncFunc(context.WithoutCancel(ctx), &m)
 		if err != nil {
 			l.WithError(err).Error("could not run syncAll")
 			continue
 		}
 	}
 }
+
+// bumpVersion marks the market as changed. A failure is only logged: the cached responses expire anyway.
+func bumpVersion(ctx context.Context, versions marketVersions, logger logrus.FieldLogger, m *types.Market) {
+	mId := converter.GetMarketId(m.GetBase(), m.GetQuote())
+	if err := versions.Bump(ctx, mId); err != nil {
+		logger.WithError(err).WithField(internal.LogFieldMarketId, mId).Warn("could not bump market version")
+	}
+}
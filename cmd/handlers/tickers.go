@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/sirupsen/logrus"
+)
+
+type tickerWindow interface {
+	RollWindow(ctx context.Context) error
+}
+
+type TickersSync struct {
+	storage tickerWindow
+	logger  logrus.FieldLogger
+}
+
+func NewTickersSyncHandler(logger logrus.FieldLogger, storage tickerWindow) (*TickersSync, error) {
+	if logger == nil || storage == nil {
+		return nil, internal.NewInvalidDependenciesErr("NewTickersSyncHandler")
+	}
+
+	return &TickersSync{logger: logger, storage: storage}, nil
+}
+
+// SyncAll rebuilds the tickers of all markets, moving their 24h window forward
+func (s *TickersSync) SyncAll(ctx context.Context) error {
+	err := s.storage.RollWindow(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("tickers sync finished")
+
+	return nil
+}
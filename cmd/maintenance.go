@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Database maintenance tasks",
+	Long: `List of maintenance commands:
+Usage:
+./bze-agg maintenance prune
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Usage()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(maintenanceCmd)
+}
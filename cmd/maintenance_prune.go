@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/bze-alphateam/bze-aggregator-api/cmd/factory"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/spf13/cobra"
+)
+
+var maintenancePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Args:  cobra.ExactArgs(0),
+	Short: "Prune old history and intervals",
+	Long: `Deletes the trade history and the intervals older than the configured retention (see the retention config).
+Finer intervals are deleted only when the coarser intervals covering them exist.
+The listener runs it every retention.prune_interval_hours, run it from a cron when the listener is not used.
+Usage:
+./bze-agg maintenance prune
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		cfg, err := config.Load(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		logger, err := internal.NewLogger(cfg)
+		if err != nil {
+			return err
+		}
+		logger = logger.WithField("command", "maintenance_prune")
+
+		flushTraces, err := setupTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer flushTraces()
+
+		ctx, cleanup := newCommandContext(logger)
+		defer cleanup()
+
+		handler, err := factory.GetPruneHandler(cfg, logger)
+		if err != nil {
+			return err
+		}
+
+		return handler.PruneAll(ctx)
+	},
+}
+
+func init() {
+	maintenanceCmd.AddCommand(maintenancePruneCmd)
+}
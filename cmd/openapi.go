@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/openapi"
+	"github.com/bze-alphateam/bze-aggregator-api/server"
+	"github.com/spf13/cobra"
+)
+
+// openapiCmd prints the OpenAPI spec and checks it against the routes of the API server
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Prints the OpenAPI spec of the API",
+	Long: `Prints the OpenAPI spec of the API, generated from the request and response DTOs.
+With --check it only verifies that every route registered by the API server is in the spec
+and exits with an error when they don't match.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		check, err := cmd.Flags().GetBool("check")
+		if err != nil {
+			return err
+		}
+
+		if check {
+			if err = openapi.CheckRoutes(server.Routes()); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "the OpenAPI spec matches the registered routes")
+
+			return nil
+		}
+
+		spec, err := openapi.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(spec))
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(openapiCmd)
+	openapiCmd.Flags().Bool("check", false, "check that the spec documents all the routes of the API server")
+}
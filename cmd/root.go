@@ -3,20 +3,32 @@ package cmd
 import (
 	"os"
 
+	"github.com/bze-alphateam/bze-aggregator-api/server"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
 	"github.com/spf13/cobra"
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "bze-agg",
-	Short: "BZE Aggregator commands",
-	Long:  `The aggregator commands provide a way to sync blockchain data with the database`,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
-	//RunE: func(cmd *cobra.Command, args []string) error {
-	//
-	//	return action.RunApp()
-	//},
+	Short: "BZE Aggregator API and commands",
+	Long: `Without a subcommand it starts the API server.
+The aggregator commands provide a way to sync blockchain data with the database.
+
+Config is read from (lowest to highest precedence): the config file (--config or CONFIG_FILE),
+the .env file, environment variables and the CLI flags.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		server.Start(cfg)
+
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -29,13 +41,6 @@ func Execute() {
 }
 
 func init() {
-	// Here you will define your flags and configuration settings.
-	// Cobra supports persistent flags, which, if defined here,
-	// will be global for your application.
-
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.go-tha-utxos.yaml)")
-
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
-	//rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	// every config value can be overridden by a flag, for the server and for all the commands
+	config.RegisterFlags(rootCmd.PersistentFlags())
 }
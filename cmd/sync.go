@@ -1,6 +1,15 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
+	"github.com/bze-alphateam/bze-aggregator-api/connector"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
 
 var syncCmd = &cobra.Command{
 	Use:   "sync",
@@ -10,6 +19,9 @@ Usage:
 ./bze-agg sync markets
 ./bze-agg sync orders
 ./bze-agg sync history
+./bze-agg sync intervals
+./bze-agg sync tickers
+./bze-agg sync supply
 ./bze-agg sync listener
 `,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -21,3 +33,27 @@ func init() {
 	rootCmd.AddCommand(syncCmd)
 	syncCmd.PersistentFlags().String(flagMarketId, "", "the blockchain market id we want to sync")
 }
+
+// newCommandContext returns a context canceled on SIGINT or SIGTERM and the func releasing the shared connections
+func newCommandContext(logger logrus.FieldLogger) (context.Context, func()) {
+	ctx, stop := internal.NewShutdownContext()
+
+	return ctx, func() {
+		stop()
+		connector.Close(logger)
+	}
+}
+
+// setupTracing registers the configured tracer provider and returns the func flushing it on exit
+func setupTracing(cfg *config.AppConfig, logger logrus.FieldLogger) (func(), error) {
+	shutdown, err := tracing.Setup(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		if err := shutdown(context.Background()); err != nil {
+			logger.WithError(err).Error("could not flush traces")
+		}
+	}, nil
+}
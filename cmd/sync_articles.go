@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/bze-alphateam/bze-aggregator-api/cmd/factory"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/spf13/cobra"
+)
+
+var syncArticlesCmd = &cobra.Command{
+	Use:   "articles",
+	Args:  cobra.ExactArgs(0),
+	Short: "Save the articles of the feeds",
+	Long: `Reads the articles.feeds and saves their articles into the database, the API serves only the saved articles.
+The listener does it every articles.refresh_minutes, run it from a cron when the listener is not used.
+Usage:
+./bze-agg sync articles
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		cfg, err := config.Load(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		logger, err := internal.NewLogger(cfg)
+		if err != nil {
+			return err
+		}
+		logger = logger.WithField("command", "sync_articles")
+
+		flushTraces, err := setupTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer flushTraces()
+
+		ctx, cleanup := newCommandContext(logger)
+		defer cleanup()
+
+		handler, err := factory.GetArticlesSyncHandler(cfg, logger)
+		if err != nil {
+			return err
+		}
+
+		return handler.SyncAll(ctx)
+	},
+}
+
+func init() {
+	syncCmd.AddCommand(syncArticlesCmd)
+}
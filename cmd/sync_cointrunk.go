@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/bze-alphateam/bze-aggregator-api/cmd/factory"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/spf13/cobra"
+)
+
+var syncCointrunkCmd = &cobra.Command{
+	Use:   "cointrunk",
+	Args:  cobra.ExactArgs(0),
+	Short: "Index the articles published on chain",
+	Long: `Saves the cointrunk articles and publishers from blockchain into the database.
+The listener saves the new articles from the chain events, run it to re-index everything or from a cron when the
+listener is not used.
+Usage:
+./bze-agg sync cointrunk
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		cfg, err := config.Load(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		logger, err := internal.NewLogger(cfg)
+		if err != nil {
+			return err
+		}
+		logger = logger.WithField("command", "sync_cointrunk")
+
+		flushTraces, err := setupTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer flushTraces()
+
+		ctx, cleanup := newCommandContext(logger)
+		defer cleanup()
+
+		handler, err := factory.GetCointrunkSyncHandler(cfg, logger)
+		if err != nil {
+			return err
+		}
+
+		return handler.SyncAll(ctx)
+	},
+}
+
+func init() {
+	syncCmd.AddCommand(syncCointrunkCmd)
+}
@@ -18,7 +18,7 @@ Usage:
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		cfg, err := config.NewAppConfig()
+		cfg, err := config.Load(cmd.Flags())
 		if err != nil {
 			return err
 		}
@@ -29,6 +29,15 @@ Usage:
 		}
 		logger = logger.WithField("command", "sync_intervals")
 
+		flushTraces, err := setupTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer flushTraces()
+
+		ctx, cleanup := newCommandContext(logger)
+		defer cleanup()
+
 		handler, err := factory.GetMarketIntervalSyncHandler(cfg, logger)
 		if err != nil {
 			return err
@@ -39,11 +48,11 @@ Usage:
 			logger.Info("no market id specified")
 			logger.Info("syncing all markets intervals")
 
-			handler.SyncAll()
+			handler.SyncAll(ctx)
 		} else {
 			logger.Infof("syncing intervals for market with id %s", marketId)
 
-			return handler.SyncIntervals(marketId)
+			return handler.SyncIntervals(ctx, marketId)
 		}
 
 		return nil
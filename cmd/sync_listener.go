@@ -1,12 +1,19 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
 	"github.com/bze-alphateam/bze-aggregator-api/cmd/factory"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze-aggregator-api/server/config"
 	"github.com/spf13/cobra"
 )
 
+const (
+	flagMetricsPort = "metrics-port"
+)
+
 var syncListenerCmd = &cobra.Command{
 	Use:   "listener",
 	Args:  cobra.ExactArgs(0),
@@ -14,10 +21,11 @@ var syncListenerCmd = &cobra.Command{
 	Long: `Sync listener subscribes to tendermint websocket and listens for DEX changes and syncs them
 Usage:
 ./bze-agg sync listener
+./bze-agg sync listener --metrics-port 9100
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		cfg, err := config.NewAppConfig()
+		cfg, err := config.Load(cmd.Flags())
 		if err != nil {
 			return err
 		}
@@ -28,15 +36,40 @@ Usage:
 		}
 		logger = logger.WithField("command", "sync_listener")
 
+		flushTraces, err := setupTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer flushTraces()
+
+		ctx, cleanup := newCommandContext(logger)
+		defer cleanup()
+
+		port, _ := cmd.Flags().GetString(flagMetricsPort)
+		if port == "" {
+			port = cfg.Metrics.ListenerPort
+		}
+
+		if cfg.Metrics.Enabled && port != "" {
+			go func() {
+				logger.Infof("exposing metrics on port %s", port)
+				err := metrics.ListenAndServe(fmt.Sprintf(":%s", port))
+				if err != nil {
+					logger.WithError(err).Error("metrics server stopped")
+				}
+			}()
+		}
+
 		handler, err := factory.GetSyncListener(cfg, logger)
 		if err != nil {
 			return err
 		}
 
-		return handler.ListenAndSync()
+		return handler.ListenAndSync(ctx)
 	},
 }
 
 func init() {
 	syncCmd.AddCommand(syncListenerCmd)
+	syncListenerCmd.Flags().String(flagMetricsPort, "", "the port used to expose prometheus metrics (overrides LISTENER_METRICS_PORT)")
 }
@@ -16,7 +16,7 @@ Usage:
 ./bze-agg sync markets
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.NewAppConfig()
+		cfg, err := config.Load(cmd.Flags())
 		if err != nil {
 			return err
 		}
@@ -27,12 +27,21 @@ Usage:
 		}
 		logger = logger.WithField("command", "sync_markets")
 
+		flushTraces, err := setupTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer flushTraces()
+
+		ctx, cleanup := newCommandContext(logger)
+		defer cleanup()
+
 		handler, err := factory.GetMarketsSyncHandler(cfg, logger)
 		if err != nil {
 			return err
 		}
 
-		handler.SyncMarkets()
+		handler.SyncMarkets(ctx)
 
 		return nil
 	},
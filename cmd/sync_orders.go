@@ -22,7 +22,7 @@ Usage:
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		cfg, err := config.NewAppConfig()
+		cfg, err := config.Load(cmd.Flags())
 		if err != nil {
 			return err
 		}
@@ -33,6 +33,15 @@ Usage:
 		}
 		logger = logger.WithField("command", "sync_orders")
 
+		flushTraces, err := setupTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer flushTraces()
+
+		ctx, cleanup := newCommandContext(logger)
+		defer cleanup()
+
 		handler, err := factory.GetMarketOrderSyncHandler(cfg, logger)
 		if err != nil {
 			return err
@@ -43,11 +52,11 @@ Usage:
 			logger.Info("no market id specified")
 			logger.Info("syncing all markets orders")
 
-			handler.SyncAll()
+			handler.SyncAll(ctx)
 		} else {
 			logger.Infof("syncing orders for market with id %s", marketId)
 
-			return handler.SyncMarketOrders(marketId)
+			return handler.SyncMarketOrders(ctx, marketId)
 		}
 
 		return nil
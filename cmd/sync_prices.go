@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/bze-alphateam/bze-aggregator-api/cmd/factory"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/spf13/cobra"
+)
+
+var syncPricesCmd = &cobra.Command{
+	Use:   "prices",
+	Args:  cobra.ExactArgs(0),
+	Short: "Save the current prices",
+	Long: `Saves a snapshot of the price of every prices.denominations coin in every prices.vs_currencies currency.
+The listener does it every prices.snapshot_minutes, run it from a cron when the listener is not used.
+Usage:
+./bze-agg sync prices
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		cfg, err := config.Load(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		logger, err := internal.NewLogger(cfg)
+		if err != nil {
+			return err
+		}
+		logger = logger.WithField("command", "sync_prices")
+
+		flushTraces, err := setupTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer flushTraces()
+
+		ctx, cleanup := newCommandContext(logger)
+		defer cleanup()
+
+		handler, err := factory.GetPricesSyncHandler(cfg, logger)
+		if err != nil {
+			return err
+		}
+
+		return handler.SyncAll(ctx)
+	},
+}
+
+func init() {
+	syncCmd.AddCommand(syncPricesCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/bze-alphateam/bze-aggregator-api/cmd/factory"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/spf13/cobra"
+)
+
+var syncSupplyCmd = &cobra.Command{
+	Use:   "supply",
+	Args:  cobra.ExactArgs(0),
+	Short: "Save the supply of the tracked denoms",
+	Long: `Saves a snapshot of the total, community pool and circulating supply of every supply.tracked_denoms denom.
+The listener does it every supply.snapshot_minutes, run it from a cron when the listener is not used.
+Usage:
+./bze-agg sync supply
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		cfg, err := config.Load(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		logger, err := internal.NewLogger(cfg)
+		if err != nil {
+			return err
+		}
+		logger = logger.WithField("command", "sync_supply")
+
+		flushTraces, err := setupTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer flushTraces()
+
+		ctx, cleanup := newCommandContext(logger)
+		defer cleanup()
+
+		handler, err := factory.GetSupplySyncHandler(cfg, logger)
+		if err != nil {
+			return err
+		}
+
+		return handler.SyncAll(ctx)
+	},
+}
+
+func init() {
+	syncCmd.AddCommand(syncSupplyCmd)
+}
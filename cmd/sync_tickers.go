@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/bze-alphateam/bze-aggregator-api/cmd/factory"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/spf13/cobra"
+)
+
+var syncTickersCmd = &cobra.Command{
+	Use:   "tickers",
+	Args:  cobra.ExactArgs(0),
+	Short: "Sync markets tickers",
+	Long: `Rebuilds the precomputed tickers of all markets, moving their 24h window forward.
+The listener does it every 5 minutes, run it from a cron when the listener is not used or to fill the tickers table.
+Usage:
+./bze-agg sync tickers
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		cfg, err := config.Load(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		logger, err := internal.NewLogger(cfg)
+		if err != nil {
+			return err
+		}
+		logger = logger.WithField("command", "sync_tickers")
+
+		flushTraces, err := setupTracing(cfg, logger)
+		if err != nil {
+			return err
+		}
+		defer flushTraces()
+
+		ctx, cleanup := newCommandContext(logger)
+		defer cleanup()
+
+		handler, err := factory.GetTickersSyncHandler(cfg, logger)
+		if err != nil {
+			return err
+		}
+
+		return handler.SyncAll(ctx)
+	},
+}
+
+func init() {
+	syncCmd.AddCommand(syncTickersCmd)
+}
@@ -1,34 +1,24 @@
 package connector
 
 import (
-	"fmt"
-	"github.com/jmoiron/sqlx"
-	"github.com/joho/godotenv"
 	"time"
 
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/jmoiron/sqlx"
+
 	_ "github.com/go-sql-driver/mysql"
 )
 
-func NewDatabaseConnection() (*sqlx.DB, error) {
-	envFile, err := godotenv.Read(".env")
-	if err != nil {
-		return nil, err
-	}
-
-	dsn, ok := envFile["MYSQL_DSN"]
-	if !ok {
-		return nil, fmt.Errorf("MYSQL_DSN not found in .env")
-	}
-
-	db, err := sqlx.Connect("mysql", dsn)
+func NewDatabaseConnection(cfg config.Database) (*sqlx.DB, error) {
+	db, err := sqlx.Connect("mysql", cfg.Dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetMaxOpenConns(100)
-	db.SetMaxIdleConns(50)
-	db.SetConnMaxLifetime(time.Second * 5)
-	db.SetConnMaxIdleTime(time.Second * 5)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Second * time.Duration(cfg.ConnMaxLifetimeSeconds))
+	db.SetConnMaxIdleTime(time.Second * time.Duration(cfg.ConnMaxIdleTimeSeconds))
 
 	if err = db.Ping(); nil != err {
 		return nil, err
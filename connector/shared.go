@@ -0,0 +1,217 @@
+package connector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/client"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/data_provider"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/lock"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/nodepool"
+	"github.com/bze-alphateam/bze-aggregator-api/server/config"
+	"github.com/cometbft/cometbft/rpc/client/http"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// The connections below are shared by every factory in the process and closed once by Close on shutdown.
+// The node pools are probed in background until Close is called.
+var (
+	sharedMu   sync.Mutex
+	database   *sqlx.DB
+	grpcClient *client.GrpcClient
+	rpcClients = make(map[string]*http.HTTP)
+	restPool   *nodepool.Pool
+	wsPool     *nodepool.Pool
+	chainReg   *data_provider.ChainRegistry
+
+	poolsCtx, stopPools = context.WithCancel(context.Background())
+)
+
+// GetDatabase returns the process wide database pool, opening it on first use
+func GetDatabase(cfg config.Database) (*sqlx.DB, error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if database != nil {
+		return database, nil
+	}
+
+	db, err := NewDatabaseConnection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	database = db
+
+	return database, nil
+}
+
+// GetGrpcClient returns the process wide gRPC client. The connection itself is dialed lazily by the client.
+func GetGrpcClient(cfg *config.AppConfig, logger logrus.FieldLogger) (*client.GrpcClient, error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if grpcClient != nil {
+		return grpcClient, nil
+	}
+
+	c, err := client.NewGrpcClient(cfg, lock.GetInMemoryLocker(), logger)
+	if err != nil {
+		return nil, err
+	}
+	grpcClient = c
+	go c.Nodes().Run(poolsCtx, config.Seconds(cfg.NodePool.RefreshSeconds))
+
+	return grpcClient, nil
+}
+
+// GetRpcClient returns the RPC client of the given host, reusing it between factories
+func GetRpcClient(host string, timeoutSeconds int) (*http.HTTP, error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	return getRpcClient(host, timeoutSeconds)
+}
+
+func getRpcClient(host string, timeoutSeconds int) (*http.HTTP, error) {
+	if c, ok := rpcClients[host]; ok {
+		return c, nil
+	}
+
+	c, err := client.GetRpcClient(host, uint(timeoutSeconds))
+	if err != nil {
+		return nil, err
+	}
+	rpcClients[host] = c
+
+	return c, nil
+}
+
+// GetRestPool returns the process wide pool of REST nodes
+func GetRestPool(cfg *config.AppConfig, logger logrus.FieldLogger) (*nodepool.Pool, error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if restPool != nil {
+		return restPool, nil
+	}
+
+	probe := client.NewRestProber(config.Seconds(cfg.Timeouts.HttpSeconds))
+	p, err := nodepool.NewPool("rest", cfg.Blockchain.RestHosts(), probe, cfg.NodePool, logger)
+	if err != nil {
+		return nil, err
+	}
+	restPool = p
+	go p.Run(poolsCtx, config.Seconds(cfg.NodePool.RefreshSeconds))
+
+	return restPool, nil
+}
+
+// GetWsPool returns the process wide pool of RPC nodes used for websocket subscriptions
+func GetWsPool(cfg *config.AppConfig, logger logrus.FieldLogger) (*nodepool.Pool, error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if wsPool != nil {
+		return wsPool, nil
+	}
+
+	probe := func(ctx context.Context, host string) (int64, error) {
+		sharedMu.Lock()
+		rpc, err := getRpcClient(host, cfg.Timeouts.RpcSeconds)
+		sharedMu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+
+		status, err := rpc.Status(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		return status.SyncInfo.LatestBlockHeight, nil
+	}
+
+	p, err := nodepool.NewPool("ws", cfg.Blockchain.WsHosts(), probe, cfg.NodePool, logger)
+	if err != nil {
+		return nil, err
+	}
+	wsPool = p
+	go p.Run(poolsCtx, config.Seconds(cfg.NodePool.RefreshSeconds))
+
+	return wsPool, nil
+}
+
+// GetChainRegistry returns the process wide chain registry, so all the services share one snapshot of the asset list
+func GetChainRegistry(cfg *config.AppConfig, logger logrus.FieldLogger) (*data_provider.ChainRegistry, error) {
+	nodes, err := GetRestPool(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if chainReg != nil {
+		return chainReg, nil
+	}
+
+	timeout := config.Seconds(cfg.Timeouts.HttpSeconds)
+	store, err := client.NewChainRegistry(cfg.ChainRegistry.AssetListUrl, cfg.ChainRegistry.AssetListFile, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	counterparties := make(map[string]data_provider.RegistryStore, len(cfg.ChainRegistry.IbcAssetLists))
+	for channel, listUrl := range cfg.ChainRegistry.IbcAssetLists {
+		counterparties[channel], err = client.NewChainRegistry(listUrl, "", timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rest, err := client.NewBlockchainQueryClient(nodes, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	chainReg, err = data_provider.NewChainRegistry(logger, store, rest, counterparties, config.Seconds(cfg.Cache.ChainRegistrySeconds), cfg.ChainRegistry.DefaultExponent)
+
+	return chainReg, err
+}
+
+// Close releases all the shared connections. It must be called after in-flight work finished,
+// since pending queries would fail once the database pool is closed.
+func Close(logger logrus.FieldLogger) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	stopPools()
+	poolsCtx, stopPools = context.WithCancel(context.Background())
+	restPool = nil
+	wsPool = nil
+	chainReg = nil
+
+	if grpcClient != nil {
+		grpcClient.CloseConnection()
+		grpcClient = nil
+		logger.Debug("grpc connection closed")
+	}
+
+	for host, c := range rpcClients {
+		if c.IsRunning() {
+			if err := c.Stop(); err != nil {
+				logger.WithError(err).WithField("host", host).Warn("could not stop rpc client")
+			}
+		}
+		delete(rpcClients, host)
+	}
+
+	if database != nil {
+		if err := database.Close(); err != nil {
+			logger.WithError(err).Warn("could not close database connection")
+		}
+		database = nil
+		logger.Debug("database connection closed")
+	}
+}
@@ -0,0 +1,85 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// TimeoutDatabase bounds every query returning complete results with a timeout.
+// Transactions and row cursors are not bounded since they outlive the call that opened them,
+// they only follow the cancellation of the context they receive.
+// Errors caused by an unreachable or slow database are returned as internal.ErrCodeUnavailable errors.
+type TimeoutDatabase struct {
+	db      internal.Database
+	timeout time.Duration
+}
+
+func NewTimeoutDatabase(db internal.Database, timeout time.Duration) *TimeoutDatabase {
+	return &TimeoutDatabase{db: db, timeout: timeout}
+}
+
+func (d *TimeoutDatabase) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	res, err := d.db.NamedExecContext(ctx, query, arg)
+
+	return res, unavailable(err)
+}
+
+func (d *TimeoutDatabase) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	tx, err := d.db.BeginTxx(ctx, opts)
+
+	return tx, unavailable(err)
+}
+
+func (d *TimeoutDatabase) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	return unavailable(d.db.GetContext(ctx, dest, query, args...))
+}
+
+func (d *TimeoutDatabase) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	res, err := d.db.ExecContext(ctx, query, args...)
+
+	return res, unavailable(err)
+}
+
+func (d *TimeoutDatabase) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	return unavailable(d.db.SelectContext(ctx, dest, query, args...))
+}
+
+func (d *TimeoutDatabase) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	rows, err := d.db.QueryxContext(ctx, query, args...)
+
+	return rows, unavailable(err)
+}
+
+// unavailable marks the connection errors and timeouts, leaving the query errors (e.g. sql.ErrNoRows) untouched
+func unavailable(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.As(err, &netErr) {
+		return internal.NewUnavailableErr("database unavailable", err)
+	}
+
+	return err
+}
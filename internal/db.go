@@ -1,15 +1,17 @@
 package internal
 
 import (
+	"context"
 	"database/sql"
+
 	"github.com/jmoiron/sqlx"
 )
 
 type Database interface {
-	NamedExec(query string, arg interface{}) (sql.Result, error)
-	Beginx() (*sqlx.Tx, error)
-	Get(dest interface{}, query string, args ...interface{}) error
-	Exec(query string, args ...any) (sql.Result, error)
-	Select(dest interface{}, query string, args ...interface{}) error
-	Queryx(query string, args ...interface{}) (*sqlx.Rows, error)
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+	BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error)
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
 }
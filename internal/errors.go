@@ -1,7 +1,121 @@
 package internal
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorCode classifies an error so it can be reported to the API clients with the right status code
+type ErrorCode string
+
+const (
+	ErrCodeInvalidRequest ErrorCode = "invalid_request"
+	ErrCodeUnauthorized   ErrorCode = "unauthorized"
+	ErrCodeValidation     ErrorCode = "validation_failed"
+	ErrCodeNotFound       ErrorCode = "not_found"
+	ErrCodeRateLimited    ErrorCode = "rate_limited"
+	ErrCodeUnavailable    ErrorCode = "service_unavailable"
+	ErrCodeInternal       ErrorCode = "internal_error"
+)
+
+// Error is an error carrying a code and a message that can be shown to the API clients.
+// The wrapped error, if any, is only meant for the logs.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s: %s", e.Message, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
 
 func NewInvalidDependenciesErr(name string) error {
 	return fmt.Errorf("invalid dependencies for: %s", name)
 }
+
+// NewInvalidRequestErr is returned when the request can not be parsed
+func NewInvalidRequestErr(message string) error {
+	return &Error{Code: ErrCodeInvalidRequest, Message: message}
+}
+
+// NewValidationErr is returned when the request was parsed but its values are not valid
+func NewValidationErr(message string) error {
+	return &Error{Code: ErrCodeValidation, Message: message}
+}
+
+// NewNotFoundErr is returned when the requested resource (market, denom etc.) does not exist
+func NewNotFoundErr(message string) error {
+	return &Error{Code: ErrCodeNotFound, Message: message}
+}
+
+// NewUnavailableErr is returned when a dependency (database, blockchain nodes, 3rd party APIs) can not be reached
+func NewUnavailableErr(message string, err error) error {
+	return &Error{Code: ErrCodeUnavailable, Message: message, Err: err}
+}
+
+// ErrorCodeOf returns the code of the first typed error found in err's chain.
+// Untyped errors are internal errors, unless a dependency took too long to answer.
+func ErrorCodeOf(err error) ErrorCode {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Code
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrCodeUnavailable
+	}
+
+	return ErrCodeInternal
+}
+
+// ErrorMessageOf returns the message that can be shown to the API clients for err
+func ErrorMessageOf(err error) string {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Message
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "a dependency took too long to answer"
+	}
+
+	return "internal error"
+}
+
+// StatusCode returns the HTTP status code matching the error code
+func StatusCode(code ErrorCode) int {
+	switch code {
+	case ErrCodeInvalidRequest:
+		return http.StatusBadRequest
+	case ErrCodeValidation:
+		return http.StatusUnprocessableEntity
+	case ErrCodeUnauthorized:
+		return http.StatusUnauthorized
+	case ErrCodeNotFound:
+		return http.StatusNotFound
+	case ErrCodeRateLimited:
+		return http.StatusTooManyRequests
+	case ErrCodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// IsErrorCode reports whether err is a typed error with the given code
+func IsErrorCode(err error, code ErrorCode) bool {
+	var typed *Error
+
+	return errors.As(err, &typed) && typed.Code == code
+}
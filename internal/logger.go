@@ -1,19 +1,42 @@
 package internal
 
 import (
+	"fmt"
+
 	"github.com/bze-alphateam/bze-aggregator-api/server/config"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	LogFormatText = "text"
+	LogFormatJson = "json"
+
+	// field names shared by every log line so the log pipeline can join them
+	LogFieldRequestId   = "request_id"
+	LogFieldTraceId     = "trace_id"
+	LogFieldMarketId    = "market_id"
+	LogFieldBlockHeight = "block_height"
+	LogFieldEventType   = "event_type"
+)
+
 func NewLogger(config *config.AppConfig) (logrus.FieldLogger, error) {
 	logger := logrus.New()
 
 	parsedLogLevel, err := logrus.ParseLevel(config.Logging.Level)
 	if err != nil {
-		logger.Fatal("error on parsing logging level: %s", err)
+		return nil, fmt.Errorf("error on parsing logging level: %w", err)
 	}
 
 	logger.SetLevel(parsedLogLevel)
 
+	switch config.Logging.Format {
+	case "", LogFormatText:
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	case LogFormatJson:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return nil, fmt.Errorf("unknown logging format: %s", config.Logging.Format)
+	}
+
 	return logger, nil
 }
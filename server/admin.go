@@ -0,0 +1,28 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// adminAuth only lets through the requests sending the admin API key as bearer token.
+// Without a configured key the admin endpoints are disabled.
+func adminAuth(apiKey string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if apiKey == "" {
+				return echo.NewHTTPError(http.StatusNotFound, "the admin API is disabled")
+			}
+
+			token, found := strings.CutPrefix(ctx.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(token), []byte(apiKey)) != 1 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid admin API key")
+			}
+
+			return next(ctx)
+		}
+	}
+}
@@ -3,161 +3,1096 @@ package config
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
 )
 
+// DefaultArticlesSource is the source of ARTICLES_FEED_URL, also served by /api/articles/medium
+const DefaultArticlesSource = "medium"
+
+const (
+	defaultPort                = "8000"
+	defaultShutdownTimeout     = 15
+	defaultLoggingLevel        = "info"
+	defaultLoggingFormat       = "text"
+	defaultListenerMetricsPort = "9100"
+	defaultTracingExporter     = "none"
+	defaultTracingServiceName  = "bze-aggregator-api"
+	defaultTracingSampleRatio  = 1.0
+
+	defaultMysqlMaxOpenConns    = 100
+	defaultMysqlMaxIdleConns    = 50
+	defaultMysqlConnMaxLifetime = 5
+	defaultMysqlConnMaxIdleTime = 5
+
+	defaultArticlesFeedUrl = "https://medium.com/feed/bzedge-community"
+	defaultAssetListUrl    = "https://raw.githubusercontent.com/faneaatiku/chain-registry/refs/heads/master/beezee/assetlist.json"
+	defaultAssetExponent   = 6
+	maxDefaultExponent     = 18
+
+	defaultRequestTimeout  = 30
+	defaultDatabaseTimeout = 10
+	defaultGrpcTimeout     = 10
+	defaultRpcTimeout      = 10
+	defaultHttpTimeout     = 10
+	defaultExportTimeout   = 600
+
+	defaultNodePoolRefreshSeconds = 15
+	defaultNodePoolMaxHeightDiff  = 2
+	defaultNodePoolRetries        = 2
+	defaultNodePoolBackoffMillis  = 200
+
+	defaultHealthNodesMaxHeightDiff = 2
+
+	defaultRateLimitStorage        = RateLimitStorageMemory
+	defaultRateLimitAnonymousRps   = 5.0
+	defaultRateLimitAnonymousBurst = 20
+	defaultRateLimitPartnerRps     = 50.0
+	defaultRateLimitPartnerBurst   = 200
+	defaultRateLimitExpensiveCost  = 10
+	defaultCorsAllowOrigins        = "*"
+	minApiKeyLength                = 16
+
+	defaultSupplyCacheSeconds        = 600
+	defaultPricesCacheSeconds        = 180
+	defaultPricesBackupCacheSeconds  = 60 * 60 * 24 //1 day
+	defaultArticlesCacheSeconds      = 600
+	defaultHealthCacheSeconds        = 60 * 10
+	defaultBalancesCacheSeconds      = 30
+	defaultChainRegistryCacheSeconds = 60 * 30
+	defaultDexCacheSeconds           = 60
+	defaultDexMaxAgeSeconds          = 5
+	defaultDexInvalidationSeconds    = 2
+
+	defaultRetentionFiveMinutesDays = 30
+	defaultRetentionQuarterHourDays = 90
+	defaultRetentionPruneHours      = 24
+	//the intervals of the last day are rebuilt from history and feed the tickers
+	minRetentionDays = 2
+
+	defaultSupplyTrackedDenoms   = "ubze"
+	defaultSupplySnapshotMinutes = 60
+	defaultSupplyExclusions      = "ubze=community_pool"
+
+	defaultPricesDenomination    = "bzedge"
+	defaultPricesSources         = PriceSourceCoingecko
+	defaultPricesVsCurrencies    = "usd"
+	defaultPricesStaleSeconds    = 60 * 60
+	defaultPricesSnapshotMinutes = 60
+
+	defaultArticlesRefreshMinutes = 10
+
+	defaultAlertingIntervalSeconds   = 60
+	defaultAlertingFailureThreshold  = 2
+	defaultAlertingCooldownMinutes   = 60
+	defaultAlertingAggregatorMinutes = 10
+	defaultAlertingTelegramApiUrl    = "https://api.telegram.org"
+)
+
+// the price sources
+const (
+	PriceSourceCoingecko = "coingecko"
+	PriceSourceOsmosis   = "osmosis"
+	PriceSourceDex       = "dex"
+	PriceSourceStatic    = "static"
+)
+
+var (
+	//a min amount: a decimal number of at least 0
+	amountRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+	//same rule as the group names saved through the admin API, see request.BalanceGroupName
+	groupNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	//an IBC channel id
+	channelRegexp = regexp.MustCompile(`^channel-[0-9]+$`)
+)
+
+var PriceSources = []string{PriceSourceCoingecko, PriceSourceOsmosis, PriceSourceDex, PriceSourceStatic}
+
+// the kinds of amounts that can be excluded from the circulating supply
 const (
-	defaultPort         = "8000"
-	defaultLoggingLevel = "info"
+	SupplyExclusionCommunityPool = "community_pool"
+	SupplyExclusionModule        = "module"
+	SupplyExclusionAddress       = "address"
+)
+
+const (
+	RateLimitStorageMemory = "memory"
+	RateLimitStorageMysql  = "mysql"
 )
 
 type PrefixedEndpoints map[string]string
 
 type CoingeckoConfig struct {
-	Host string
+	Host string `yaml:"host" toml:"host"`
 }
 
+// PricesConfig holds the priced coins and the sources of their prices. The sources are keyed by id:currency,
+// e.g. bzedge:usd, where id is one of Denominations.
 type PricesConfig struct {
-	Denominations string
+	//the ids of the priced coins (coingecko ids), separated by comma
+	Denominations string `yaml:"denominations" toml:"denominations"`
+	//sources asked in order until every coin has a price: coingecko, osmosis, dex, static
+	Sources string `yaml:"sources" toml:"sources"`
+	//currencies the prices are given in, separated by comma
+	VsCurrencies string `yaml:"vs_currencies" toml:"vs_currencies"`
+	//osmosis REST (LCD) url
+	OsmosisHost string `yaml:"osmosis_host" toml:"osmosis_host"`
+	//id:currency => pool_id:base_denom:quote_denom, the quote must be worth one unit of the currency
+	OsmosisPools map[string]string `yaml:"osmosis_pools" toml:"osmosis_pools"`
+	//id:currency => market id of the internal DEX, the quote must be worth one unit of the currency
+	DexMarkets map[string]string `yaml:"dex_markets" toml:"dex_markets"`
+	//id:currency => price
+	Static map[string]string `yaml:"static" toml:"static"`
+	//prices updated longer ago are flagged as stale
+	StaleSeconds int `yaml:"stale_seconds" toml:"stale_seconds"`
+	//how often the listener saves the prices, 0 disables it
+	SnapshotMinutes int `yaml:"snapshot_minutes" toml:"snapshot_minutes"`
+}
+
+// PriceKey identifies the price of a coin in a currency
+type PriceKey struct {
+	Id       string
+	Currency string
 }
 
+// PriceSourceEntry is the value configured for a price key in one of the sources
+type PriceSourceEntry struct {
+	PriceKey
+	Value string
+}
+
+// Ids returns the ids of the priced coins, bzedge when none is configured
+func (p PricesConfig) Ids() []string {
+	ids := splitHosts(p.Denominations)
+	if len(ids) == 0 {
+		return []string{defaultPricesDenomination}
+	}
+
+	return ids
+}
+
+// SourceNames returns the price sources in the order they are asked
+func (p PricesConfig) SourceNames() []string {
+	return splitHosts(p.Sources)
+}
+
+// Currencies returns the currencies the prices are given in, lower case
+func (p PricesConfig) Currencies() []string {
+	return splitHosts(strings.ToLower(p.VsCurrencies))
+}
+
+// Entries returns the entries of a source map sorted by key. The invalid keys are reported by Validate.
+func (p PricesConfig) Entries(source map[string]string) []PriceSourceEntry {
+	var result []PriceSourceEntry
+	for key, value := range source {
+		parsed, err := parsePriceKey(key)
+		if err != nil {
+			continue
+		}
+		result = append(result, PriceSourceEntry{PriceKey: parsed, Value: value})
+	}
+
+	slices.SortFunc(result, func(a, b PriceSourceEntry) int {
+		return strings.Compare(a.Id+":"+a.Currency, b.Id+":"+b.Currency)
+	})
+
+	return result
+}
+
+func parsePriceKey(key string) (PriceKey, error) {
+	id, currency, found := strings.Cut(key, ":")
+	if !found || id == "" || currency == "" {
+		return PriceKey{}, fmt.Errorf("%q must be id:currency", key)
+	}
+
+	return PriceKey{Id: id, Currency: strings.ToLower(currency)}, nil
+}
+
+func validatePrices(p PricesConfig) (errs []error) {
+	if len(p.Currencies()) == 0 {
+		errs = append(errs, fmt.Errorf("prices.vs_currencies can not be empty"))
+	}
+
+	for _, name := range p.SourceNames() {
+		if !slices.Contains(PriceSources, name) {
+			errs = append(errs, fmt.Errorf("prices.sources must be a list of [%s], got %q", strings.Join(PriceSources, ", "), name))
+		}
+	}
+
+	if len(p.SourceNames()) == 0 {
+		errs = append(errs, fmt.Errorf("prices.sources can not be empty"))
+	}
+
+	if slices.Contains(p.SourceNames(), PriceSourceOsmosis) {
+		errs = append(errs, validateUrl("prices.osmosis_host", p.OsmosisHost))
+	}
+
+	sources := map[string]map[string]string{"osmosis_pools": p.OsmosisPools, "dex_markets": p.DexMarkets, "static": p.Static}
+	for name, source := range sources {
+		for key, value := range source {
+			if _, err := parsePriceKey(key); err != nil {
+				errs = append(errs, fmt.Errorf("prices.%s: %w", name, err))
+			}
+
+			switch name {
+			case "osmosis_pools":
+				if parts := strings.Split(value, ":"); len(parts) != 3 || slices.Contains(parts, "") {
+					errs = append(errs, fmt.Errorf("prices.osmosis_pools.%s must be pool_id:base_denom:quote_denom", key))
+				}
+			case "static":
+				if price, err := strconv.ParseFloat(value, 64); err != nil || price <= 0 {
+					errs = append(errs, fmt.Errorf("prices.static.%s must be a positive number", key))
+				}
+			}
+		}
+	}
+
+	return append(errs,
+		validatePositive("prices.stale_seconds", p.StaleSeconds),
+		validateNonNegative("prices.snapshot_minutes", p.SnapshotMinutes),
+	)
+}
+
+// BlockchainConfig holds the blockchain endpoints. Every host accepts a comma separated list of nodes.
 type BlockchainConfig struct {
-	RestHost    string
-	RpcHost     string
-	GrpcHost    string
-	HealthNodes map[string]string
+	RestHost    string            `yaml:"rest_host" toml:"rest_host"`
+	RpcHost     string            `yaml:"rpc_host" toml:"rpc_host"`
+	GrpcHost    string            `yaml:"grpc_host" toml:"grpc_host"`
+	WsHost      string            `yaml:"ws_host" toml:"ws_host"`
+	HealthNodes map[string]string `yaml:"health_nodes" toml:"health_nodes"`
+
+	UseGrpcTls bool `yaml:"grpc_use_tls" toml:"grpc_use_tls"`
+}
+
+func (b BlockchainConfig) RestHosts() []string {
+	return splitHosts(b.RestHost)
+}
+
+func (b BlockchainConfig) GrpcHosts() []string {
+	return splitHosts(b.GrpcHost)
+}
+
+// WsHosts returns the websocket nodes followed by the health nodes, which are RPC nodes as well
+func (b BlockchainConfig) WsHosts() []string {
+	hosts := splitHosts(b.WsHost)
+	names := make([]string, 0, len(b.HealthNodes))
+	for name := range b.HealthNodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !slices.Contains(hosts, b.HealthNodes[name]) {
+			hosts = append(hosts, b.HealthNodes[name])
+		}
+	}
+
+	return hosts
+}
+
+// NodePool configures how the calls are spread over the blockchain nodes
+type NodePool struct {
+	RefreshSeconds int `yaml:"refresh_seconds" toml:"refresh_seconds"`
+	MaxHeightDiff  int `yaml:"max_height_diff" toml:"max_height_diff"`
+	Retries        int `yaml:"retries" toml:"retries"`
+	BackoffMillis  int `yaml:"backoff_millis" toml:"backoff_millis"`
+}
+
+// Health configures the thresholds of the health endpoints
+type Health struct {
+	//blocks a health node can be behind the highest one
+	NodesMaxHeightDiff int `yaml:"nodes_max_height_diff" toml:"nodes_max_height_diff"`
+	//a health node answering slower is unhealthy, 0 disables the check
+	NodesMaxLatencyMillis int `yaml:"nodes_max_latency_millis" toml:"nodes_max_latency_millis"`
+	//group:address:denom => min amount, checked by /api/health/balances/{group}
+	BalanceGroups map[string]string `yaml:"balance_groups" toml:"balance_groups"`
+}
+
+func (h Health) NodesMaxLatency() time.Duration {
+	return time.Duration(h.NodesMaxLatencyMillis) * time.Millisecond
+}
+
+// Groups returns the balance watch groups by name, each sorted by address and denom.
+// The invalid entries are reported by Validate.
+func (h Health) Groups() map[string][]BalanceWatch {
+	result, _ := parseBalanceGroups(h.BalanceGroups)
+
+	return result
+}
+
+func parseBalanceGroups(groups map[string]string) (map[string][]BalanceWatch, []error) {
+	var errs []error
+	result := make(map[string][]BalanceWatch)
+	for key, value := range groups {
+		parts := strings.SplitN(key, ":", 3)
+		if len(parts) != 3 || !groupNameRegexp.MatchString(parts[0]) || parts[1] == "" || parts[2] == "" {
+			errs = append(errs, fmt.Errorf("health.balance_groups: %q must be group:address:denom, the group made of lowercase letters, digits, - and _", key))
 
-	UseGrpcTls bool
+			continue
+		}
+
+		if !amountRegexp.MatchString(value) {
+			errs = append(errs, fmt.Errorf("health.balance_groups.%s must be a min amount of at least 0, got %q", key, value))
+
+			continue
+		}
+		result[parts[0]] = append(result[parts[0]], BalanceWatch{Address: parts[1], Denom: parts[2], MinAmount: value})
+	}
+
+	for _, watches := range result {
+		sortBalanceWatches(watches)
+	}
+
+	return result, errs
 }
 
 type Logging struct {
-	Level string
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
 }
 
 type Server struct {
-	Port string
+	Port                   string `yaml:"port" toml:"port"`
+	ShutdownTimeoutSeconds int    `yaml:"shutdown_timeout_seconds" toml:"shutdown_timeout_seconds"`
+	//bearer token of the /api/v2/admin endpoints, they are disabled when empty
+	AdminApiKey string `yaml:"admin_api_key" toml:"admin_api_key"`
 }
 
-type AppConfig struct {
-	Server            Server
-	Logging           Logging
-	Blockchain        BlockchainConfig
-	Prices            PricesConfig
-	Coingecko         CoingeckoConfig
-	PrefixedEndpoints PrefixedEndpoints
+// RateLimit configures the token buckets limiting the API requests. Anonymous clients get a bucket per IP,
+// partners (CoinGecko, CMC etc.) get a bucket per API key.
+type RateLimit struct {
+	Enabled        bool    `yaml:"enabled" toml:"enabled"`
+	Storage        string  `yaml:"storage" toml:"storage"`
+	AnonymousRps   float64 `yaml:"anonymous_rps" toml:"anonymous_rps"`
+	AnonymousBurst int     `yaml:"anonymous_burst" toml:"anonymous_burst"`
+	PartnerRps     float64 `yaml:"partner_rps" toml:"partner_rps"`
+	PartnerBurst   int     `yaml:"partner_burst" toml:"partner_burst"`
+	//partner name => API key
+	ApiKeys map[string]string `yaml:"api_keys" toml:"api_keys"`
+	//tokens taken by the endpoints that are expensive to serve
+	ExpensiveCost int `yaml:"expensive_cost" toml:"expensive_cost"`
+	//use the X-Forwarded-For header to find the client IP. Enable it only behind a trusted proxy
+	TrustProxy bool `yaml:"trust_proxy" toml:"trust_proxy"`
 }
 
-func NewAppConfig() (*AppConfig, error) {
-	envFile, err := godotenv.Read(".env")
-	cfg := loadDefaultConfig(envFile, err)
-	rpc, ok := envFile["BLOCKCHAIN_RPC_HOST"]
-	if !ok {
-		return nil, errors.New("BLOCKCHAIN_RPC_HOST not found in .env")
+// Alerting configures the health checks run by the listener and where their state changes are notified
+type Alerting struct {
+	//how often the listener runs the checks, 0 disables the alerting
+	IntervalSeconds int `yaml:"interval_seconds" toml:"interval_seconds"`
+	//consecutive failures before a check is unhealthy
+	FailureThreshold int `yaml:"failure_threshold" toml:"failure_threshold"`
+	//minimum time between two notifications of the same unhealthy check
+	CooldownMinutes int `yaml:"cooldown_minutes" toml:"cooldown_minutes"`
+	//market id => minutes without trades before the market is unhealthy
+	Markets map[string]string `yaml:"markets" toml:"markets"`
+	//minutes without synced trades before the aggregator is unhealthy, 0 disables the check
+	AggregatorMinutes int `yaml:"aggregator_minutes" toml:"aggregator_minutes"`
+	//address:denom => min amount (base units)
+	Balances map[string]string `yaml:"balances" toml:"balances"`
+
+	WebhookUrl       string `yaml:"webhook_url" toml:"webhook_url"`
+	SlackWebhookUrl  string `yaml:"slack_webhook_url" toml:"slack_webhook_url"`
+	TelegramApiUrl   string `yaml:"telegram_api_url" toml:"telegram_api_url"`
+	TelegramBotToken string `yaml:"telegram_bot_token" toml:"telegram_bot_token"`
+	TelegramChatId   string `yaml:"telegram_chat_id" toml:"telegram_chat_id"`
+}
+
+// AlertMarket is a market expected to have trades in the last Minutes
+type AlertMarket struct {
+	MarketId string
+	Minutes  int
+}
+
+// BalanceWatch is an address expected to hold at least MinAmount of Denom. MinAmount is a decimal number, in
+// display units when Denom is a display denom of the chain registry.
+type BalanceWatch struct {
+	Address   string
+	Denom     string
+	MinAmount string
+}
+
+// MarketChecks returns the watched markets sorted by id. The invalid entries are reported by Validate.
+func (a Alerting) MarketChecks() []AlertMarket {
+	result, _ := parseAlertMarkets(a.Markets)
+
+	return result
+}
+
+// BalanceChecks returns the watched balances sorted by address and denom. The invalid entries are reported by Validate.
+func (a Alerting) BalanceChecks() []BalanceWatch {
+	result, _ := parseAlertBalances(a.Balances)
+
+	return result
+}
+
+func parseAlertMarkets(markets map[string]string) (result []AlertMarket, errs []error) {
+	for marketId, value := range markets {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes <= 0 {
+			errs = append(errs, fmt.Errorf("alerting.markets.%s must be a number of minutes greater than 0, got %q", marketId, value))
+
+			continue
+		}
+		result = append(result, AlertMarket{MarketId: marketId, Minutes: minutes})
 	}
+	slices.SortFunc(result, func(a, b AlertMarket) int { return strings.Compare(a.MarketId, b.MarketId) })
+
+	return result, errs
+}
 
-	rest, ok := envFile["BLOCKCHAIN_REST_HOST"]
-	if !ok {
-		return nil, errors.New("BLOCKCHAIN_REST_HOST not found in .env")
+func parseAlertBalances(balances map[string]string) (result []BalanceWatch, errs []error) {
+	for key, value := range balances {
+		address, denom, found := strings.Cut(key, ":")
+		if !found || address == "" || denom == "" {
+			errs = append(errs, fmt.Errorf("alerting.balances: %q must be address:denom", key))
+
+			continue
+		}
+
+		if !amountRegexp.MatchString(value) {
+			errs = append(errs, fmt.Errorf("alerting.balances.%s must be a min amount of at least 0, got %q", key, value))
+
+			continue
+		}
+		result = append(result, BalanceWatch{Address: address, Denom: denom, MinAmount: value})
 	}
+	sortBalanceWatches(result)
+
+	return result, errs
+}
+
+func sortBalanceWatches(watches []BalanceWatch) {
+	slices.SortFunc(watches, func(a, b BalanceWatch) int {
+		return strings.Compare(a.Address+":"+a.Denom, b.Address+":"+b.Denom)
+	})
+}
 
-	grpc, ok := envFile["BLOCKCHAIN_GRPC_HOST"]
-	if !ok {
-		return nil, errors.New("BLOCKCHAIN_GRPC_HOST not found in .env")
+func validateAlerting(a Alerting) (errs []error) {
+	_, marketErrs := parseAlertMarkets(a.Markets)
+	_, balanceErrs := parseAlertBalances(a.Balances)
+	errs = append(errs, marketErrs...)
+	errs = append(errs, balanceErrs...)
+
+	if (a.TelegramBotToken == "") != (a.TelegramChatId == "") {
+		errs = append(errs, fmt.Errorf("alerting.telegram_bot_token and alerting.telegram_chat_id must be set together"))
 	}
 
-	cg, ok := envFile["COINGECKO_HOST"]
-	if !ok {
-		return nil, errors.New("COINGECKO_HOST not found in .env")
+	return append(errs,
+		validateNonNegative("alerting.interval_seconds", a.IntervalSeconds),
+		validatePositive("alerting.failure_threshold", a.FailureThreshold),
+		validateNonNegative("alerting.cooldown_minutes", a.CooldownMinutes),
+		validateNonNegative("alerting.aggregator_minutes", a.AggregatorMinutes),
+		validateUrl("alerting.webhook_url", a.WebhookUrl),
+		validateUrl("alerting.slack_webhook_url", a.SlackWebhookUrl),
+		validateUrl("alerting.telegram_api_url", a.TelegramApiUrl),
+	)
+}
+
+// Supply holds the denoms whose supply is saved over time
+type Supply struct {
+	//comma separated
+	TrackedDenoms string `yaml:"tracked_denoms" toml:"tracked_denoms"`
+	//0 disables the snapshots taken by the listener
+	SnapshotMinutes int `yaml:"snapshot_minutes" toml:"snapshot_minutes"`
+	//amounts left out of the circulating supply, separated by comma:
+	//denom=community_pool, denom=module:name[:label] or denom=address:bech32[:label]
+	Exclusions string `yaml:"exclusions" toml:"exclusions"`
+}
+
+// SupplyExclusion is an amount of the denom left out of its circulating supply
+type SupplyExclusion struct {
+	Denom string
+	//one of community_pool, module or address
+	Kind string
+	//the module name or the address, empty for the community pool
+	Account string
+	//shown in the circulating supply breakdown
+	Label string
+}
+
+// Denoms returns the tracked denoms from the comma separated list
+func (s Supply) Denoms() []string {
+	return splitHosts(s.TrackedDenoms)
+}
+
+// ExclusionsByDenom returns the exclusions grouped by denom. The invalid entries are reported by Validate.
+func (s Supply) ExclusionsByDenom() map[string][]SupplyExclusion {
+	result := make(map[string][]SupplyExclusion)
+	exclusions, _ := parseSupplyExclusions(s.Exclusions)
+	for _, e := range exclusions {
+		result[e.Denom] = append(result[e.Denom], e)
 	}
 
-	useGrpcTls, ok := envFile["BLOCKCHAIN_GRPC_USE_TLS"]
-	if ok {
-		cfg.Blockchain.UseGrpcTls = useGrpcTls == "true"
+	return result
+}
+
+func parseSupplyExclusions(value string) (result []SupplyExclusion, err error) {
+	for _, item := range splitHosts(value) {
+		denom, target, found := strings.Cut(item, "=")
+		if !found || denom == "" {
+			return nil, fmt.Errorf("supply.exclusions: %q must be denom=kind[:account[:label]]", item)
+		}
+
+		parts := strings.SplitN(target, ":", 3)
+		e := SupplyExclusion{Denom: denom, Kind: parts[0], Label: parts[0]}
+		switch e.Kind {
+		case SupplyExclusionCommunityPool:
+			if len(parts) > 1 {
+				return nil, fmt.Errorf("supply.exclusions: %q, the community pool has no account", item)
+			}
+		case SupplyExclusionModule, SupplyExclusionAddress:
+			if len(parts) < 2 || parts[1] == "" {
+				return nil, fmt.Errorf("supply.exclusions: %q needs the %s after the kind", item, e.Kind)
+			}
+			e.Account, e.Label = parts[1], parts[1]
+			if len(parts) == 3 && parts[2] != "" {
+				e.Label = parts[2]
+			}
+		default:
+			return nil, fmt.Errorf("supply.exclusions: %q, the kind must be one of [%s, %s, %s]", item, SupplyExclusionCommunityPool, SupplyExclusionModule, SupplyExclusionAddress)
+		}
+
+		result = append(result, e)
 	}
 
-	healthNodes, err := parseEnvVarMap(envFile, "HEALTH_NODES")
-	if err != nil {
-		return nil, err
+	return result, nil
+}
+
+// Retention holds how many days of history and of intervals of each length are kept, 0 keeps them forever.
+// Coarser intervals must be kept at least as long as the finer ones.
+type Retention struct {
+	HistoryDays     int `yaml:"history_days" toml:"history_days"`
+	FiveMinutesDays int `yaml:"intervals_5m_days" toml:"intervals_5m_days"`
+	QuarterHourDays int `yaml:"intervals_15m_days" toml:"intervals_15m_days"`
+	HourDays        int `yaml:"intervals_1h_days" toml:"intervals_1h_days"`
+	FourHoursDays   int `yaml:"intervals_4h_days" toml:"intervals_4h_days"`
+	DayDays         int `yaml:"intervals_1d_days" toml:"intervals_1d_days"`
+	//how often the listener prunes the data, 0 disables it
+	PruneIntervalHours int `yaml:"prune_interval_hours" toml:"prune_interval_hours"`
+}
+
+// IntervalRetention is the retention of the intervals of one length (minutes)
+type IntervalRetention struct {
+	Length int
+	Days   int
+}
+
+// Intervals returns the retention of each interval length, from the finest to the coarsest
+func (r Retention) Intervals() []IntervalRetention {
+	return []IntervalRetention{
+		{Length: 5, Days: r.FiveMinutesDays},
+		{Length: 15, Days: r.QuarterHourDays},
+		{Length: 60, Days: r.HourDays},
+		{Length: 240, Days: r.FourHoursDays},
+		{Length: 1440, Days: r.DayDays},
 	}
+}
+
+type Cors struct {
+	AllowOrigins string `yaml:"allow_origins" toml:"allow_origins"`
+}
+
+// Origins returns the allowed origins from the comma separated list
+func (c Cors) Origins() []string {
+	return splitHosts(c.AllowOrigins)
+}
+
+type Metrics struct {
+	Enabled      bool   `yaml:"enabled" toml:"enabled"`
+	ListenerPort string `yaml:"listener_port" toml:"listener_port"`
+}
+
+type Tracing struct {
+	Exporter     string  `yaml:"exporter" toml:"exporter"`
+	OtlpEndpoint string  `yaml:"otlp_endpoint" toml:"otlp_endpoint"`
+	ServiceName  string  `yaml:"service_name" toml:"service_name"`
+	SampleRatio  float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+type Database struct {
+	Dsn                    string `yaml:"dsn" toml:"dsn"`
+	MaxOpenConns           int    `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns           int    `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetimeSeconds int    `yaml:"conn_max_lifetime_seconds" toml:"conn_max_lifetime_seconds"`
+	ConnMaxIdleTimeSeconds int    `yaml:"conn_max_idle_time_seconds" toml:"conn_max_idle_time_seconds"`
+}
 
-	cfg.Blockchain.HealthNodes = healthNodes
-	cfg.Blockchain.RestHost = rest
-	cfg.Blockchain.RpcHost = rpc
-	cfg.Blockchain.GrpcHost = grpc
+type Articles struct {
+	//the medium feed, used when no feeds are configured
+	FeedUrl string `yaml:"feed_url" toml:"feed_url"`
+	//source name => RSS or Atom feed url
+	Feeds map[string]string `yaml:"feeds" toml:"feeds"`
+	//how often the listener reads the feeds, 0 disables it
+	RefreshMinutes int `yaml:"refresh_minutes" toml:"refresh_minutes"`
+	//the listener indexes the articles published on chain from the cointrunk events
+	OnchainIndex bool `yaml:"onchain_index" toml:"onchain_index"`
+}
 
-	cfg.Coingecko = CoingeckoConfig{
-		Host: cg,
+// ArticleFeed is a feed the articles are read from
+type ArticleFeed struct {
+	Name string
+	Url  string
+}
+
+// FeedList returns the configured feeds sorted by name, or the medium feed when none is configured
+func (a Articles) FeedList() []ArticleFeed {
+	if len(a.Feeds) == 0 {
+		return []ArticleFeed{{Name: DefaultArticlesSource, Url: a.FeedUrl}}
 	}
 
-	cfg.PrefixedEndpoints, err = parseEnvVarMap(envFile, "PREFIXED_REST_HOSTS")
-	if err != nil {
-		return nil, err
+	var result []ArticleFeed
+	for name, feedUrl := range a.Feeds {
+		result = append(result, ArticleFeed{Name: name, Url: feedUrl})
 	}
+	slices.SortFunc(result, func(a, b ArticleFeed) int { return strings.Compare(a.Name, b.Name) })
 
-	return cfg, nil
+	return result
 }
 
-func loadDefaultConfig(env map[string]string, err error) *AppConfig {
+type ChainRegistry struct {
+	AssetListUrl string `yaml:"asset_list_url" toml:"asset_list_url"`
+	//a local assetlist.json, its assets override the assets of the url with the same base denom
+	AssetListFile string `yaml:"asset_list_file" toml:"asset_list_file"`
+	//assetlist.json urls of the chains at the other end of the IBC channels, by channel id (e.g. channel-0)
+	IbcAssetLists map[string]string `yaml:"ibc_asset_lists" toml:"ibc_asset_lists"`
+	//exponent of the denoms missing from the registries
+	DefaultExponent int `yaml:"default_exponent" toml:"default_exponent"`
+}
 
-	port := defaultPort
-	logLevel := defaultLoggingLevel
-	if err != nil {
-		return &AppConfig{
-			Server: Server{
-				Port: port,
-			},
-			Logging: Logging{
-				Level: logLevel,
-			},
-		}
+func validateChainRegistry(r ChainRegistry) (errs []error) {
+	if r.AssetListUrl == "" && r.AssetListFile == "" {
+		errs = append(errs, fmt.Errorf("chain_registry.asset_list_url or chain_registry.asset_list_file is required"))
 	}
 
-	port, ok := env["HTTP_PORT"]
-	if !ok {
-		port = defaultPort
-	}
+	errs = append(errs, validateUrl("chain_registry.asset_list_url", r.AssetListUrl))
+	for channel, listUrl := range r.IbcAssetLists {
+		if !channelRegexp.MatchString(channel) {
+			errs = append(errs, fmt.Errorf("chain_registry.ibc_asset_lists: invalid channel %q, expected channel-{number}", channel))
+		}
 
-	logLevel, ok = env["LOG_LEVEL"]
-	if !ok {
-		logLevel = defaultLoggingLevel
+		errs = append(errs, validateUrl(fmt.Sprintf("chain_registry.ibc_asset_lists.%s", channel), listUrl))
 	}
 
-	prices, ok := env["COINGECKO_PRICE_IDS"]
-	if !ok {
-		prices = ""
+	if r.DefaultExponent < 0 || r.DefaultExponent > maxDefaultExponent {
+		errs = append(errs, fmt.Errorf("chain_registry.default_exponent must be between 0 and %d, got %d", maxDefaultExponent, r.DefaultExponent))
 	}
 
+	return errs
+}
+
+// Cache holds the expiration (in seconds) of every in memory cache
+type Cache struct {
+	SupplySeconds       int `yaml:"supply_seconds" toml:"supply_seconds"`
+	PricesSeconds       int `yaml:"prices_seconds" toml:"prices_seconds"`
+	PricesBackupSeconds int `yaml:"prices_backup_seconds" toml:"prices_backup_seconds"`
+	ArticlesSeconds     int `yaml:"articles_seconds" toml:"articles_seconds"`
+	HealthSeconds       int `yaml:"health_seconds" toml:"health_seconds"`
+	//balances of an address, shared by all the balance checks
+	BalancesSeconds      int `yaml:"balances_seconds" toml:"balances_seconds"`
+	ChainRegistrySeconds int `yaml:"chain_registry_seconds" toml:"chain_registry_seconds"`
+	//DEX responses are also dropped as soon as the sync processes update their market
+	DexSeconds int `yaml:"dex_seconds" toml:"dex_seconds"`
+	//max-age sent in the Cache-Control header of the DEX responses
+	DexMaxAgeSeconds int `yaml:"dex_max_age_seconds" toml:"dex_max_age_seconds"`
+	//how often the synced markets are checked to invalidate their cached DEX responses
+	DexInvalidationSeconds int `yaml:"dex_invalidation_seconds" toml:"dex_invalidation_seconds"`
+}
+
+// Timeouts holds the time (in seconds) allowed for a whole API request and for each dependency call
+type Timeouts struct {
+	RequestSeconds  int `yaml:"request_seconds" toml:"request_seconds"`
+	DatabaseSeconds int `yaml:"database_seconds" toml:"database_seconds"`
+	GrpcSeconds     int `yaml:"grpc_seconds" toml:"grpc_seconds"`
+	RpcSeconds      int `yaml:"rpc_seconds" toml:"rpc_seconds"`
+	HttpSeconds     int `yaml:"http_seconds" toml:"http_seconds"`
+	//exports stream many rows and replace RequestSeconds on their routes
+	ExportSeconds int `yaml:"export_seconds" toml:"export_seconds"`
+}
+
+type AppConfig struct {
+	Server            Server            `yaml:"server" toml:"server"`
+	Logging           Logging           `yaml:"logging" toml:"logging"`
+	RateLimit         RateLimit         `yaml:"rate_limit" toml:"rate_limit"`
+	Cors              Cors              `yaml:"cors" toml:"cors"`
+	Metrics           Metrics           `yaml:"metrics" toml:"metrics"`
+	Tracing           Tracing           `yaml:"tracing" toml:"tracing"`
+	Database          Database          `yaml:"database" toml:"database"`
+	Blockchain        BlockchainConfig  `yaml:"blockchain" toml:"blockchain"`
+	NodePool          NodePool          `yaml:"node_pool" toml:"node_pool"`
+	Health            Health            `yaml:"health" toml:"health"`
+	Prices            PricesConfig      `yaml:"prices" toml:"prices"`
+	Coingecko         CoingeckoConfig   `yaml:"coingecko" toml:"coingecko"`
+	Articles          Articles          `yaml:"articles" toml:"articles"`
+	ChainRegistry     ChainRegistry     `yaml:"chain_registry" toml:"chain_registry"`
+	Cache             Cache             `yaml:"cache" toml:"cache"`
+	Retention         Retention         `yaml:"retention" toml:"retention"`
+	Supply            Supply            `yaml:"supply" toml:"supply"`
+	Alerting          Alerting          `yaml:"alerting" toml:"alerting"`
+	Timeouts          Timeouts          `yaml:"timeouts" toml:"timeouts"`
+	PrefixedEndpoints PrefixedEndpoints `yaml:"prefixed_rest_hosts" toml:"prefixed_rest_hosts"`
+}
+
+// NewAppConfig loads the config without CLI flags: config file (CONFIG_FILE env), .env file and environment variables
+func NewAppConfig() (*AppConfig, error) {
+	return Load(nil)
+}
+
+func loadDefaultConfig() *AppConfig {
 	return &AppConfig{
 		Server: Server{
-			Port: port,
+			Port:                   defaultPort,
+			ShutdownTimeoutSeconds: defaultShutdownTimeout,
 		},
 		Logging: Logging{
-			Level: logLevel,
+			Level:  defaultLoggingLevel,
+			Format: defaultLoggingFormat,
+		},
+		RateLimit: RateLimit{
+			Enabled:        true,
+			Storage:        defaultRateLimitStorage,
+			AnonymousRps:   defaultRateLimitAnonymousRps,
+			AnonymousBurst: defaultRateLimitAnonymousBurst,
+			PartnerRps:     defaultRateLimitPartnerRps,
+			PartnerBurst:   defaultRateLimitPartnerBurst,
+			ExpensiveCost:  defaultRateLimitExpensiveCost,
+		},
+		Cors: Cors{
+			AllowOrigins: defaultCorsAllowOrigins,
+		},
+		Metrics: Metrics{
+			Enabled:      true,
+			ListenerPort: defaultListenerMetricsPort,
+		},
+		Tracing: Tracing{
+			Exporter:    defaultTracingExporter,
+			ServiceName: defaultTracingServiceName,
+			SampleRatio: defaultTracingSampleRatio,
+		},
+		Database: Database{
+			MaxOpenConns:           defaultMysqlMaxOpenConns,
+			MaxIdleConns:           defaultMysqlMaxIdleConns,
+			ConnMaxLifetimeSeconds: defaultMysqlConnMaxLifetime,
+			ConnMaxIdleTimeSeconds: defaultMysqlConnMaxIdleTime,
+		},
+		Articles: Articles{
+			FeedUrl:        defaultArticlesFeedUrl,
+			RefreshMinutes: defaultArticlesRefreshMinutes,
+			OnchainIndex:   true,
+		},
+		ChainRegistry: ChainRegistry{
+			AssetListUrl:    defaultAssetListUrl,
+			DefaultExponent: defaultAssetExponent,
 		},
 		Prices: PricesConfig{
-			Denominations: prices,
+			Sources:         defaultPricesSources,
+			VsCurrencies:    defaultPricesVsCurrencies,
+			StaleSeconds:    defaultPricesStaleSeconds,
+			SnapshotMinutes: defaultPricesSnapshotMinutes,
+		},
+		Supply: Supply{
+			TrackedDenoms:   defaultSupplyTrackedDenoms,
+			SnapshotMinutes: defaultSupplySnapshotMinutes,
+			Exclusions:      defaultSupplyExclusions,
+		},
+		Alerting: Alerting{
+			IntervalSeconds:   defaultAlertingIntervalSeconds,
+			FailureThreshold:  defaultAlertingFailureThreshold,
+			CooldownMinutes:   defaultAlertingCooldownMinutes,
+			AggregatorMinutes: defaultAlertingAggregatorMinutes,
+			TelegramApiUrl:    defaultAlertingTelegramApiUrl,
+		},
+		Retention: Retention{
+			FiveMinutesDays:    defaultRetentionFiveMinutesDays,
+			QuarterHourDays:    defaultRetentionQuarterHourDays,
+			PruneIntervalHours: defaultRetentionPruneHours,
+		},
+		Cache: Cache{
+			SupplySeconds:          defaultSupplyCacheSeconds,
+			PricesSeconds:          defaultPricesCacheSeconds,
+			PricesBackupSeconds:    defaultPricesBackupCacheSeconds,
+			ArticlesSeconds:        defaultArticlesCacheSeconds,
+			HealthSeconds:          defaultHealthCacheSeconds,
+			BalancesSeconds:        defaultBalancesCacheSeconds,
+			ChainRegistrySeconds:   defaultChainRegistryCacheSeconds,
+			DexSeconds:             defaultDexCacheSeconds,
+			DexMaxAgeSeconds:       defaultDexMaxAgeSeconds,
+			DexInvalidationSeconds: defaultDexInvalidationSeconds,
+		},
+		NodePool: NodePool{
+			RefreshSeconds: defaultNodePoolRefreshSeconds,
+			MaxHeightDiff:  defaultNodePoolMaxHeightDiff,
+			Retries:        defaultNodePoolRetries,
+			BackoffMillis:  defaultNodePoolBackoffMillis,
+		},
+		Health: Health{
+			NodesMaxHeightDiff: defaultHealthNodesMaxHeightDiff,
+		},
+		Timeouts: Timeouts{
+			RequestSeconds:  defaultRequestTimeout,
+			DatabaseSeconds: defaultDatabaseTimeout,
+			GrpcSeconds:     defaultGrpcTimeout,
+			RpcSeconds:      defaultRpcTimeout,
+			HttpSeconds:     defaultHttpTimeout,
+			ExportSeconds:   defaultExportTimeout,
 		},
 	}
 }
 
-func parseEnvVarMap(envFile map[string]string, envVar string) (map[string]string, error) {
-	envValue, ok := envFile[envVar]
-	var result map[string]string
-	if ok {
-		nodes := strings.Split(envValue, ",")
-		if len(nodes) > 0 {
-			result = make(map[string]string)
-			for _, node := range nodes {
-				nodeSplit := strings.Split(node, "=")
-				if len(nodeSplit) != 2 || nodeSplit[0] == "" || nodeSplit[1] == "" {
-					return nil, errors.New(fmt.Sprintf("env var %s contains an unknown format: %s", envVar, envValue))
-				}
+// Validate checks the loaded config and returns all the problems found at once
+func (c *AppConfig) Validate() error {
+	var errs []error
+	required := map[string]string{
+		"BLOCKCHAIN_RPC_HOST":  c.Blockchain.RpcHost,
+		"BLOCKCHAIN_REST_HOST": c.Blockchain.RestHost,
+		"BLOCKCHAIN_GRPC_HOST": c.Blockchain.GrpcHost,
+		"COINGECKO_HOST":       c.Coingecko.Host,
+		"MYSQL_DSN":            c.Database.Dsn,
+	}
+	for _, b := range bindings {
+		if value, ok := required[b.env]; ok && value == "" {
+			errs = append(errs, fmt.Errorf("%s is required (env %s or flag --%s)", b.key, b.env, b.flagName()))
+		}
+	}
 
-				result[nodeSplit[0]] = nodeSplit[1]
-			}
+	if _, err := logrus.ParseLevel(c.Logging.Level); err != nil {
+		errs = append(errs, fmt.Errorf("logging.level: %w", err))
+	}
+
+	if c.Logging.Format != "text" && c.Logging.Format != "json" {
+		errs = append(errs, fmt.Errorf("logging.format must be one of [text, json], got %q", c.Logging.Format))
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be one of [none, stdout, otlp], got %q", c.Tracing.Exporter))
+	}
+
+	if c.RateLimit.Storage != RateLimitStorageMemory && c.RateLimit.Storage != RateLimitStorageMysql {
+		errs = append(errs, fmt.Errorf("rate_limit.storage must be one of [%s, %s], got %q", RateLimitStorageMemory, RateLimitStorageMysql, c.RateLimit.Storage))
+	}
+
+	if c.RateLimit.AnonymousRps <= 0 || c.RateLimit.PartnerRps <= 0 {
+		errs = append(errs, fmt.Errorf("rate_limit.anonymous_rps and rate_limit.partner_rps must be positive"))
+	}
+
+	if c.RateLimit.ExpensiveCost > c.RateLimit.AnonymousBurst || c.RateLimit.ExpensiveCost > c.RateLimit.PartnerBurst {
+		errs = append(errs, fmt.Errorf("rate_limit.expensive_cost can not be greater than the bursts, the expensive endpoints would never be allowed"))
+	}
+
+	for name, key := range c.RateLimit.ApiKeys {
+		if len(key) < minApiKeyLength {
+			errs = append(errs, fmt.Errorf("rate_limit.api_keys.%s must have at least %d characters", name, minApiKeyLength))
+		}
+	}
+
+	errs = append(errs, validateRetention(c.Retention)...)
+	errs = append(errs, validatePrices(c.Prices)...)
+	errs = append(errs, validateAlerting(c.Alerting)...)
+
+	if _, groupErrs := parseBalanceGroups(c.Health.BalanceGroups); len(groupErrs) > 0 {
+		errs = append(errs, groupErrs...)
+	}
+
+	if c.Server.AdminApiKey != "" && len(c.Server.AdminApiKey) < minApiKeyLength {
+		errs = append(errs, fmt.Errorf("server.admin_api_key must have at least %d characters", minApiKeyLength))
+	}
+
+	if _, err := parseSupplyExclusions(c.Supply.Exclusions); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(c.Cors.Origins()) == 0 {
+		errs = append(errs, fmt.Errorf("cors.allow_origins can not be empty"))
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio must be between 0 and 1, got %v", c.Tracing.SampleRatio))
+	}
+
+	errs = append(errs,
+		validatePort("server.port", c.Server.Port),
+		validatePort("metrics.listener_port", c.Metrics.ListenerPort),
+		validateUrl("coingecko.host", c.Coingecko.Host),
+		validateUrl("articles.feed_url", c.Articles.FeedUrl),
+	)
+	errs = append(errs, validateChainRegistry(c.ChainRegistry)...)
+
+	for _, host := range c.Blockchain.RestHosts() {
+		errs = append(errs, validateUrl("blockchain.rest_host", host))
+	}
+
+	for _, host := range splitHosts(c.Blockchain.RpcHost) {
+		errs = append(errs, validateUrl("blockchain.rpc_host", host))
+	}
+
+	for _, host := range splitHosts(c.Blockchain.WsHost) {
+		errs = append(errs, validateUrl("blockchain.ws_host", host))
+	}
+
+	for name, node := range c.Blockchain.HealthNodes {
+		errs = append(errs, validateUrl(fmt.Sprintf("blockchain.health_nodes.%s", name), node))
+	}
+
+	for name, feedUrl := range c.Articles.Feeds {
+		errs = append(errs, validateUrl(fmt.Sprintf("articles.feeds.%s", name), feedUrl))
+	}
+
+	for prefix, host := range c.PrefixedEndpoints {
+		errs = append(errs, validateUrl(fmt.Sprintf("prefixed_rest_hosts.%s", prefix), host))
+	}
+
+	errs = append(errs,
+		validatePositive("server.shutdown_timeout_seconds", c.Server.ShutdownTimeoutSeconds),
+		validatePositive("database.max_open_conns", c.Database.MaxOpenConns),
+		validatePositive("cache.supply_seconds", c.Cache.SupplySeconds),
+		validatePositive("cache.prices_seconds", c.Cache.PricesSeconds),
+		validatePositive("cache.prices_backup_seconds", c.Cache.PricesBackupSeconds),
+		validatePositive("cache.articles_seconds", c.Cache.ArticlesSeconds),
+		validatePositive("cache.health_seconds", c.Cache.HealthSeconds),
+		validatePositive("cache.balances_seconds", c.Cache.BalancesSeconds),
+		validatePositive("cache.chain_registry_seconds", c.Cache.ChainRegistrySeconds),
+		validatePositive("cache.dex_seconds", c.Cache.DexSeconds),
+		validateNonNegative("cache.dex_max_age_seconds", c.Cache.DexMaxAgeSeconds),
+		validatePositive("cache.dex_invalidation_seconds", c.Cache.DexInvalidationSeconds),
+		validatePositive("timeouts.request_seconds", c.Timeouts.RequestSeconds),
+		validatePositive("timeouts.database_seconds", c.Timeouts.DatabaseSeconds),
+		validatePositive("timeouts.grpc_seconds", c.Timeouts.GrpcSeconds),
+		validatePositive("timeouts.rpc_seconds", c.Timeouts.RpcSeconds),
+		validatePositive("timeouts.http_seconds", c.Timeouts.HttpSeconds),
+		validatePositive("timeouts.export_seconds", c.Timeouts.ExportSeconds),
+		validatePositive("node_pool.refresh_seconds", c.NodePool.RefreshSeconds),
+		validatePositive("rate_limit.anonymous_burst", c.RateLimit.AnonymousBurst),
+		validatePositive("rate_limit.partner_burst", c.RateLimit.PartnerBurst),
+		validatePositive("rate_limit.expensive_cost", c.RateLimit.ExpensiveCost),
+		validatePositive("node_pool.backoff_millis", c.NodePool.BackoffMillis),
+		validateNonNegative("node_pool.max_height_diff", c.NodePool.MaxHeightDiff),
+		validateNonNegative("node_pool.retries", c.NodePool.Retries),
+		validateNonNegative("health.nodes_max_height_diff", c.Health.NodesMaxHeightDiff),
+		validateNonNegative("health.nodes_max_latency_millis", c.Health.NodesMaxLatencyMillis),
+		validateNonNegative("database.max_idle_conns", c.Database.MaxIdleConns),
+		validateNonNegative("database.conn_max_lifetime_seconds", c.Database.ConnMaxLifetimeSeconds),
+		validateNonNegative("database.conn_max_idle_time_seconds", c.Database.ConnMaxIdleTimeSeconds),
+		validateNonNegative("supply.snapshot_minutes", c.Supply.SnapshotMinutes),
+		validateNonNegative("articles.refresh_minutes", c.Articles.RefreshMinutes),
+	)
+
+	return errors.Join(errs...)
+}
+
+func validateRetention(r Retention) (errs []error) {
+	errs = append(errs,
+		validateRetentionDays("retention.history_days", r.HistoryDays),
+		validateNonNegative("retention.prune_interval_hours", r.PruneIntervalHours),
+	)
+
+	//0 (forever) is the longest retention
+	longest := func(days int) int {
+		if days == 0 {
+			return math.MaxInt
 		}
+
+		return days
+	}
+
+	intervals := r.Intervals()
+	for i, item := range intervals {
+		name := fmt.Sprintf("retention.intervals_%s_days", retentionLengthNames[item.Length])
+		errs = append(errs, validateRetentionDays(name, item.Days))
+		if i > 0 && longest(item.Days) < longest(intervals[i-1].Days) {
+			errs = append(errs, fmt.Errorf("%s can not be shorter than the retention of the finer intervals", name))
+		}
+	}
+
+	return errs
+}
+
+var retentionLengthNames = map[int]string{5: "5m", 15: "15m", 60: "1h", 240: "4h", 1440: "1d"}
+
+func validateRetentionDays(name string, days int) error {
+	if days < 0 || (days > 0 && days < minRetentionDays) {
+		return fmt.Errorf("%s must be 0 (forever) or at least %d, got %d", name, minRetentionDays, days)
+	}
+
+	return nil
+}
+
+// Seconds converts a config value expressed in seconds to a time.Duration
+func Seconds(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+func validatePort(name, port string) error {
+	if port == "" {
+		return nil
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil || p <= 0 || p > 65535 {
+		return fmt.Errorf("%s must be a valid port, got %q", name, port)
+	}
+
+	return nil
+}
+
+func validateUrl(name, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%s must be an absolute url (scheme://host[:port]), got %q", name, value)
+	}
+
+	return nil
+}
+
+func validatePositive(name string, value int) error {
+	if value <= 0 {
+		return fmt.Errorf("%s must be greater than 0, got %d", name, value)
+	}
+
+	return nil
+}
+
+func validateNonNegative(name string, value int) error {
+	if value < 0 {
+		return fmt.Errorf("%s can not be negative, got %d", name, value)
+	}
+
+	return nil
+}
+
+func splitHosts(value string) []string {
+	var hosts []string
+	for _, h := range strings.Split(value, ",") {
+		h = strings.TrimSpace(h)
+		if h != "" && !slices.Contains(hosts, h) {
+			hosts = append(hosts, h)
+		}
+	}
+
+	return hosts
+}
+
+func parseEnvVarMap(envVar, envValue string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, node := range strings.Split(envValue, ",") {
+		nodeSplit := strings.SplitN(node, "=", 2)
+		if len(nodeSplit) != 2 || nodeSplit[0] == "" || nodeSplit[1] == "" {
+			return nil, fmt.Errorf("%s contains an unknown format: %s", envVar, envValue)
+		}
+
+		result[nodeSplit[0]] = nodeSplit[1]
 	}
 
 	return result, nil
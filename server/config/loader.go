@@ -0,0 +1,300 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FlagConfigFile = "config"
+
+	envConfigFile = "CONFIG_FILE"
+	dotEnvFile    = ".env"
+)
+
+// binding maps one config value to its env var and CLI flag
+type binding struct {
+	key   string
+	env   string
+	usage string
+	apply func(cfg *AppConfig, value string) error
+}
+
+// flagName derives the CLI flag from the env var name: HTTP_PORT => http-port
+func (b binding) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(b.env), "_", "-")
+}
+
+var bindings = []binding{
+	stringBinding("server.port", "HTTP_PORT", "port used by the API server", func(c *AppConfig) *string { return &c.Server.Port }),
+	intBinding("server.shutdown_timeout_seconds", "SHUTDOWN_TIMEOUT_SECONDS", "time allowed for in-flight work to finish on shutdown", func(c *AppConfig) *int { return &c.Server.ShutdownTimeoutSeconds }),
+	stringBinding("server.admin_api_key", "ADMIN_API_KEY", "bearer token of the /api/v2/admin endpoints, they are disabled when empty", func(c *AppConfig) *string { return &c.Server.AdminApiKey }),
+	stringBinding("logging.level", "LOG_LEVEL", "panic, fatal, error, warning, info, debug or trace", func(c *AppConfig) *string { return &c.Logging.Level }),
+	stringBinding("logging.format", "LOG_FORMAT", "text or json", func(c *AppConfig) *string { return &c.Logging.Format }),
+	boolBinding("rate_limit.enabled", "RATE_LIMIT_ENABLED", "limit the API requests per IP and API key", func(c *AppConfig) *bool { return &c.RateLimit.Enabled }),
+	stringBinding("rate_limit.storage", "RATE_LIMIT_STORAGE", "memory or mysql (shared by all the replicas)", func(c *AppConfig) *string { return &c.RateLimit.Storage }),
+	floatBinding("rate_limit.anonymous_rps", "RATE_LIMIT_ANONYMOUS_RPS", "requests per second allowed for each IP without API key", func(c *AppConfig) *float64 { return &c.RateLimit.AnonymousRps }),
+	intBinding("rate_limit.anonymous_burst", "RATE_LIMIT_ANONYMOUS_BURST", "burst of requests allowed for each IP without API key", func(c *AppConfig) *int { return &c.RateLimit.AnonymousBurst }),
+	floatBinding("rate_limit.partner_rps", "RATE_LIMIT_PARTNER_RPS", "requests per second allowed for each API key", func(c *AppConfig) *float64 { return &c.RateLimit.PartnerRps }),
+	intBinding("rate_limit.partner_burst", "RATE_LIMIT_PARTNER_BURST", "burst of requests allowed for each API key", func(c *AppConfig) *int { return &c.RateLimit.PartnerBurst }),
+	mapBinding("rate_limit.api_keys", "RATE_LIMIT_API_KEYS", "partner=key pairs separated by comma", func(c *AppConfig) *map[string]string { return &c.RateLimit.ApiKeys }),
+	intBinding("rate_limit.expensive_cost", "RATE_LIMIT_EXPENSIVE_COST", "requests counted for each call to an expensive endpoint", func(c *AppConfig) *int { return &c.RateLimit.ExpensiveCost }),
+	boolBinding("rate_limit.trust_proxy", "RATE_LIMIT_TRUST_PROXY", "read the client IP from X-Forwarded-For", func(c *AppConfig) *bool { return &c.RateLimit.TrustProxy }),
+	stringBinding("cors.allow_origins", "CORS_ALLOW_ORIGINS", "origins allowed to call the API, separated by comma", func(c *AppConfig) *string { return &c.Cors.AllowOrigins }),
+	boolBinding("metrics.enabled", "METRICS_ENABLED", "expose prometheus metrics on /metrics", func(c *AppConfig) *bool { return &c.Metrics.Enabled }),
+	stringBinding("metrics.listener_port", "LISTENER_METRICS_PORT", "port used by the sync listener to expose metrics", func(c *AppConfig) *string { return &c.Metrics.ListenerPort }),
+	stringBinding("tracing.exporter", "TRACING_EXPORTER", "none, stdout or otlp", func(c *AppConfig) *string { return &c.Tracing.Exporter }),
+	stringBinding("tracing.otlp_endpoint", "TRACING_OTLP_ENDPOINT", "OTLP gRPC collector url", func(c *AppConfig) *string { return &c.Tracing.OtlpEndpoint }),
+	stringBinding("tracing.service_name", "TRACING_SERVICE_NAME", "service name reported in traces", func(c *AppConfig) *string { return &c.Tracing.ServiceName }),
+	floatBinding("tracing.sample_ratio", "TRACING_SAMPLE_RATIO", "fraction of traces sampled (0-1)", func(c *AppConfig) *float64 { return &c.Tracing.SampleRatio }),
+	stringBinding("database.dsn", "MYSQL_DSN", "MySQL data source name", func(c *AppConfig) *string { return &c.Database.Dsn }),
+	intBinding("database.max_open_conns", "MYSQL_MAX_OPEN_CONNS", "max open MySQL connections", func(c *AppConfig) *int { return &c.Database.MaxOpenConns }),
+	intBinding("database.max_idle_conns", "MYSQL_MAX_IDLE_CONNS", "max idle MySQL connections", func(c *AppConfig) *int { return &c.Database.MaxIdleConns }),
+	intBinding("database.conn_max_lifetime_seconds", "MYSQL_CONN_MAX_LIFETIME_SECONDS", "max lifetime of a MySQL connection", func(c *AppConfig) *int { return &c.Database.ConnMaxLifetimeSeconds }),
+	intBinding("database.conn_max_idle_time_seconds", "MYSQL_CONN_MAX_IDLE_TIME_SECONDS", "max idle time of a MySQL connection", func(c *AppConfig) *int { return &c.Database.ConnMaxIdleTimeSeconds }),
+	stringBinding("blockchain.rpc_host", "BLOCKCHAIN_RPC_HOST", "blockchain RPC urls separated by comma", func(c *AppConfig) *string { return &c.Blockchain.RpcHost }),
+	stringBinding("blockchain.rest_host", "BLOCKCHAIN_REST_HOST", "blockchain REST urls separated by comma", func(c *AppConfig) *string { return &c.Blockchain.RestHost }),
+	stringBinding("blockchain.grpc_host", "BLOCKCHAIN_GRPC_HOST", "blockchain gRPC host:port list separated by comma", func(c *AppConfig) *string { return &c.Blockchain.GrpcHost }),
+	boolBinding("blockchain.grpc_use_tls", "BLOCKCHAIN_GRPC_USE_TLS", "use TLS for the gRPC connection", func(c *AppConfig) *bool { return &c.Blockchain.UseGrpcTls }),
+	stringBinding("blockchain.ws_host", "BLOCKCHAIN_WS_HOST", "blockchain websocket urls used by the sync listener, separated by comma", func(c *AppConfig) *string { return &c.Blockchain.WsHost }),
+	mapBinding("blockchain.health_nodes", "HEALTH_NODES", "name=url pairs separated by comma", func(c *AppConfig) *map[string]string { return &c.Blockchain.HealthNodes }),
+	intBinding("node_pool.refresh_seconds", "NODE_POOL_REFRESH_SECONDS", "interval between two probes of the blockchain nodes", func(c *AppConfig) *int { return &c.NodePool.RefreshSeconds }),
+	intBinding("node_pool.max_height_diff", "NODE_POOL_MAX_HEIGHT_DIFF", "blocks a node can be behind the others before it is skipped", func(c *AppConfig) *int { return &c.NodePool.MaxHeightDiff }),
+	intBinding("node_pool.retries", "NODE_POOL_RETRIES", "times a failed blockchain call is retried on another node", func(c *AppConfig) *int { return &c.NodePool.Retries }),
+	intBinding("node_pool.backoff_millis", "NODE_POOL_BACKOFF_MILLIS", "wait before the first retry, doubled on every retry", func(c *AppConfig) *int { return &c.NodePool.BackoffMillis }),
+	intBinding("health.nodes_max_height_diff", "HEALTH_NODES_MAX_HEIGHT_DIFF", "blocks a health node can be behind the highest one before it is unhealthy", func(c *AppConfig) *int { return &c.Health.NodesMaxHeightDiff }),
+	intBinding("health.nodes_max_latency_millis", "HEALTH_NODES_MAX_LATENCY_MILLIS", "a health node answering slower is unhealthy, 0 disables the check", func(c *AppConfig) *int { return &c.Health.NodesMaxLatencyMillis }),
+	mapBinding("health.balance_groups", "HEALTH_BALANCE_GROUPS", "group:address:denom=min_amount pairs separated by comma", func(c *AppConfig) *map[string]string { return &c.Health.BalanceGroups }),
+	stringBinding("coingecko.host", "COINGECKO_HOST", "coingecko API url", func(c *AppConfig) *string { return &c.Coingecko.Host }),
+	stringBinding("prices.denominations", "COINGECKO_PRICE_IDS", "coingecko ids to fetch prices for", func(c *AppConfig) *string { return &c.Prices.Denominations }),
+	stringBinding("prices.sources", "PRICES_SOURCES", "price sources asked in order: coingecko, osmosis, dex, static", func(c *AppConfig) *string { return &c.Prices.Sources }),
+	stringBinding("prices.vs_currencies", "PRICES_VS_CURRENCIES", "currencies the prices are given in, separated by comma", func(c *AppConfig) *string { return &c.Prices.VsCurrencies }),
+	stringBinding("prices.osmosis_host", "PRICES_OSMOSIS_HOST", "osmosis REST url used by the osmosis price source", func(c *AppConfig) *string { return &c.Prices.OsmosisHost }),
+	mapBinding("prices.osmosis_pools", "PRICES_OSMOSIS_POOLS", "id:currency=pool_id:base_denom:quote_denom pairs separated by comma", func(c *AppConfig) *map[string]string { return &c.Prices.OsmosisPools }),
+	mapBinding("prices.dex_markets", "PRICES_DEX_MARKETS", "id:currency=market_id pairs separated by comma", func(c *AppConfig) *map[string]string { return &c.Prices.DexMarkets }),
+	mapBinding("prices.static", "PRICES_STATIC", "id:currency=price pairs separated by comma", func(c *AppConfig) *map[string]string { return &c.Prices.Static }),
+	intBinding("prices.stale_seconds", "PRICES_STALE_SECONDS", "prices updated longer ago are flagged as stale", func(c *AppConfig) *int { return &c.Prices.StaleSeconds }),
+	intBinding("prices.snapshot_minutes", "PRICES_SNAPSHOT_MINUTES", "how often `sync listener` saves the prices, 0 disables it", func(c *AppConfig) *int { return &c.Prices.SnapshotMinutes }),
+	stringBinding("articles.feed_url", "ARTICLES_FEED_URL", "medium RSS feed used for articles when ARTICLES_FEEDS is empty", func(c *AppConfig) *string { return &c.Articles.FeedUrl }),
+	mapBinding("articles.feeds", "ARTICLES_FEEDS", "source=feed_url pairs separated by comma, RSS or Atom", func(c *AppConfig) *map[string]string { return &c.Articles.Feeds }),
+	intBinding("articles.refresh_minutes", "ARTICLES_REFRESH_MINUTES", "how often `sync listener` reads the articles feeds, 0 disables it", func(c *AppConfig) *int { return &c.Articles.RefreshMinutes }),
+	boolBinding("articles.onchain_index", "ARTICLES_ONCHAIN_INDEX", "index the articles published on chain from the `sync listener` events", func(c *AppConfig) *bool { return &c.Articles.OnchainIndex }),
+	intBinding("alerting.interval_seconds", "ALERTING_INTERVAL_SECONDS", "how often `sync listener` runs the health checks, 0 disables the alerting", func(c *AppConfig) *int { return &c.Alerting.IntervalSeconds }),
+	intBinding("alerting.failure_threshold", "ALERTING_FAILURE_THRESHOLD", "consecutive failures before a check is unhealthy", func(c *AppConfig) *int { return &c.Alerting.FailureThreshold }),
+	intBinding("alerting.cooldown_minutes", "ALERTING_COOLDOWN_MINUTES", "minimum time between two notifications of the same unhealthy check", func(c *AppConfig) *int { return &c.Alerting.CooldownMinutes }),
+	mapBinding("alerting.markets", "ALERTING_MARKETS", "market_id=minutes pairs separated by comma", func(c *AppConfig) *map[string]string { return &c.Alerting.Markets }),
+	intBinding("alerting.aggregator_minutes", "ALERTING_AGGREGATOR_MINUTES", "minutes without synced trades before the aggregator is unhealthy, 0 disables the check", func(c *AppConfig) *int { return &c.Alerting.AggregatorMinutes }),
+	mapBinding("alerting.balances", "ALERTING_BALANCES", "address:denom=min_amount pairs separated by comma", func(c *AppConfig) *map[string]string { return &c.Alerting.Balances }),
+	stringBinding("alerting.webhook_url", "ALERTING_WEBHOOK_URL", "url receiving the alerts as JSON", func(c *AppConfig) *string { return &c.Alerting.WebhookUrl }),
+	stringBinding("alerting.slack_webhook_url", "ALERTING_SLACK_WEBHOOK_URL", "slack compatible incoming webhook url", func(c *AppConfig) *string { return &c.Alerting.SlackWebhookUrl }),
+	stringBinding("alerting.telegram_api_url", "ALERTING_TELEGRAM_API_URL", "telegram bot API url", func(c *AppConfig) *string { return &c.Alerting.TelegramApiUrl }),
+	stringBinding("alerting.telegram_bot_token", "ALERTING_TELEGRAM_BOT_TOKEN", "telegram bot token", func(c *AppConfig) *string { return &c.Alerting.TelegramBotToken }),
+	stringBinding("alerting.telegram_chat_id", "ALERTING_TELEGRAM_CHAT_ID", "telegram chat receiving the alerts", func(c *AppConfig) *string { return &c.Alerting.TelegramChatId }),
+	stringBinding("chain_registry.asset_list_url", "CHAIN_REGISTRY_ASSET_LIST_URL", "chain registry assetlist.json url", func(c *AppConfig) *string { return &c.ChainRegistry.AssetListUrl }),
+	stringBinding("chain_registry.asset_list_file", "CHAIN_REGISTRY_ASSET_LIST_FILE", "local assetlist.json overriding the assets of the url", func(c *AppConfig) *string { return &c.ChainRegistry.AssetListFile }),
+	mapBinding("chain_registry.ibc_asset_lists", "CHAIN_REGISTRY_IBC_ASSET_LISTS", "channel=assetlist.json url pairs of the IBC counterparty chains separated by comma", func(c *AppConfig) *map[string]string { return &c.ChainRegistry.IbcAssetLists }),
+	intBinding("chain_registry.default_exponent", "CHAIN_REGISTRY_DEFAULT_EXPONENT", "exponent of the denoms missing from the registries", func(c *AppConfig) *int { return &c.ChainRegistry.DefaultExponent }),
+	intBinding("cache.supply_seconds", "CACHE_SUPPLY_SECONDS", "supply cache ttl", func(c *AppConfig) *int { return &c.Cache.SupplySeconds }),
+	intBinding("cache.prices_seconds", "CACHE_PRICES_SECONDS", "prices cache ttl", func(c *AppConfig) *int { return &c.Cache.PricesSeconds }),
+	intBinding("cache.prices_backup_seconds", "CACHE_PRICES_BACKUP_SECONDS", "prices backup cache ttl", func(c *AppConfig) *int { return &c.Cache.PricesBackupSeconds }),
+	intBinding("cache.articles_seconds", "CACHE_ARTICLES_SECONDS", "articles cache ttl", func(c *AppConfig) *int { return &c.Cache.ArticlesSeconds }),
+	intBinding("cache.health_seconds", "CACHE_HEALTH_SECONDS", "health cache ttl", func(c *AppConfig) *int { return &c.Cache.HealthSeconds }),
+	intBinding("cache.balances_seconds", "CACHE_BALANCES_SECONDS", "ttl of the balances of an address used by the balance checks", func(c *AppConfig) *int { return &c.Cache.BalancesSeconds }),
+	intBinding("cache.chain_registry_seconds", "CACHE_CHAIN_REGISTRY_SECONDS", "age of the chain registry snapshot before it is refreshed in background", func(c *AppConfig) *int { return &c.Cache.ChainRegistrySeconds }),
+	intBinding("retention.history_days", "RETENTION_HISTORY_DAYS", "days of trade history kept, 0 keeps it forever", func(c *AppConfig) *int { return &c.Retention.HistoryDays }),
+	intBinding("retention.intervals_5m_days", "RETENTION_INTERVALS_5M_DAYS", "days of 5 minutes intervals kept, 0 keeps them forever", func(c *AppConfig) *int { return &c.Retention.FiveMinutesDays }),
+	intBinding("retention.intervals_15m_days", "RETENTION_INTERVALS_15M_DAYS", "days of 15 minutes intervals kept, 0 keeps them forever", func(c *AppConfig) *int { return &c.Retention.QuarterHourDays }),
+	intBinding("retention.intervals_1h_days", "RETENTION_INTERVALS_1H_DAYS", "days of 1 hour intervals kept, 0 keeps them forever", func(c *AppConfig) *int { return &c.Retention.HourDays }),
+	intBinding("retention.intervals_4h_days", "RETENTION_INTERVALS_4H_DAYS", "days of 4 hours intervals kept, 0 keeps them forever", func(c *AppConfig) *int { return &c.Retention.FourHoursDays }),
+	intBinding("retention.intervals_1d_days", "RETENTION_INTERVALS_1D_DAYS", "days of 1 day intervals kept, 0 keeps them forever", func(c *AppConfig) *int { return &c.Retention.DayDays }),
+	stringBinding("supply.tracked_denoms", "SUPPLY_TRACKED_DENOMS", "denoms whose supply is saved over time, separated by comma", func(c *AppConfig) *string { return &c.Supply.TrackedDenoms }),
+	intBinding("supply.snapshot_minutes", "SUPPLY_SNAPSHOT_MINUTES", "how often the listener saves the supply of the tracked denoms, 0 disables it", func(c *AppConfig) *int { return &c.Supply.SnapshotMinutes }),
+	stringBinding("supply.exclusions", "SUPPLY_EXCLUSIONS", "amounts left out of the circulating supply, separated by comma: denom=community_pool, denom=module:name[:label] or denom=address:addr[:label]", func(c *AppConfig) *string { return &c.Supply.Exclusions }),
+	intBinding("retention.prune_interval_hours", "RETENTION_PRUNE_INTERVAL_HOURS", "how often the listener prunes old data, 0 disables it", func(c *AppConfig) *int { return &c.Retention.PruneIntervalHours }),
+	intBinding("cache.dex_seconds", "CACHE_DEX_SECONDS", "dex responses cache ttl", func(c *AppConfig) *int { return &c.Cache.DexSeconds }),
+	intBinding("cache.dex_max_age_seconds", "CACHE_DEX_MAX_AGE_SECONDS", "max-age of the dex responses Cache-Control header", func(c *AppConfig) *int { return &c.Cache.DexMaxAgeSeconds }),
+	intBinding("cache.dex_invalidation_seconds", "CACHE_DEX_INVALIDATION_SECONDS", "interval of the dex responses invalidation checks", func(c *AppConfig) *int { return &c.Cache.DexInvalidationSeconds }),
+	intBinding("timeouts.request_seconds", "TIMEOUT_REQUEST_SECONDS", "max duration of an API request", func(c *AppConfig) *int { return &c.Timeouts.RequestSeconds }),
+	intBinding("timeouts.database_seconds", "TIMEOUT_DATABASE_SECONDS", "max duration of a database query", func(c *AppConfig) *int { return &c.Timeouts.DatabaseSeconds }),
+	intBinding("timeouts.grpc_seconds", "TIMEOUT_GRPC_SECONDS", "max duration of a blockchain gRPC call", func(c *AppConfig) *int { return &c.Timeouts.GrpcSeconds }),
+	intBinding("timeouts.rpc_seconds", "TIMEOUT_RPC_SECONDS", "max duration of a blockchain RPC call", func(c *AppConfig) *int { return &c.Timeouts.RpcSeconds }),
+	intBinding("timeouts.http_seconds", "TIMEOUT_HTTP_SECONDS", "max duration of an outgoing HTTP call (REST, coingecko, registry, feeds)", func(c *AppConfig) *int { return &c.Timeouts.HttpSeconds }),
+	intBinding("timeouts.export_seconds", "TIMEOUT_EXPORT_SECONDS", "max duration of a DEX export request", func(c *AppConfig) *int { return &c.Timeouts.ExportSeconds }),
+	mapBinding("prefixed_rest_hosts", "PREFIXED_REST_HOSTS", "prefix=url pairs separated by comma", func(c *AppConfig) *map[string]string {
+		return (*map[string]string)(&c.PrefixedEndpoints)
+	}),
+}
+
+// RegisterFlags adds one flag for every config value plus the --config flag
+func RegisterFlags(flags *pflag.FlagSet) {
+	flags.String(FlagConfigFile, "", fmt.Sprintf("path to a YAML or TOML config file (env %s)", envConfigFile))
+	for _, b := range bindings {
+		flags.String(b.flagName(), "", fmt.Sprintf("%s (env %s)", b.usage, b.env))
+	}
+}
+
+// Load builds the app config from (lowest to highest precedence):
+// defaults, the config file, the .env file, environment variables and the CLI flags.
+// flags can be nil. The result is validated before being returned.
+func Load(flags *pflag.FlagSet) (*AppConfig, error) {
+	cfg := loadDefaultConfig()
+
+	env, err := readEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	file := env[envConfigFile]
+	if flags != nil {
+		if f := flags.Lookup(FlagConfigFile); f != nil && f.Changed {
+			file = f.Value.String()
+		}
+	}
+
+	if file != "" {
+		if err = loadFile(cfg, file); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, b := range bindings {
+		if value := env[b.env]; value != "" {
+			if err = b.apply(cfg, value); err != nil {
+				errs = append(errs, fmt.Errorf("env %s: %w", b.env, err))
+			}
+		}
+
+		if flags == nil {
+			continue
+		}
+
+		if f := flags.Lookup(b.flagName()); f != nil && f.Changed {
+			if err = b.apply(cfg, f.Value.String()); err != nil {
+				errs = append(errs, fmt.Errorf("flag --%s: %w", b.flagName(), err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// readEnv merges the optional .env file with the process environment, the latter taking precedence
+func readEnv() (map[string]string, error) {
+	env, err := godotenv.Read(dotEnvFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("could not read %s: %w", dotEnvFile, err)
+		}
+
+		env = make(map[string]string)
+	}
+
+	names := []string{envConfigFile}
+	for _, b := range bindings {
+		names = append(names, b.env)
+	}
+
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = value
+		}
+	}
+
+	return env, nil
+}
+
+func loadFile(cfg *AppConfig, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, cfg)
+	case ".toml":
+		err = toml.Unmarshal(content, cfg)
+	default:
+		return fmt.Errorf("unsupported config file extension: %s (use .yaml, .yml or .toml)", path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func stringBinding(key, env, usage string, field func(c *AppConfig) *string) binding {
+	return binding{key: key, env: env, usage: usage, apply: func(cfg *AppConfig, value string) error {
+		*field(cfg) = value
+
+		return nil
+	}}
+}
+
+func intBinding(key, env, usage string, field func(c *AppConfig) *int) binding {
+	return binding{key: key, env: env, usage: usage, apply: func(cfg *AppConfig, value string) error {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", value)
+		}
+		*field(cfg) = parsed
+
+		return nil
+	}}
+}
+
+func floatBinding(key, env, usage string, field func(c *AppConfig) *float64) binding {
+	return binding{key: key, env: env, usage: usage, apply: func(cfg *AppConfig, value string) error {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", value)
+		}
+		*field(cfg) = parsed
+
+		return nil
+	}}
+}
+
+func boolBinding(key, env, usage string, field func(c *AppConfig) *bool) binding {
+	return binding{key: key, env: env, usage: usage, apply: func(cfg *AppConfig, value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", value)
+		}
+		*field(cfg) = parsed
+
+		return nil
+	}}
+}
+
+func mapBinding(key, env, usage string, field func(c *AppConfig) *map[string]string) binding {
+	return binding{key: key, env: env, usage: usage, apply: func(cfg *AppConfig, value string) error {
+		parsed, err := parseEnvVarMap(env, value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = parsed
+
+		return nil
+	}}
+}
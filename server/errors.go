@@ -0,0 +1,60 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/controller"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
+	"github.com/labstack/echo/v4"
+)
+
+// newHTTPErrorHandler answers the errors raised outside the handlers (unknown route, request timeout, panic)
+// with the /api/v2 envelope on v2 routes and with the default echo response on the others
+func newHTTPErrorHandler(e *echo.Echo) echo.HTTPErrorHandler {
+	return func(err error, ctx echo.Context) {
+		if !strings.HasPrefix(ctx.Request().URL.Path, apiV2Prefix+"/") {
+			e.DefaultHTTPErrorHandler(err, ctx)
+			return
+		}
+
+		if ctx.Response().Committed {
+			return
+		}
+
+		status := http.StatusInternalServerError
+		message := http.StatusText(status)
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			status = he.Code
+			message = fmt.Sprint(he.Message)
+		}
+
+		if err = controller.RespondErrorEnvelope(ctx, status, string(errorCodeForStatus(status)), message); err != nil {
+			e.Logger.Error(err)
+		}
+	}
+}
+
+func errorCodeForStatus(status int) internal.ErrorCode {
+	switch status {
+	case http.StatusUnauthorized:
+		return internal.ErrCodeUnauthorized
+	case http.StatusNotFound:
+		return internal.ErrCodeNotFound
+	case http.StatusTooManyRequests:
+		return internal.ErrCodeRateLimited
+	case http.StatusServiceUnavailable:
+		return internal.ErrCodeUnavailable
+	case http.StatusUnprocessableEntity:
+		return internal.ErrCodeValidation
+	}
+
+	if status < http.StatusInternalServerError {
+		return internal.ErrCodeInvalidRequest
+	}
+
+	return internal.ErrCodeInternal
+}
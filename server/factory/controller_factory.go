@@ -6,11 +6,17 @@ import (
 	"github.com/bze-alphateam/bze-aggregator-api/app/controller"
 	"github.com/bze-alphateam/bze-aggregator-api/app/repository"
 	appService "github.com/bze-alphateam/bze-aggregator-api/app/service"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/alerting"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/client"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/data_provider"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/dex"
 	"github.com/bze-alphateam/bze-aggregator-api/app/service/health"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/httpcache"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/ratelimit"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/retention"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
 	"github.com/bze-alphateam/bze-aggregator-api/connector"
+	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze-aggregator-api/server/config"
 	"github.com/sirupsen/logrus"
 )
@@ -18,6 +24,11 @@ import (
 type ControllerFactory struct {
 	logger logrus.FieldLogger
 	config *config.AppConfig
+
+	//shared by the dex controller and its invalidator
+	dexCache *httpcache.Cache
+	//shared by the balance checks, so they share the cached balances
+	balancesHealth *health.BalancesHealth
 }
 
 func NewControllerFactory(logger logrus.FieldLogger, cfg *config.AppConfig) (*ControllerFactory, error) {
@@ -32,79 +43,207 @@ func NewControllerFactory(logger logrus.FieldLogger, cfg *config.AppConfig) (*Co
 }
 
 func (c *ControllerFactory) GetSupplyController() (*controller.SupplyController, error) {
-	cache := appService.NewInMemoryCache()
+	cache := appService.NewMeteredCache("supply", appService.NewInMemoryCache())
 	if cache == nil {
 		return nil, fmt.Errorf("could not instantiate in memory cache")
 	}
 
-	dp, err := client.NewBlockchainQueryClient(c.config.Blockchain.RestHost)
+	dp, err := c.getBlockchainQueryClient()
 	if err != nil {
 		return nil, fmt.Errorf("could not instantiate blockchain query client: %w", err)
 	}
 
-	regClient, err := client.NewChainRegistry()
+	chainReg, err := connector.GetChainRegistry(c.config, c.logger)
 	if err != nil {
 		return nil, err
 	}
 
-	chainReg, err := data_provider.NewChainRegistry(c.logger, cache, regClient)
+	service, err := appService.NewSupplyService(c.logger, cache, dp, chainReg, config.Seconds(c.config.Cache.SupplySeconds), c.config.Supply.ExclusionsByDenom())
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate supply service: %w", err)
+	}
+
+	db, err := getDatabase(c.config)
 	if err != nil {
 		return nil, err
 	}
 
-	service, err := appService.NewSupplyService(c.logger, cache, dp, chainReg)
+	snapshots, err := repository.NewSupplySnapshotRepository(db)
 	if err != nil {
-		return nil, fmt.Errorf("could not instantiate supply service: %w", err)
+		return nil, err
+	}
+
+	history, err := appService.NewSupplyHistoryService(c.logger, snapshots, service, chainReg)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.NewSupplyController(c.logger, service, history)
+}
+
+func (c *ControllerFactory) GetAssetsController() (*controller.AssetsController, error) {
+	chainReg, err := connector.GetChainRegistry(c.config, c.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := getDatabase(c.config)
+	if err != nil {
+		return nil, err
+	}
+
+	markets, err := repository.NewMarketRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := appService.NewAssetsService(c.logger, chainReg, markets)
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate assets service: %w", err)
 	}
 
-	return controller.NewSupplyController(c.logger, service)
+	return controller.NewAssetsController(c.logger, service)
 }
 
 func (c *ControllerFactory) GetArticlesController() (*controller.ArticlesController, error) {
-	cache := appService.NewInMemoryCache()
+	cache := appService.NewMeteredCache("articles", appService.NewInMemoryCache())
 	if cache == nil {
 		return nil, fmt.Errorf("could not instantiate in memory cache")
 	}
 
-	service, err := appService.NewMediumService(c.logger, cache)
+	db, err := getDatabase(c.config)
 	if err != nil {
-		return nil, fmt.Errorf("could not instantiate supply service: %w", err)
+		return nil, err
+	}
+
+	storage, err := repository.NewArticleRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := appService.NewArticlesService(c.logger, cache, storage, c.config.Articles.FeedList(), config.Seconds(c.config.Cache.ArticlesSeconds), config.Seconds(c.config.Timeouts.HttpSeconds))
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate articles service: %w", err)
+	}
+
+	cointrunk, err := repository.NewCointrunkRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	onchain, err := appService.NewOnchainArticlesService(c.logger, cache, cointrunk, config.Seconds(c.config.Cache.ArticlesSeconds))
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate onchain articles service: %w", err)
 	}
 
-	return controller.NewArticlesController(c.logger, service)
+	return controller.NewArticlesController(c.logger, service, onchain)
 }
 
 func (c *ControllerFactory) GetPricesController() (*controller.PricesController, error) {
-	cache := appService.NewInMemoryCache()
+	cache := appService.NewMeteredCache("prices", appService.NewInMemoryCache())
 	if cache == nil {
 		return nil, fmt.Errorf("could not instantiate in memory cache")
 	}
 
-	cgClient, err := client.NewCoingeckoClient(c.config.Coingecko.Host, c.config.Prices.Denominations)
+	db, err := getDatabase(c.config)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := c.getPriceSources(db)
 	if err != nil {
-		return nil, fmt.Errorf("could not instantiate coingecko client: %w", err)
+		return nil, fmt.Errorf("could not instantiate price sources: %w", err)
 	}
 
-	service, err := appService.NewPricesService(cache, cgClient, c.logger)
+	service, err := appService.NewPricesService(cache, sources, c.logger, c.config.Prices, config.Seconds(c.config.Cache.PricesSeconds), config.Seconds(c.config.Cache.PricesBackupSeconds))
 	if err != nil {
 		return nil, fmt.Errorf("could not instantiate prices service: %w", err)
 	}
 
-	return controller.NewPricesController(c.logger, service)
+	snapshots, err := repository.NewPriceSnapshotRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := appService.NewPricesHistoryService(c.logger, snapshots, service)
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate prices history service: %w", err)
+	}
+
+	return controller.NewPricesController(c.logger, service, history)
+}
+
+// getPriceSources returns the configured price sources, in the order they are asked
+func (c *ControllerFactory) getPriceSources(db internal.Database) ([]appService.PriceSource, error) {
+	timeout := config.Seconds(c.config.Timeouts.HttpSeconds)
+	prices := c.config.Prices
+	var sources []appService.PriceSource
+	for _, name := range prices.SourceNames() {
+		switch name {
+		case config.PriceSourceCoingecko:
+			source, err := client.NewCoingeckoClient(c.config.Coingecko.Host, timeout)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		case config.PriceSourceOsmosis:
+			source, err := client.NewOsmosisClient(prices.OsmosisHost, prices.Entries(prices.OsmosisPools), timeout)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		case config.PriceSourceDex:
+			tickers, err := repository.NewMarketTickerRepository(db)
+			if err != nil {
+				return nil, err
+			}
+			source, err := data_provider.NewDexPrice(tickers, prices.Entries(prices.DexMarkets))
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		case config.PriceSourceStatic:
+			sources = append(sources, data_provider.NewStaticPrice(prices.Entries(prices.Static)))
+		}
+	}
+
+	return sources, nil
+}
+
+// GetRateLimiter returns the limiter of the API requests, keeping its buckets in the configured storage
+func (c *ControllerFactory) GetRateLimiter(expensiveRoutes []string) (*ratelimit.Limiter, error) {
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if c.config.RateLimit.Storage == config.RateLimitStorageMysql {
+		db, err := getDatabase(c.config)
+		if err != nil {
+			return nil, err
+		}
+
+		store, err = ratelimit.NewDatabaseStore(db, ratelimit.MaxRefill(c.config.RateLimit))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ratelimit.NewLimiter(store, c.config.RateLimit, expensiveRoutes, c.logger)
+}
+
+func (c *ControllerFactory) GetDocsController() (*controller.DocsController, error) {
+	return controller.NewDocsController()
 }
 
 func (c *ControllerFactory) GetHealthController() (*controller.HealthCheckController, error) {
-	cache := appService.NewInMemoryCache()
+	cache := appService.NewMeteredCache("health", appService.NewInMemoryCache())
 	if cache == nil {
 		return nil, fmt.Errorf("could not instantiate in memory cache")
 	}
 
-	dp, err := client.NewBlockchainQueryClient(c.config.Blockchain.RestHost)
+	dp, err := c.getBlockchainQueryClient()
 	if err != nil {
 		return nil, fmt.Errorf("could not instantiate blockchain query client: %w", err)
 	}
 
-	db, err := connector.NewDatabaseConnection()
+	db, err := getDatabase(c.config)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +258,7 @@ func (c *ControllerFactory) GetHealthController() (*controller.HealthCheckContro
 	if len(c.config.Blockchain.HealthNodes) > 0 {
 		healthClients = make(map[string]appService.NodeInfoClient, len(c.config.Blockchain.HealthNodes))
 		for hostName, hostAddr := range c.config.Blockchain.HealthNodes {
-			rpc, err := client.GetRpcClient(hostAddr)
+			rpc, err := connector.GetRpcClient(hostAddr, c.config.Timeouts.RpcSeconds)
 			if err != nil {
 				return nil, err
 			}
@@ -133,21 +272,95 @@ func (c *ControllerFactory) GetHealthController() (*controller.HealthCheckContro
 		}
 	}
 
-	service, err := appService.NewHealthService(c.logger, cache, dp, repo, healthClients)
+	service, err := appService.NewHealthService(c.logger, cache, dp, repo, healthClients, config.Seconds(c.config.Cache.HealthSeconds), int64(c.config.Health.NodesMaxHeightDiff), c.config.Health.NodesMaxLatency())
 	if err != nil {
 		return nil, fmt.Errorf("could not instantiate prices service: %w", err)
 	}
 
-	balanceHealthService, err := health.NewBalancesHealth(c.config.PrefixedEndpoints)
+	balanceHealthService, err := c.getBalancesHealth()
 	if err != nil {
 		return nil, fmt.Errorf("could not instantiate balance health service: %w", err)
 	}
 
-	return controller.NewHealthCheckController(c.logger, service, balanceHealthService)
+	events, err := repository.NewHealthEventRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := alerting.NewStatus(events)
+	if err != nil {
+		return nil, err
+	}
+
+	readiness, err := c.getReadiness(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.NewHealthCheckController(c.logger, service, balanceHealthService, status, readiness)
+}
+
+func (c *ControllerFactory) GetBalanceGroupsController() (*controller.BalanceGroupsController, error) {
+	balances, err := c.getBalancesHealth()
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate balance health service: %w", err)
+	}
+
+	db, err := getDatabase(c.config)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewBalanceWatchRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := health.NewBalanceGroups(repo, balances, c.config.Health.Groups())
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.NewBalanceGroupsController(c.logger, service)
+}
+
+func (c *ControllerFactory) getBalancesHealth() (*health.BalancesHealth, error) {
+	if c.balancesHealth != nil {
+		return c.balancesHealth, nil
+	}
+
+	cache := appService.NewMeteredCache("balances", appService.NewInMemoryCache())
+	chainReg, err := connector.GetChainRegistry(c.config, c.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	c.balancesHealth, err = health.NewBalancesHealth(c.logger, cache, chainReg, c.config.PrefixedEndpoints, config.Seconds(c.config.Timeouts.HttpSeconds), config.Seconds(c.config.Cache.BalancesSeconds))
+
+	return c.balancesHealth, err
+}
+
+// getReadiness probes the database and the pools of REST and gRPC nodes used to serve the requests
+func (c *ControllerFactory) getReadiness(db internal.Database) (*health.Readiness, error) {
+	restPool, err := connector.GetRestPool(c.config, c.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	grpc, err := connector.GetGrpcClient(c.config, c.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return health.NewReadiness(c.logger, map[string]health.Probe{
+		"database":   health.NewDatabaseProbe(db),
+		"rest_nodes": health.NewPoolProbe(restPool),
+		"grpc_nodes": health.NewPoolProbe(grpc.Nodes()),
+	})
 }
 
 func (c *ControllerFactory) GetDexController() (*controller.Dex, error) {
-	db, err := connector.NewDatabaseConnection()
+	db, err := getDatabase(c.config)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +385,12 @@ func (c *ControllerFactory) GetDexController() (*controller.Dex, error) {
 		return nil, err
 	}
 
-	tickers, err := dex.NewTickersService(c.logger, mRepo, iRepo, oRepo)
+	tRepo, err := repository.NewMarketTickerRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers, err := dex.NewTickersService(c.logger, tRepo)
 	if err != nil {
 		return nil, err
 	}
@@ -187,10 +405,87 @@ func (c *ControllerFactory) GetDexController() (*controller.Dex, error) {
 		return nil, err
 	}
 
-	intervals, err := dex.NewIntervals(iRepo, c.logger, mRepo)
+	intervals, err := dex.NewIntervals(iRepo, c.logger, mRepo, retention.NewPolicy(c.config.Retention))
+	if err != nil {
+		return nil, err
+	}
+
+	markets, err := dex.NewMarketsService(mRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.NewDexController(c.logger, tickers, orders, history, intervals, markets, c.getDexCache(), config.Seconds(c.config.Cache.DexMaxAgeSeconds))
+}
+
+func (c *ControllerFactory) GetDexExportController() (*controller.DexExport, error) {
+	db, err := getDatabase(c.config)
+	if err != nil {
+		return nil, err
+	}
+
+	mRepo, err := repository.NewMarketRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	iRepo, err := repository.NewMarketIntervalRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	hRepo, err := repository.NewMarketHistoryRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := dex.NewExportService(c.logger, hRepo, iRepo, mRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.NewDexExportController(c.logger, service, config.Seconds(c.config.Timeouts.ExportSeconds))
+}
+
+// GetDexCacheInvalidator returns the invalidator of the DEX responses cached by the dex controller
+func (c *ControllerFactory) GetDexCacheInvalidator() (*httpcache.Invalidator, error) {
+	db, err := getDatabase(c.config)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewMarketSyncVersionRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return httpcache.NewInvalidator(c.getDexCache(), repo, config.Seconds(c.config.Cache.DexInvalidationSeconds), c.logger)
+}
+
+func (c *ControllerFactory) getDexCache() *httpcache.Cache {
+	if c.dexCache == nil {
+		c.dexCache = httpcache.NewCache(config.Seconds(c.config.Cache.DexSeconds))
+	}
+
+	return c.dexCache
+}
+
+// getBlockchainQueryClient returns a REST client using the shared pool of REST nodes
+func (c *ControllerFactory) getBlockchainQueryClient() (*client.BlockchainQueryClient, error) {
+	nodes, err := connector.GetRestPool(c.config, c.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NewBlockchainQueryClient(nodes, config.Seconds(c.config.Timeouts.HttpSeconds))
+}
+
+// getDatabase returns the shared database pool wrapped with the query timeout and tracing instrumentation
+func getDatabase(cfg *config.AppConfig) (internal.Database, error) {
+	db, err := connector.GetDatabase(cfg.Database)
 	if err != nil {
 		return nil, err
 	}
 
-	return controller.NewDexController(c.logger, tickers, orders, history, intervals)
+	return tracing.NewDatabase(connector.NewTimeoutDatabase(db, config.Seconds(cfg.Timeouts.DatabaseSeconds))), nil
 }
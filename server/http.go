@@ -1,8 +1,16 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/logging"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/ratelimit"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/tracing"
+	"github.com/bze-alphateam/bze-aggregator-api/connector"
 	"github.com/bze-alphateam/bze-aggregator-api/internal"
 	"github.com/bze-alphateam/bze-aggregator-api/server/config"
 	"github.com/bze-alphateam/bze-aggregator-api/server/factory"
@@ -11,68 +19,100 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func Start() {
+// Start runs the API server using the already loaded and validated config
+func Start(appCfg *config.AppConfig) {
 	e := echo.New()
+	e.HTTPErrorHandler = newHTTPErrorHandler(e)
+	//the client IP is used by the rate limiter, X-Forwarded-For can be spoofed unless set by a trusted proxy
+	e.IPExtractor = echo.ExtractIPDirect()
+	if appCfg.RateLimit.TrustProxy {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	}
 
-	appCfg, err := config.NewAppConfig()
+	logger, err := internal.NewLogger(appCfg)
 	if err != nil {
-		logrus.Fatalf("could not load app config: %v", err)
+		logrus.Fatalf("could not create logger: %v", err)
 	}
 
-	logger, err := internal.NewLogger(appCfg)
+	shutdownTracing, err := tracing.Setup(appCfg)
+	if err != nil {
+		logger.Fatalf("could not setup tracing: %s", err)
+	}
 
 	// Middleware
 	e.Use(middleware.Recover())
 	//generates a unique id for each request
 	e.Use(middleware.RequestID())
-	e.Use(middleware.CORS())
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins:  appCfg.Cors.Origins(),
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, ratelimit.HeaderApiKey, "If-None-Match"},
+		ExposeHeaders: []string{echo.HeaderXRequestID, "ETag", echo.HeaderRetryAfter, ratelimit.HeaderLimit, ratelimit.HeaderRemaining, ratelimit.HeaderReset},
+	}))
+	if appCfg.Metrics.Enabled {
+		e.Use(metrics.EchoMiddleware())
+	}
+	e.Use(tracing.EchoMiddleware())
+	e.Use(logging.EchoMiddleware(logger))
+	//the request context is canceled on timeout or when the client goes away, aborting the calls made for it
+	e.Use(middleware.ContextTimeoutWithConfig(middleware.ContextTimeoutConfig{
+		Skipper: isExportRoute,
+		Timeout: config.Seconds(appCfg.Timeouts.RequestSeconds),
+	}))
 
 	ctrlFactory, err := factory.NewControllerFactory(logger, appCfg)
 	if err != nil {
 		logger.Fatalf("could not start server: %s", err)
 	}
 
-	supplyCtrl, err := ctrlFactory.GetSupplyController()
-	if err != nil {
-		logger.Fatalf("could not start server: %s", err)
+	if appCfg.RateLimit.Enabled {
+		limiter, err := ctrlFactory.GetRateLimiter(expensiveRoutes)
+		if err != nil {
+			logger.Fatalf("could not start server: %s", err)
+		}
+		e.Use(limiter.EchoMiddleware(func(ctx echo.Context) bool {
+			return ctx.Path() == "/metrics" || ctx.Path() == readinessPath
+		}))
 	}
 
-	articlesCtrl, err := ctrlFactory.GetArticlesController()
+	ctrls, err := newControllers(ctrlFactory)
 	if err != nil {
 		logger.Fatalf("could not start server: %s", err)
 	}
 
-	pricesCtrl, err := ctrlFactory.GetPricesController()
-	if err != nil {
-		logger.Fatalf("could not start server: %s", err)
-	}
+	registerRoutes(e, ctrls, appCfg.Metrics.Enabled, appCfg.Server.AdminApiKey)
 
-	healthCtrl, err := ctrlFactory.GetHealthController()
+	invalidator, err := ctrlFactory.GetDexCacheInvalidator()
 	if err != nil {
 		logger.Fatalf("could not start server: %s", err)
 	}
 
-	dexCtrl, err := ctrlFactory.GetDexController()
-	if err != nil {
-		logger.Fatalf("could not start server: %s", err)
-	}
+	ctx, stop := internal.NewShutdownContext()
+	defer stop()
 
-	// Routes
-	e.GET("/api/supply/total", supplyCtrl.TotalSupplyHandler)
-	e.GET("/api/supply/circulating", supplyCtrl.CirculatingSupplyHandler)
-	e.GET("/api/articles/medium", articlesCtrl.MediumArticlesHandler)
-	e.GET("/api/prices", pricesCtrl.PricesHandler)
-	e.GET("/api/health/market", healthCtrl.DexMarketCheckHandler)
-	e.GET("/api/health/aggregator", healthCtrl.DexAggregatorCheckHandler)
-	e.GET("/api/health/nodes", healthCtrl.NodesCheckHandler)
-	e.POST("/api/health/balances", healthCtrl.CheckBalancesHandler)
-
-	//dex related endpoints
-	e.GET("/api/dex/tickers", dexCtrl.TickersHandler)
-	e.GET("/api/dex/orders", dexCtrl.OrdersHandler)
-	e.GET("/api/dex/history", dexCtrl.HistoryHandler)
-	e.GET("/api/dex/intervals", dexCtrl.IntervalsHandler)
+	go invalidator.Run(ctx)
 
 	// Start server
-	e.Logger.Fatal(e.Start(fmt.Sprintf(":%s", appCfg.Server.Port)))
+	go func() {
+		err := e.Start(fmt.Sprintf(":%s", appCfg.Server.Port))
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatalf("could not start server: %s", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("shutting down server")
+
+	// stop accepting new requests and wait for the in-flight ones before closing the connections they use
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Seconds(appCfg.Server.ShutdownTimeoutSeconds))
+	defer cancel()
+	if err = e.Shutdown(shutdownCtx); err != nil {
+		logger.WithError(err).Error("could not gracefully shutdown the server")
+	}
+
+	connector.Close(logger)
+	if err = shutdownTracing(shutdownCtx); err != nil {
+		logger.WithError(err).Error("could not flush traces")
+	}
+
+	logger.Info("server stopped")
 }
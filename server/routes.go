@@ -0,0 +1,158 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/controller"
+	"github.com/bze-alphateam/bze-aggregator-api/app/openapi"
+	"github.com/bze-alphateam/bze-aggregator-api/app/service/metrics"
+	"github.com/bze-alphateam/bze-aggregator-api/server/factory"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	apiV2Prefix = "/api/v2"
+	//the export routes stream for up to timeouts.export_seconds instead of timeouts.request_seconds
+	exportPrefix = "/api/dex/export/"
+	//probed by the orchestrator, it is never rate limited
+	readinessPath = "/api/health"
+)
+
+// expensiveRoutes take more tokens from the rate limit bucket: they fan out calls to the blockchain nodes
+// or read many rows
+var expensiveRoutes = []string{
+	"/api/health/balances",
+	apiV2Prefix + "/health/balances",
+	"/api/health/balances/:group",
+	apiV2Prefix + "/health/balances/:group",
+	"/api/supply",
+	apiV2Prefix + "/supply",
+	"/api/dex/history",
+	apiV2Prefix + "/dex/history",
+	exportPrefix + "trades",
+	exportPrefix + "candles",
+}
+
+type controllers struct {
+	supply   *controller.SupplyController
+	assets   *controller.AssetsController
+	articles *controller.ArticlesController
+	prices   *controller.PricesController
+	health   *controller.HealthCheckController
+	balances *controller.BalanceGroupsController
+	dex      *controller.Dex
+	export   *controller.DexExport
+	docs     *controller.DocsController
+}
+
+func newControllers(f *factory.ControllerFactory) (c controllers, err error) {
+	if c.supply, err = f.GetSupplyController(); err != nil {
+		return c, fmt.Errorf("supply controller: %w", err)
+	}
+	if c.assets, err = f.GetAssetsController(); err != nil {
+		return c, fmt.Errorf("assets controller: %w", err)
+	}
+	if c.articles, err = f.GetArticlesController(); err != nil {
+		return c, fmt.Errorf("articles controller: %w", err)
+	}
+	if c.prices, err = f.GetPricesController(); err != nil {
+		return c, fmt.Errorf("prices controller: %w", err)
+	}
+	if c.health, err = f.GetHealthController(); err != nil {
+		return c, fmt.Errorf("health controller: %w", err)
+	}
+	if c.balances, err = f.GetBalanceGroupsController(); err != nil {
+		return c, fmt.Errorf("balance groups controller: %w", err)
+	}
+	if c.dex, err = f.GetDexController(); err != nil {
+		return c, fmt.Errorf("dex controller: %w", err)
+	}
+	if c.export, err = f.GetDexExportController(); err != nil {
+		return c, fmt.Errorf("dex export controller: %w", err)
+	}
+	if c.docs, err = f.GetDocsController(); err != nil {
+		return c, fmt.Errorf("docs controller: %w", err)
+	}
+
+	return c, nil
+}
+
+// registerRoutes registers all the API routes. Every route must be described in the OpenAPI spec (see app/openapi).
+func registerRoutes(e *echo.Echo, c controllers, metricsEnabled bool, adminApiKey string) {
+	if metricsEnabled {
+		e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+	}
+	e.GET(openapi.SpecPath, c.docs.SpecHandler)
+	e.GET(openapi.DocsPath, c.docs.UIHandler)
+	e.GET(openapi.DocsAssetsPath, c.docs.AssetsHandler)
+
+	e.GET("/api/supply", c.supply.ListHandler)
+	e.GET("/api/supply/total", c.supply.TotalSupplyHandler)
+	e.GET("/api/supply/circulating", c.supply.CirculatingSupplyHandler)
+	e.GET("/api/supply/history", c.supply.HistoryHandler)
+	e.GET("/api/assets", c.assets.AssetsHandler)
+	e.GET("/api/articles", c.articles.ArticlesHandler)
+	e.GET("/api/articles/onchain", c.articles.OnchainArticlesHandler)
+	e.GET("/api/articles/medium", c.articles.MediumArticlesHandler)
+	e.GET("/api/prices", c.prices.PricesHandler)
+	e.GET("/api/prices/history", c.prices.HistoryHandler)
+	e.GET(readinessPath, c.health.ReadinessHandler)
+	e.GET("/api/health/market", c.health.DexMarketCheckHandler)
+	e.GET("/api/health/aggregator", c.health.DexAggregatorCheckHandler)
+	e.GET("/api/health/nodes", c.health.NodesCheckHandler)
+	e.POST("/api/health/balances", c.health.CheckBalancesHandler)
+	e.GET("/api/health/balances/:group", c.balances.CheckHandler)
+	e.GET("/api/health/status", c.health.StatusHandler)
+
+	//dex related endpoints
+	e.GET("/api/dex/tickers", c.dex.TickersHandler)
+	e.GET("/api/dex/orders", c.dex.OrdersHandler)
+	e.GET("/api/dex/history", c.dex.HistoryHandler)
+	e.GET("/api/dex/intervals", c.dex.IntervalsHandler)
+	e.GET(exportPrefix+"trades", c.export.TradesHandler)
+	e.GET(exportPrefix+"candles", c.export.CandlesHandler)
+
+	//v2 endpoints wrap every response in the same envelope and report errors with matching status codes
+	v2 := e.Group(apiV2Prefix)
+	v2.GET("/supply", c.supply.ListV2Handler)
+	v2.GET("/supply/total", c.supply.TotalSupplyV2Handler)
+	v2.GET("/supply/circulating", c.supply.CirculatingSupplyV2Handler)
+	v2.GET("/supply/history", c.supply.HistoryV2Handler)
+	v2.GET("/assets", c.assets.AssetsV2Handler)
+	v2.GET("/articles", c.articles.ArticlesV2Handler)
+	v2.GET("/articles/onchain", c.articles.OnchainArticlesV2Handler)
+	v2.GET("/prices", c.prices.PricesV2Handler)
+	v2.GET("/prices/history", c.prices.HistoryV2Handler)
+	v2.GET("/health/market", c.health.DexMarketCheckV2Handler)
+	v2.GET("/health/aggregator", c.health.DexAggregatorCheckV2Handler)
+	v2.GET("/health/nodes", c.health.NodesCheckV2Handler)
+	v2.POST("/health/balances", c.health.CheckBalancesV2Handler)
+	v2.GET("/health/balances/:group", c.balances.CheckV2Handler)
+	v2.GET("/health/status", c.health.StatusV2Handler)
+	v2.GET("/dex/tickers", c.dex.TickersV2Handler)
+	v2.GET("/dex/orders", c.dex.OrdersV2Handler)
+	v2.GET("/dex/history", c.dex.HistoryV2Handler)
+	v2.GET("/dex/intervals", c.dex.IntervalsV2Handler)
+
+	//admin endpoints, authenticated with the ADMIN_API_KEY bearer token
+	//the middleware is set on each route, a group middleware would also catch the unknown /admin routes
+	auth := adminAuth(adminApiKey)
+	admin := v2.Group("/admin")
+	admin.GET("/balance-groups", c.balances.ListV2Handler, auth)
+	admin.GET("/balance-groups/:group", c.balances.GetV2Handler, auth)
+	admin.PUT("/balance-groups/:group", c.balances.SaveV2Handler, auth)
+	admin.DELETE("/balance-groups/:group", c.balances.DeleteV2Handler, auth)
+}
+
+func isExportRoute(ctx echo.Context) bool {
+	return strings.HasPrefix(ctx.Path(), exportPrefix)
+}
+
+// Routes returns the routes registered by the API server. The controllers are not built, so it needs no config.
+func Routes() []*echo.Route {
+	e := echo.New()
+	registerRoutes(e, controllers{}, true, "")
+
+	return e.Routes()
+}
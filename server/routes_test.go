@@ -0,0 +1,15 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/bze-alphateam/bze-aggregator-api/app/openapi"
+)
+
+// TestRoutesMatchSpec fails when a route registered by the API server is missing from the OpenAPI spec or the spec
+// documents a route that does not exist
+func TestRoutesMatchSpec(t *testing.T) {
+	if err := openapi.CheckRoutes(Routes()); err != nil {
+		t.Fatal(err)
+	}
+}